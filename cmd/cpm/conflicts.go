@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+// runConflicts implements "cpm conflicts [--json]" and returns the exit code.
+// It reports skill, agent, command, and MCP server names provided by more than
+// one plugin enabled for the current directory.
+func runConflicts(opts options, args []string) int {
+	jsonOutput := false
+	for _, arg := range args {
+		switch arg {
+		case "--help", "-h":
+			printConflictsUsage(os.Stdout)
+			return exitValid
+		case "--json":
+			jsonOutput = true
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown option: %s\n\n", arg)
+			printConflictsUsage(os.Stderr)
+			return exitUsage
+		}
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get working directory: %v\n", err)
+		return exitUsage
+	}
+
+	for _, settingsErr := range claude.CheckSettings(workingDir) {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring %s settings: %v\n", settingsErr.Scope, settingsErr)
+	}
+
+	list, err := newClient(workingDir, opts.offline).ListPlugins(false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	components := claude.EnabledPluginComponents(list.Installed, claude.GetAllEnabledPlugins(workingDir))
+	conflicts := claude.FindConflicts(components)
+	if conflicts == nil {
+		conflicts = []claude.Conflict{}
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(conflicts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+	} else {
+		printConflicts(os.Stdout, conflicts, len(components))
+	}
+
+	if len(conflicts) > 0 {
+		return exitInvalid
+	}
+	return exitValid
+}
+
+// printConflicts writes a human-readable conflict report.
+func printConflicts(w io.Writer, conflicts []claude.Conflict, pluginCount int) {
+	if len(conflicts) == 0 {
+		_, _ = fmt.Fprintf(w, "✓ No conflicts between %d enabled plugin(s)\n", pluginCount)
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "✗ %d conflict(s) between %d enabled plugin(s)\n\n", len(conflicts), pluginCount)
+	for _, c := range conflicts {
+		_, _ = fmt.Fprintf(w, "  %-10s %-20s %s\n", c.Kind, c.Name, strings.Join(c.PluginIDs, ", "))
+	}
+}
+
+func printConflictsUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Usage: cpm conflicts [options]")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Report skill, agent, command, and MCP server names provided by more than one")
+	_, _ = fmt.Fprintln(w, "plugin enabled for the current directory (user, project, and local settings).")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Options:")
+	_, _ = fmt.Fprintln(w, "  --json                Output conflicts as JSON")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Exit status is 0 when there are no conflicts, 1 when conflicts were found,")
+	_, _ = fmt.Fprintln(w, "and 2 for usage errors or when the claude CLI fails.")
+}
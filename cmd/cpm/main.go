@@ -5,41 +5,56 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/open-cli-collective/cpm/internal/claude"
+	"github.com/open-cli-collective/cpm/internal/config"
 	"github.com/open-cli-collective/cpm/internal/tui"
 	"github.com/open-cli-collective/cpm/internal/version"
 )
 
-func main() {
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+// subcommands maps non-interactive commands to their implementations.
+// Each gets the global options given before it and returns the process exit code.
+var subcommands = map[string]func(opts options, args []string) int{
+	"validate":    runValidate,
+	"conflicts":   runConflicts,
+	"marketplace": runMarketplace,
 }
 
-func run() error {
-	theme, done := parseFlags()
+func main() {
+	opts, rest, done := parseFlags(os.Args[1:])
 	if done {
-		return nil
+		return
 	}
+	claude.SetConfigDir(opts.configDir)
 
-	// Check for claude CLI
-	if _, err := exec.LookPath("claude"); err != nil {
-		return fmt.Errorf("claude CLI not found in PATH. Please install Claude Code first")
+	if len(rest) > 0 {
+		os.Exit(subcommands[rest[0]](opts, rest[1:]))
 	}
 
+	if err := run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
 	// Get current working directory for filtering project-scoped plugins
 	workingDir, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get working directory: %w", err)
 	}
 
-	// Create client and model
-	client := claude.NewClient()
-	model := tui.NewModelWithTheme(client, workingDir, theme)
+	model := tui.NewModelWithTheme(newClient(workingDir, opts.offline), workingDir, opts.theme)
+	model.SetStaleAfter(opts.staleAfter)
+
+	// Collapsed groups are remembered between sessions when there's a config directory
+	if path, err := config.Path(); err == nil {
+		model.UseConfig(path)
+	}
 
 	// Run the TUI
 	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
@@ -50,38 +65,102 @@ func run() error {
 	return nil
 }
 
-// parseFlags parses command-line flags and returns the theme setting.
-// Returns done=true if the program should exit (e.g., after --help or --version).
-func parseFlags() (theme tui.Theme, done bool) {
-	theme = tui.ThemeAuto
+// newClient returns a client for the claude CLI or, when offline is set or
+// claude isn't in PATH, one that edits the settings files directly.
+func newClient(workingDir string, offline bool) claude.Client {
+	if _, err := exec.LookPath("claude"); err != nil || offline {
+		return claude.NewOfflineClient(workingDir)
+	}
+	return claude.NewClient()
+}
+
+// options holds the settings parsed from command-line flags.
+type options struct {
+	configDir  string // Claude config directory from --config-dir; "" if not given
+	theme      tui.Theme
+	staleAfter time.Duration
+	offline    bool
+}
 
-	for i := 1; i < len(os.Args); i++ {
-		arg := os.Args[i]
-		switch {
-		case arg == "--version" || arg == "-v":
+// parseFlags parses the global command-line flags in args, stopping at the
+// first subcommand, which is returned with its arguments in rest.
+// Returns done=true if the program should exit (e.g., after --help or --version).
+func parseFlags(args []string) (opts options, rest []string, done bool) {
+	opts.theme = tui.ThemeAuto
+	opts.staleAfter = tui.DefaultStaleAfter
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if _, ok := subcommands[arg]; ok {
+			return opts, args[i:], false
+		}
+		switch arg {
+		case "--version", "-v":
 			fmt.Println(version.String())
-			return theme, true
-		case arg == "--help" || arg == "-h":
+			return opts, nil, true
+		case "--help", "-h":
 			printUsage()
-			return theme, true
-		case arg == "--theme" || arg == "-t":
-			if i+1 >= len(os.Args) {
-				exitWithError("--theme requires an argument (auto, light, dark)")
-			}
-			i++
-			theme = parseThemeOrExit(os.Args[i])
-		case strings.HasPrefix(arg, "--theme="):
-			theme = parseThemeOrExit(strings.TrimPrefix(arg, "--theme="))
-		case strings.HasPrefix(arg, "-t="):
-			theme = parseThemeOrExit(strings.TrimPrefix(arg, "-t="))
+			return opts, nil, true
+		case "--offline":
+			opts.offline = true
 		default:
-			fmt.Fprintf(os.Stderr, "Unknown option: %s\n\n", arg)
-			printUsage()
-			os.Exit(1)
+			i += parseValueFlag(&opts, args[i:])
 		}
 	}
 
-	return theme, false
+	return opts, nil, false
+}
+
+// valueFlag is a global flag taking a value, given as "--flag value" or "--flag=value".
+type valueFlag struct {
+	set     func(opts *options, value string)
+	missing string // Error when the value is missing
+	names   []string
+}
+
+// valueFlags are the global flags that take a value.
+var valueFlags = []valueFlag{
+	{
+		set:     func(opts *options, value string) { opts.configDir = value },
+		missing: "--config-dir requires a directory",
+		names:   []string{"--config-dir"},
+	},
+	{
+		set:     func(opts *options, value string) { opts.staleAfter = parseStaleAfterOrExit(value) },
+		missing: "--stale-after requires a duration (e.g. 7d, 36h, 0 to disable)",
+		names:   []string{"--stale-after"},
+	},
+	{
+		set:     func(opts *options, value string) { opts.theme = parseThemeOrExit(value) },
+		missing: "--theme requires an argument (auto, light, dark)",
+		names:   []string{"--theme", "-t"},
+	},
+}
+
+// parseValueFlag sets the value flag at the start of args in opts and returns
+// how many following args its value used. Unknown options exit with usage.
+func parseValueFlag(opts *options, args []string) int {
+	arg := args[0]
+	for _, flag := range valueFlags {
+		for _, name := range flag.names {
+			switch {
+			case arg == name:
+				if len(args) < 2 {
+					exitWithError(flag.missing)
+				}
+				flag.set(opts, args[1])
+				return 1
+			case strings.HasPrefix(arg, name+"="):
+				flag.set(opts, strings.TrimPrefix(arg, name+"="))
+				return 0
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown option: %s\n\n", arg)
+	printUsage()
+	os.Exit(1)
+	return 0
 }
 
 // parseThemeOrExit parses a theme string, exiting on error.
@@ -107,6 +186,31 @@ func parseTheme(s string) (tui.Theme, bool) {
 	}
 }
 
+// parseStaleAfterOrExit parses a --stale-after value, exiting on error.
+func parseStaleAfterOrExit(s string) time.Duration {
+	d, ok := parseStaleAfter(s)
+	if !ok {
+		exitWithError(fmt.Sprintf("invalid duration '%s'. Use e.g. 7d, 36h, or 0 to disable", s))
+	}
+	return d
+}
+
+// parseStaleAfter parses a non-negative duration, accepting a "d" suffix for days.
+func parseStaleAfter(s string) (time.Duration, bool) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * 24 * time.Hour, true
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+	return d, true
+}
+
 // exitWithError prints an error message and exits.
 func exitWithError(msg string) {
 	fmt.Fprintf(os.Stderr, "Error: %s\n", msg)
@@ -119,9 +223,22 @@ func printUsage() {
 	fmt.Println("A TUI for managing Claude Code plugins with clear scope visibility.")
 	fmt.Println()
 	fmt.Println("Usage: cpm [options]")
+	fmt.Println("       cpm [--config-dir <dir>] [--offline] <command> [args]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  validate [dir]       Check a plugin directory for errors (see cpm validate --help)")
+	fmt.Println("  conflicts            Report name collisions between enabled plugins")
+	fmt.Println("  marketplace auto-update <name> on|off")
+	fmt.Println("                       Turn automatic updates on or off for a marketplace")
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  -h, --help           Show this help message")
 	fmt.Println("  -v, --version        Show version information")
 	fmt.Println("  -t, --theme <theme>  Set color theme: auto, light, dark (default: auto)")
+	fmt.Println("      --offline        Edit settings files directly instead of running the claude CLI")
+	fmt.Println("                       (automatic when claude isn't in PATH)")
+	fmt.Println("      --stale-after <duration>")
+	fmt.Println("                       Highlight marketplaces not updated for this long (default: 7d; 0 disables)")
+	fmt.Println("      --config-dir <dir>")
+	fmt.Println("                       Claude config directory (default: $CLAUDE_CONFIG_DIR, then ~/.claude)")
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+	"github.com/open-cli-collective/cpm/internal/tui"
+)
+
+func TestParseFlagsBeforeSubcommand(t *testing.T) {
+	tests := []struct {
+		wantDir     string
+		args        []string
+		wantRest    []string
+		wantOffline bool
+	}{
+		{"/work/claude", []string{"--config-dir", "/work/claude", "--offline", "conflicts", "--json"}, []string{"conflicts", "--json"}, true},
+		{"x", []string{"--config-dir=x", "marketplace", "auto-update", "mp", "on"}, []string{"marketplace", "auto-update", "mp", "on"}, false},
+		{"", []string{"validate", "--offline"}, []string{"validate", "--offline"}, false}, // Subcommand flags are its own
+		{"", []string{"--theme", "dark"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		opts, rest, done := parseFlags(tt.args)
+		if done || opts.configDir != tt.wantDir || opts.offline != tt.wantOffline || !slices.Equal(rest, tt.wantRest) {
+			t.Errorf("parseFlags(%q) = %+v, %q, %v", tt.args, opts, rest, done)
+		}
+	}
+}
+
+func TestParseValueFlags(t *testing.T) {
+	opts, rest, done := parseFlags([]string{"-t", "dark", "--stale-after=36h", "--theme=light", "--config-dir", "/c"})
+	if done || rest != nil || opts.theme != tui.ThemeLight || opts.staleAfter != 36*time.Hour || opts.configDir != "/c" {
+		t.Errorf("parseFlags() = %+v, %q, %v", opts, rest, done)
+	}
+}
+
+func TestSubcommandUsesConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	pluginsDir := filepath.Join(dir, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	knownPath := filepath.Join(pluginsDir, "known_marketplaces.json")
+	if err := os.WriteFile(knownPath, []byte(`{"mp": {"source": {"source": "github", "repo": "owner/mp"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(claude.ConfigDirEnv, "")
+
+	opts, rest, _ := parseFlags([]string{"--config-dir", dir, "marketplace", "auto-update", "mp", "on"})
+	claude.SetConfigDir(opts.configDir)
+	t.Cleanup(func() { claude.SetConfigDir("") })
+
+	if code := subcommands[rest[0]](opts, rest[1:]); code != exitValid {
+		t.Fatalf("exit code = %d, want %d", code, exitValid)
+	}
+	data, err := os.ReadFile(knownPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"autoUpdate": true`) {
+		t.Errorf("known_marketplaces.json in --config-dir not updated:\n%s", data)
+	}
+}
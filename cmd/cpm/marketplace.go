@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+// runMarketplace implements "cpm marketplace <command>" and returns the exit code.
+func runMarketplace(_ options, args []string) int {
+	if len(args) == 0 {
+		printMarketplaceUsage(os.Stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "--help", "-h":
+		printMarketplaceUsage(os.Stdout)
+		return exitValid
+	case "auto-update":
+		return runAutoUpdate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown marketplace command: %s\n\n", args[0])
+		printMarketplaceUsage(os.Stderr)
+		return exitUsage
+	}
+}
+
+// runAutoUpdate implements "cpm marketplace auto-update <name> on|off".
+func runAutoUpdate(args []string) int {
+	if len(args) != 2 {
+		printMarketplaceUsage(os.Stderr)
+		return exitUsage
+	}
+	name := args[0]
+
+	var on bool
+	switch args[1] {
+	case "on":
+		on = true
+	case "off":
+		on = false
+	default:
+		fmt.Fprintf(os.Stderr, "Error: expected on or off, got %q\n", args[1])
+		return exitUsage
+	}
+
+	if err := claude.SetMarketplaceAutoUpdate(name, on); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitInvalid
+	}
+	fmt.Printf("Auto-update %s for %s\n", args[1], name)
+	return exitValid
+}
+
+func printMarketplaceUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Usage: cpm marketplace auto-update <name> on|off")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Turn automatic updates on or off for a known marketplace. Only the")
+	_, _ = fmt.Fprintln(w, "marketplace's autoUpdate field in known_marketplaces.json changes.")
+}
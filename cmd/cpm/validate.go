@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+// Exit codes for subcommands, suitable for CI.
+const (
+	exitValid   = 0 // No problems found (warnings allowed)
+	exitInvalid = 1 // Problems found
+	exitUsage   = 2 // Bad arguments or I/O failure
+)
+
+// validateReport is the JSON output of cpm validate. Fields are declared in
+// output order, which scripts may rely on, rather than for alignment.
+type validateReport struct { //nolint:govet // fieldalignment: field order is the JSON key order
+	Path     string                   `json:"path"`
+	Valid    bool                     `json:"valid"`
+	Errors   int                      `json:"errors"`
+	Warnings int                      `json:"warnings"`
+	Issues   []claude.ValidationIssue `json:"issues"`
+}
+
+// runValidate implements "cpm validate [--json] [dir]" and returns the exit code.
+func runValidate(_ options, args []string) int {
+	dir, format, code, done := parseValidateArgs(args)
+	if done {
+		return code
+	}
+
+	issues, err := claude.ValidatePlugin(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	report := validateReport{Path: dir, Valid: !claude.HasErrors(issues), Issues: issues}
+	for _, issue := range issues {
+		if issue.Severity == claude.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	if report.Issues == nil {
+		report.Issues = []claude.ValidationIssue{}
+	}
+	return writeValidateReport(os.Stdout, format, report)
+}
+
+// parseValidateArgs parses the arguments of cpm validate. Returns done=true
+// with the exit code if the command should stop (e.g., after --help or a usage error).
+func parseValidateArgs(args []string) (dir, format string, code int, done bool) {
+	format = "text"
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--help" || arg == "-h":
+			printValidateUsage(os.Stdout)
+			return "", "", exitValid, true
+		case arg == "--json":
+			format = "json"
+		case arg == "--format":
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, "Error: --format requires an argument (text, json)")
+				return "", "", exitUsage, true
+			}
+			i++
+			format = args[i]
+		case strings.HasPrefix(arg, "--format="):
+			format = strings.TrimPrefix(arg, "--format=")
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Unknown option: %s\n\n", arg)
+			printValidateUsage(os.Stderr)
+			return "", "", exitUsage, true
+		case dir != "":
+			fmt.Fprintf(os.Stderr, "Error: unexpected argument '%s'; validate takes one plugin directory\n", arg)
+			return "", "", exitUsage, true
+		default:
+			dir = arg
+		}
+	}
+
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid format '%s'. Use: text, json\n", format)
+		return "", "", exitUsage, true
+	}
+	if dir == "" {
+		dir = "."
+	}
+	return dir, format, exitValid, false
+}
+
+// writeValidateReport writes report to w in format and returns the exit code.
+func writeValidateReport(w io.Writer, format string, report validateReport) int {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+	} else {
+		printValidateReport(w, report)
+	}
+
+	if !report.Valid {
+		return exitInvalid
+	}
+	return exitValid
+}
+
+// printValidateReport writes a human-readable validation report.
+func printValidateReport(w io.Writer, report validateReport) {
+	switch {
+	case len(report.Issues) == 0:
+		_, _ = fmt.Fprintf(w, "✓ %s: plugin is valid\n", report.Path)
+		return
+	case report.Valid:
+		_, _ = fmt.Fprintf(w, "✓ %s: plugin is valid with %d warning(s)\n", report.Path, report.Warnings)
+	default:
+		_, _ = fmt.Fprintf(w, "✗ %s: %d error(s), %d warning(s)\n", report.Path, report.Errors, report.Warnings)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	for _, issue := range report.Issues {
+		_, _ = fmt.Fprintf(w, "  %-7s %s: %s\n", issue.Severity, issue.Path, issue.Message)
+	}
+}
+
+func printValidateUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Usage: cpm validate [options] [plugin-dir]")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Check a plugin directory for a missing or invalid plugin.json, components")
+	_, _ = fmt.Fprintln(w, "without frontmatter, malformed hooks.json or .mcp.json, and references to")
+	_, _ = fmt.Fprintln(w, "script files that don't exist. plugin-dir defaults to the current directory.")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Options:")
+	_, _ = fmt.Fprintln(w, "  --json                Output the report as JSON (same as --format=json)")
+	_, _ = fmt.Fprintln(w, "  --format <fmt>        Output format: text, json (default: text)")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Exit status is 0 when the plugin is valid, 1 when errors were found,")
+	_, _ = fmt.Fprintln(w, "and 2 for usage errors or an unreadable directory.")
+}
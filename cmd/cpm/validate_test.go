@@ -0,0 +1,12 @@
+package main
+
+import "testing"
+
+func TestValidateRejectsExtraArguments(t *testing.T) {
+	if _, _, code, done := parseValidateArgs([]string{"a", "b"}); !done || code != exitUsage {
+		t.Errorf("parseValidateArgs(a, b) = code %d, done %v, want usage error", code, done)
+	}
+	if dir, format, _, done := parseValidateArgs([]string{"--json", "a"}); done || dir != "a" || format != "json" {
+		t.Errorf("parseValidateArgs(--json, a) = %q, %q, done %v", dir, format, done)
+	}
+}
@@ -24,6 +24,16 @@ type Client interface {
 
 	// DisablePlugin disables a plugin at the specified scope.
 	DisablePlugin(pluginID string, scope Scope) error
+
+	// AddMarketplace adds a marketplace, declaring it at the given scope.
+	// source is a MarketplaceAddArg value.
+	AddMarketplace(source string, scope Scope) error
+
+	// UpdateMarketplace refreshes a marketplace's clone from its source.
+	UpdateMarketplace(name string) error
+
+	// RemoveMarketplace unregisters a marketplace and deletes its clone.
+	RemoveMarketplace(name string) error
 }
 
 // realClient implements Client by shelling out to the claude CLI.
@@ -60,6 +70,7 @@ func (c *realClient) ListPlugins(includeAvailable bool) (*PluginList, error) {
 
 	// #nosec G204 -- args are hardcoded, not user input
 	cmd := exec.Command(c.claudePath, args...)
+	cmd.Env = subprocessEnv()
 	cmd.Stdout = tmpFile
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
@@ -75,24 +86,40 @@ func (c *realClient) ListPlugins(includeAvailable bool) (*PluginList, error) {
 		return nil, fmt.Errorf("failed to read plugin list output: %w", err)
 	}
 
+	return parsePluginList(stdout)
+}
+
+// parsePluginList parses `claude plugin list --json` output. With --available the
+// CLI returns an object; without it, a bare array of installed plugins.
+func parsePluginList(data []byte) (*PluginList, error) {
 	var list PluginList
-	if err := json.Unmarshal(stdout, &list); err != nil {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &list.Installed); err != nil {
+			return nil, fmt.Errorf("failed to parse plugin list: %w", err)
+		}
+		return &list, nil
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
 		return nil, fmt.Errorf("failed to parse plugin list: %w", err)
 	}
-
 	return &list, nil
 }
 
 // runPluginCommand executes a claude plugin subcommand (install, uninstall, enable, disable).
 func (c *realClient) runPluginCommand(command, pluginID string, scope Scope) error {
-	args := []string{"plugin", command}
+	args := []string{command}
 	if scope != ScopeNone {
 		args = append(args, "--scope", string(scope))
 	}
-	args = append(args, pluginID)
+	return c.run(command, append(args, pluginID)...)
+}
 
-	// #nosec G204 -- args are constructed safely from enum scope
-	cmd := exec.Command(c.claudePath, args...)
+// run executes "claude plugin" with the given arguments. command names the
+// subcommand in errors.
+func (c *realClient) run(command string, args ...string) error {
+	// #nosec G204 -- args are constructed safely from enum scope and registered names
+	cmd := exec.Command(c.claudePath, append([]string{"plugin"}, args...)...)
+	cmd.Env = subprocessEnv()
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
@@ -122,3 +149,18 @@ func (c *realClient) EnablePlugin(pluginID string, scope Scope) error {
 func (c *realClient) DisablePlugin(pluginID string, scope Scope) error {
 	return c.runPluginCommand("disable", pluginID, scope)
 }
+
+// AddMarketplace implements Client.AddMarketplace.
+func (c *realClient) AddMarketplace(source string, scope Scope) error {
+	return c.run("marketplace add", "marketplace", "add", "--scope", string(scope), source)
+}
+
+// UpdateMarketplace implements Client.UpdateMarketplace.
+func (c *realClient) UpdateMarketplace(name string) error {
+	return c.run("marketplace update", "marketplace", "update", name)
+}
+
+// RemoveMarketplace implements Client.RemoveMarketplace.
+func (c *realClient) RemoveMarketplace(name string) error {
+	return c.run("marketplace remove", "marketplace", "remove", name)
+}
@@ -31,3 +31,41 @@ func TestNewClientWithPath(t *testing.T) {
 		t.Errorf("claudePath = %q, want %q", rc.claudePath, "/usr/local/bin/claude")
 	}
 }
+
+func TestParsePluginList(t *testing.T) {
+	t.Run("object with available", func(t *testing.T) {
+		list, err := parsePluginList([]byte(`{"installed":[{"id":"a@mp","scope":"user"}],"available":[{"pluginId":"b@mp"}]}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(list.Installed) != 1 || len(list.Available) != 1 {
+			t.Errorf("list = %+v, want 1 installed and 1 available", list)
+		}
+	})
+
+	t.Run("bare installed array", func(t *testing.T) {
+		list, err := parsePluginList([]byte("\n[{\"id\":\"a@mp\",\"scope\":\"project\"}]\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(list.Installed) != 1 || list.Installed[0].ID != "a@mp" {
+			t.Errorf("Installed = %+v, want [a@mp]", list.Installed)
+		}
+	})
+
+	t.Run("empty array", func(t *testing.T) {
+		list, err := parsePluginList([]byte(`[]`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(list.Installed) != 0 {
+			t.Errorf("Installed = %+v, want empty", list.Installed)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := parsePluginList([]byte(`{"installed":`)); err == nil {
+			t.Error("expected error for invalid JSON")
+		}
+	})
+}
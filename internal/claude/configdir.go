@@ -0,0 +1,66 @@
+package claude
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ConfigDirEnv is the environment variable Claude Code reads its config
+// directory from, used to keep several Claude configurations side by side.
+const ConfigDirEnv = "CLAUDE_CONFIG_DIR"
+
+// errNoConfigDir is returned when neither an override nor a home directory is available.
+var errNoConfigDir = errors.New("can't determine the Claude config directory")
+
+// configDirOverride is set by SetConfigDir and takes precedence over ConfigDirEnv.
+var configDirOverride string
+
+// SetConfigDir makes dir the Claude config directory, overriding
+// CLAUDE_CONFIG_DIR, for the --config-dir flag. Relative paths are made
+// absolute. An empty dir removes the override.
+func SetConfigDir(dir string) {
+	if dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+	configDirOverride = dir
+}
+
+// ConfigDir returns the Claude config directory holding user settings.json
+// and the plugins directory: the SetConfigDir override, then CLAUDE_CONFIG_DIR,
+// then ~/.claude. Returns empty string if none can be determined.
+func ConfigDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "" // User settings are then skipped, which is handled gracefully
+	}
+	return resolveConfigDir(configDirOverride, os.Getenv(ConfigDirEnv), homeDir)
+}
+
+// resolveConfigDir is the internal implementation with injectable inputs for testing.
+func resolveConfigDir(override, env, homeDir string) string {
+	switch {
+	case override != "":
+		return override
+	case env != "":
+		return env
+	case homeDir != "":
+		return filepath.Join(homeDir, ".claude")
+	default:
+		return ""
+	}
+}
+
+// subprocessEnv returns the environment for claude CLI subprocesses, with
+// CLAUDE_CONFIG_DIR set to the SetConfigDir override so the CLI edits the same
+// configuration cpm reads. Without an override the environment is passed
+// through, since setting the variable also moves the CLI's .claude.json.
+func subprocessEnv() []string {
+	env := os.Environ()
+	if configDirOverride != "" {
+		env = append(env, ConfigDirEnv+"="+configDirOverride)
+	}
+	return env
+}
@@ -0,0 +1,86 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResolveConfigDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		env      string
+		homeDir  string
+		want     string
+	}{
+		{"flag wins", "/flag", "/env", "/home/u", "/flag"},
+		{"environment", "", "/env", "/home/u", "/env"},
+		{"home", "", "", "/home/u", filepath.Join("/home/u", ".claude")},
+		{"unknown", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveConfigDir(tt.override, tt.env, tt.homeDir); got != tt.want {
+				t.Errorf("resolveConfigDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetConfigDir(t *testing.T) {
+	t.Cleanup(func() { SetConfigDir("") })
+	t.Setenv(ConfigDirEnv, "/env")
+
+	if got := ConfigDir(); got != "/env" {
+		t.Errorf("ConfigDir() = %q, want CLAUDE_CONFIG_DIR", got)
+	}
+
+	dir := t.TempDir()
+	SetConfigDir(dir)
+	if got := ConfigDir(); got != dir {
+		t.Errorf("ConfigDir() = %q, want override %q", got, dir)
+	}
+	if got := UserSettingsPath(); got != filepath.Join(dir, "settings.json") {
+		t.Errorf("UserSettingsPath() = %q, want settings.json in the override", got)
+	}
+	if env := subprocessEnv(); env[len(env)-1] != ConfigDirEnv+"="+dir {
+		t.Errorf("subprocessEnv() ends with %q, want %s=%s", env[len(env)-1], ConfigDirEnv, dir)
+	}
+
+	SetConfigDir("relative")
+	if got := ConfigDir(); !filepath.IsAbs(got) || filepath.Base(got) != "relative" {
+		t.Errorf("ConfigDir() = %q, want relative override made absolute", got)
+	}
+}
+
+func TestSubprocessEnvWithoutOverride(t *testing.T) {
+	t.Setenv(ConfigDirEnv, "")
+	if err := os.Unsetenv(ConfigDirEnv); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	if i := slices.IndexFunc(subprocessEnv(), func(kv string) bool { return strings.HasPrefix(kv, ConfigDirEnv+"=") }); i >= 0 {
+		t.Errorf("subprocessEnv() sets %q, want CLAUDE_CONFIG_DIR left unset", subprocessEnv()[i])
+	}
+}
+
+func TestConfigDirReadsSettings(t *testing.T) {
+	t.Cleanup(func() { SetConfigDir("") })
+	configDir := t.TempDir()
+	SetConfigDir(configDir)
+
+	if err := SetPluginEnabled(filepath.Join(configDir, "settings.json"), "p@mp", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetAllEnabledPlugins(t.TempDir())["p@mp"]; !got[ScopeUser] {
+		t.Errorf("GetAllEnabledPlugins() user scope = %v, want p@mp from the config dir", got)
+	}
+	if files := WatchedFiles("/work"); !slices.Contains(files, filepath.Join(configDir, "plugins", "known_marketplaces.json")) {
+		t.Errorf("WatchedFiles() = %q, want files in the config dir", files)
+	}
+}
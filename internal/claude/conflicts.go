@@ -0,0 +1,126 @@
+package claude
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ComponentKind identifies the kind of component a conflict is about.
+type ComponentKind string
+
+// Component kinds that share a namespace across plugins.
+const (
+	KindSkill     ComponentKind = "skill"
+	KindAgent     ComponentKind = "agent"
+	KindCommand   ComponentKind = "command"
+	KindMCPServer ComponentKind = "mcp server"
+)
+
+// kindOrder is the display order of component kinds in conflict reports.
+var kindOrder = []ComponentKind{KindCommand, KindAgent, KindSkill, KindMCPServer}
+
+// Conflict is a component name provided by more than one enabled plugin.
+type Conflict struct {
+	Kind      ComponentKind `json:"kind"`
+	Name      string        `json:"name"`
+	PluginIDs []string      `json:"plugins"` // Sorted plugin IDs providing the name
+}
+
+// Others returns the plugins other than pluginID that provide the conflicting name.
+func (c Conflict) Others(pluginID string) []string {
+	var others []string
+	for _, id := range c.PluginIDs {
+		if id != pluginID {
+			others = append(others, id)
+		}
+	}
+	return others
+}
+
+// FindConflicts returns component names provided by more than one plugin.
+// components maps plugin ID to that plugin's components; nil entries are ignored.
+// Conflicts are sorted by kind, then name.
+func FindConflicts(components map[string]*PluginComponents) []Conflict {
+	type key struct {
+		kind ComponentKind
+		name string
+	}
+	providers := make(map[key][]string)
+	add := func(kind ComponentKind, name, pluginID string) {
+		k := key{kind, name}
+		if !slices.Contains(providers[k], pluginID) {
+			providers[k] = append(providers[k], pluginID)
+		}
+	}
+
+	for pluginID, c := range components {
+		if c == nil {
+			continue
+		}
+		for _, s := range c.Skills {
+			add(KindSkill, s.Name, pluginID)
+		}
+		for _, a := range c.Agents {
+			add(KindAgent, a.Name, pluginID)
+		}
+		for _, cmd := range c.Commands {
+			add(KindCommand, cmd.Name, pluginID)
+		}
+		for _, s := range c.MCPs {
+			add(KindMCPServer, s.Name, pluginID)
+		}
+	}
+
+	var conflicts []Conflict
+	for k, ids := range providers {
+		if len(ids) < 2 {
+			continue
+		}
+		slices.Sort(ids)
+		conflicts = append(conflicts, Conflict{Kind: k.kind, Name: k.name, PluginIDs: ids})
+	}
+	slices.SortFunc(conflicts, func(a, b Conflict) int {
+		if c := cmp.Compare(slices.Index(kindOrder, a.Kind), slices.Index(kindOrder, b.Kind)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return conflicts
+}
+
+// ConflictsByPlugin indexes conflicts by each plugin involved.
+func ConflictsByPlugin(conflicts []Conflict) map[string][]Conflict {
+	result := make(map[string][]Conflict)
+	for _, c := range conflicts {
+		for _, id := range c.PluginIDs {
+			result[id] = append(result[id], c)
+		}
+	}
+	return result
+}
+
+// EnabledPluginComponents scans the components of every installed plugin that
+// is enabled in the effective scope set. Plugins without an install path are skipped.
+func EnabledPluginComponents(installed []InstalledPlugin, scopes ScopeState) map[string]*PluginComponents {
+	result := make(map[string]*PluginComponents)
+	for _, p := range installed {
+		if _, seen := result[p.ID]; seen || p.InstallPath == "" || !scopes.Enabled(p.ID) {
+			continue
+		}
+		result[p.ID] = ScanPluginComponents(p.InstallPath)
+	}
+	return result
+}
+
+// Enabled reports whether a plugin is enabled in the effective scope set, where
+// managed policy overrides local settings, which override project settings,
+// which override user settings.
+func (s ScopeState) Enabled(pluginID string) bool {
+	scopes := s[pluginID]
+	for _, scope := range []Scope{ScopeManaged, ScopeLocal, ScopeProject, ScopeUser} {
+		if enabled, ok := scopes[scope]; ok {
+			return enabled
+		}
+	}
+	return false
+}
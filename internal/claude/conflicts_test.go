@@ -0,0 +1,125 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFindConflicts(t *testing.T) {
+	components := map[string]*PluginComponents{
+		"a@mp": {
+			Commands: []Component{{Name: "review"}, {Name: "deploy"}},
+			Agents:   []Component{{Name: "helper"}},
+			MCPs:     []MCPServer{{Name: "github"}},
+		},
+		"b@mp": {
+			Commands: []Component{{Name: "review"}},
+			Skills:   []Component{{Name: "helper"}}, // Same name, different kind: no conflict
+			MCPs:     []MCPServer{{Name: "github"}},
+		},
+		"c@other": {
+			Commands: []Component{{Name: "review"}},
+			Agents:   []Component{{Name: "helper"}},
+		},
+		"d@mp": nil,
+	}
+
+	conflicts := FindConflicts(components)
+	if len(conflicts) != 3 {
+		t.Fatalf("len(conflicts) = %d, want 3: %+v", len(conflicts), conflicts)
+	}
+
+	// Sorted by kind (command, agent, skill, mcp server), then name
+	want := []Conflict{
+		{Kind: KindCommand, Name: "review", PluginIDs: []string{"a@mp", "b@mp", "c@other"}},
+		{Kind: KindAgent, Name: "helper", PluginIDs: []string{"a@mp", "c@other"}},
+		{Kind: KindMCPServer, Name: "github", PluginIDs: []string{"a@mp", "b@mp"}},
+	}
+	for i, w := range want {
+		got := conflicts[i]
+		if got.Kind != w.Kind || got.Name != w.Name || !slices.Equal(got.PluginIDs, w.PluginIDs) {
+			t.Errorf("conflicts[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if others := conflicts[0].Others("b@mp"); !slices.Equal(others, []string{"a@mp", "c@other"}) {
+		t.Errorf("Others(b@mp) = %v", others)
+	}
+
+	byPlugin := ConflictsByPlugin(conflicts)
+	if len(byPlugin["a@mp"]) != 3 || len(byPlugin["b@mp"]) != 2 || len(byPlugin["c@other"]) != 2 {
+		t.Errorf("ConflictsByPlugin counts = a:%d b:%d c:%d, want 3/2/2",
+			len(byPlugin["a@mp"]), len(byPlugin["b@mp"]), len(byPlugin["c@other"]))
+	}
+}
+
+func TestFindConflictsNone(t *testing.T) {
+	conflicts := FindConflicts(map[string]*PluginComponents{
+		"a@mp": {Commands: []Component{{Name: "one"}}},
+		"b@mp": {Commands: []Component{{Name: "two"}}},
+	})
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", conflicts)
+	}
+}
+
+func TestScopeStateEnabled(t *testing.T) {
+	state := ScopeState{
+		"user-only@mp":       {ScopeUser: true},
+		"disabled-local@mp":  {ScopeUser: true, ScopeLocal: false},
+		"enabled-project@mp": {ScopeUser: false, ScopeProject: true},
+		"blocked@mp":         {ScopeLocal: true, ScopeManaged: false},
+	}
+
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"user-only@mp", true},
+		{"disabled-local@mp", false},
+		{"enabled-project@mp", true},
+		{"blocked@mp", false},
+		{"missing@mp", false},
+	}
+	for _, tt := range tests {
+		if got := state.Enabled(tt.id); got != tt.want {
+			t.Errorf("Enabled(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestEnabledPluginComponents(t *testing.T) {
+	tmp := setupTempDir(t, "enabled-components-*")
+	makePlugin := func(name string) string {
+		t.Helper()
+		dir := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Join(dir, "commands"), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "commands", "review.md"), []byte("# Review"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	installed := []InstalledPlugin{
+		{ID: "a@mp", InstallPath: makePlugin("a")},
+		{ID: "b@mp", InstallPath: makePlugin("b")},
+		{ID: "no-path@mp"},
+	}
+	scopes := ScopeState{
+		"a@mp":       {ScopeUser: true},
+		"b@mp":       {ScopeUser: true, ScopeLocal: false},
+		"no-path@mp": {ScopeUser: true},
+	}
+
+	components := EnabledPluginComponents(installed, scopes)
+	if len(components) != 1 || components["a@mp"] == nil {
+		t.Fatalf("components = %v, want only a@mp", components)
+	}
+	if len(components["a@mp"].Commands) != 1 {
+		t.Errorf("a@mp commands = %+v", components["a@mp"].Commands)
+	}
+}
@@ -0,0 +1,109 @@
+package claude
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DirUsage is the total size and file count of a directory tree.
+type DirUsage struct {
+	Bytes int64
+	Files int
+}
+
+// Add returns the sum of two usages.
+func (u DirUsage) Add(other DirUsage) DirUsage {
+	return DirUsage{Bytes: u.Bytes + other.Bytes, Files: u.Files + other.Files}
+}
+
+// MeasureDir returns the total size and number of regular files under dir.
+// Symlinks are not followed, so vendored links aren't counted twice.
+func MeasureDir(dir string) (DirUsage, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return DirUsage{}, err
+	}
+	defer func() { _ = root.Close() }()
+
+	return MeasureDirFS(root.FS())
+}
+
+// MeasureDirFS returns the total size and number of regular files in the given filesystem.
+// Entries that can't be read are skipped.
+func MeasureDirFS(fsys fs.FS) (DirUsage, error) {
+	var usage DirUsage
+	err := fs.WalkDir(fsys, ".", func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable subdirectory: skip it rather than failing the whole walk
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, infoErr := d.Info()
+		if infoErr != nil {
+			return nil
+		}
+		usage.Bytes += info.Size()
+		usage.Files++
+		return nil
+	})
+	return usage, err
+}
+
+// PluginsUsage breaks down disk usage under ~/.claude/plugins.
+type PluginsUsage struct {
+	Marketplaces DirUsage // Cloned marketplaces (marketplaces/)
+	Cache        DirUsage // Installed plugin copies (cache/)
+	Other        DirUsage // Everything else (registry files, temp data)
+}
+
+// Total returns the combined usage of the plugins directory.
+func (u PluginsUsage) Total() DirUsage {
+	return u.Marketplaces.Add(u.Cache).Add(u.Other)
+}
+
+// MeasurePluginsDir measures disk usage under ~/.claude/plugins.
+func MeasurePluginsDir() (PluginsUsage, error) {
+	pluginsDir, err := userPluginsDir()
+	if err != nil {
+		return PluginsUsage{}, err
+	}
+	return MeasurePluginsDirFrom(pluginsDir)
+}
+
+// MeasurePluginsDirFrom measures disk usage of a plugins directory, split into
+// marketplace clones, installed caches, and everything else.
+func MeasurePluginsDirFrom(pluginsDir string) (PluginsUsage, error) {
+	entries, err := os.ReadDir(pluginsDir)
+	if err != nil {
+		return PluginsUsage{}, err
+	}
+
+	var usage PluginsUsage
+	for _, entry := range entries {
+		var entryUsage DirUsage
+		switch {
+		case entry.IsDir():
+			entryUsage, _ = MeasureDir(filepath.Join(pluginsDir, entry.Name()))
+		case entry.Type().IsRegular():
+			if info, infoErr := entry.Info(); infoErr == nil {
+				entryUsage = DirUsage{Bytes: info.Size(), Files: 1}
+			}
+		}
+
+		switch entry.Name() {
+		case "marketplaces":
+			usage.Marketplaces = usage.Marketplaces.Add(entryUsage)
+		case "cache":
+			usage.Cache = usage.Cache.Add(entryUsage)
+		default:
+			usage.Other = usage.Other.Add(entryUsage)
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,71 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMeasureDirFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".claude-plugin/plugin.json": {Data: []byte(`{"name":"p"}`)},
+		"commands/review.md":         {Data: []byte("# Review\n")},
+		"node_modules/dep/index.js":  {Data: make([]byte, 1000)},
+		"empty":                      {Mode: 0o755 | os.ModeDir},
+	}
+
+	usage, err := MeasureDirFS(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Files != 3 {
+		t.Errorf("Files = %d, want 3", usage.Files)
+	}
+	if want := int64(12 + 9 + 1000); usage.Bytes != want {
+		t.Errorf("Bytes = %d, want %d", usage.Bytes, want)
+	}
+}
+
+func TestMeasureDirMissing(t *testing.T) {
+	if _, err := MeasureDir(filepath.Join(os.TempDir(), "cpm-does-not-exist")); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestMeasurePluginsDirFrom(t *testing.T) {
+	tmp := setupTempDir(t, "diskusage-*")
+
+	write := func(rel string, size int) {
+		t.Helper()
+		path := filepath.Join(tmp, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("marketplaces/acme/.claude-plugin/marketplace.json", 100)
+	write("marketplaces/acme/README.md", 50)
+	write("cache/acme/linter/1.0.0/.claude-plugin/plugin.json", 30)
+	write("known_marketplaces.json", 20)
+	write("repos/tmp/file", 5)
+
+	usage, err := MeasurePluginsDirFrom(tmp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Marketplaces != (DirUsage{Bytes: 150, Files: 2}) {
+		t.Errorf("Marketplaces = %+v, want {150 2}", usage.Marketplaces)
+	}
+	if usage.Cache != (DirUsage{Bytes: 30, Files: 1}) {
+		t.Errorf("Cache = %+v, want {30 1}", usage.Cache)
+	}
+	if usage.Other != (DirUsage{Bytes: 25, Files: 2}) {
+		t.Errorf("Other = %+v, want {25 2}", usage.Other)
+	}
+	if total := usage.Total(); total != (DirUsage{Bytes: 205, Files: 5}) {
+		t.Errorf("Total() = %+v, want {205 5}", total)
+	}
+}
@@ -0,0 +1,175 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-cli-collective/cpm/internal/jsonedit"
+)
+
+// extraKey is the settings key holding extra marketplaces.
+const extraKey = "extraKnownMarketplaces"
+
+// ReadExtraMarketplaces returns the extraKnownMarketplaces entries of a
+// settings file. A missing file has none.
+func ReadExtraMarketplaces(settingsPath string) (map[string]MarketplaceEntry, error) {
+	data, err := readSettingsFile(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+	rawSettings, err := parseRawSettings(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]MarketplaceEntry)
+	if raw, ok := rawSettings[extraKey]; ok {
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", extraKey, err)
+		}
+	}
+	return entries, nil
+}
+
+// readSettingsFile reads a settings file, returning nil data if it doesn't exist.
+func readSettingsFile(settingsPath string) ([]byte, error) {
+	root, err := os.OpenRoot(filepath.Dir(settingsPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+
+	data, err := fs.ReadFile(root.FS(), filepath.Base(settingsPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read settings: %w", err)
+	}
+	return data, nil
+}
+
+// PreviewExtraMarketplace returns a settings file's contents before and after
+// SetExtraMarketplace(settingsPath, name, entry). Nothing is written.
+func PreviewExtraMarketplace(settingsPath, name string, entry *MarketplaceEntry) (before, after []byte, err error) {
+	before, err = readSettingsFile(settingsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if before == nil && entry == nil {
+		return nil, nil, nil
+	}
+	after, err = editExtra(before, name, entry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("update settings: %w", err)
+	}
+	return before, after, nil
+}
+
+// SetExtraMarketplace sets extraKnownMarketplaces[name] in a settings file to
+// entry, creating the file if needed, or removes it when entry is nil. The
+// extraKnownMarketplaces key is dropped when its last entry is removed.
+func SetExtraMarketplace(settingsPath, name string, entry *MarketplaceEntry) error {
+	return editSettings(settingsPath, entry != nil, func(data []byte) ([]byte, error) {
+		return editExtra(data, name, entry)
+	})
+}
+
+// editExtra sets or removes one extraKnownMarketplaces entry in settings data.
+func editExtra(data []byte, name string, entry *MarketplaceEntry) ([]byte, error) {
+	if entry != nil {
+		return jsonedit.Set(data, []string{extraKey, name}, *entry)
+	}
+
+	data, err := jsonedit.Delete(data, []string{extraKey, name})
+	if err != nil {
+		return nil, err
+	}
+	rawSettings, err := parseRawSettings(data)
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := rawSettings[extraKey]; ok && strings.TrimSpace(string(raw)) == "{}" {
+		return jsonedit.Delete(data, []string{extraKey})
+	}
+	return data, nil
+}
+
+// SourceFields reports which of the editable ref and path fields a source type has.
+func SourceFields(src MarketplaceSource) (hasRef, hasPath bool) {
+	switch src.SourceType() {
+	case "github", "git":
+		return true, true
+	case "file", "directory":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// SourceRefAndPath returns a source's ref and path, empty when it has none.
+func SourceRefAndPath(src MarketplaceSource) (ref, path string) {
+	data, err := marshalSource(src)
+	if err != nil {
+		return "", ""
+	}
+	var fields struct {
+		Ref  string `json:"ref"`
+		Path string `json:"path"`
+	}
+	_ = json.Unmarshal(data, &fields)
+	return fields.Ref, fields.Path
+}
+
+// EditSource returns a copy of src with its ref and path replaced. Empty
+// values remove optional fields. The result is validated by round-tripping it
+// through the settings JSON form, so it is written exactly as returned.
+func EditSource(src MarketplaceSource, ref, path string) (MarketplaceSource, error) {
+	hasRef, hasPath := SourceFields(src)
+	ref, path = strings.TrimSpace(ref), strings.TrimSpace(path)
+	switch {
+	case ref != "" && !hasRef:
+		return nil, fmt.Errorf("%s sources have no ref", src.SourceType())
+	case path != "" && !hasPath:
+		return nil, fmt.Errorf("%s sources have no path", src.SourceType())
+	case strings.ContainsAny(ref, " \t"):
+		return nil, fmt.Errorf("invalid ref %q", ref)
+	case path == "" && hasPath && !hasRef:
+		return nil, fmt.Errorf("%s sources need a path", src.SourceType())
+	}
+
+	data, err := marshalSource(src)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	setOptional := func(key, value string) {
+		if value == "" {
+			delete(fields, key)
+		} else {
+			fields[key] = value
+		}
+	}
+	if hasRef {
+		setOptional("ref", ref)
+	}
+	if hasPath {
+		setOptional("path", path)
+	}
+
+	if data, err = json.Marshal(fields); err != nil {
+		return nil, err
+	}
+	return unmarshalSource(data)
+}
@@ -0,0 +1,104 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadExtraMarketplaces(t *testing.T) {
+	tmp := setupTempDir(t, "extras-*")
+	settingsPath := setupClaudeDir(t, tmp, `{
+  "extraKnownMarketplaces": {
+    "mp": {"source": {"source": "github", "repo": "owner/repo", "ref": "v1"}},
+    "local": {"source": {"source": "directory", "path": "/src/local"}}
+  }
+}`)
+
+	got, err := ReadExtraMarketplaces(settingsPath)
+	if err != nil {
+		t.Fatalf("ReadExtraMarketplaces: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+	if s := got["mp"].Source.String(); s != "owner/repo@v1" {
+		t.Errorf("mp source = %q, want owner/repo@v1", s)
+	}
+
+	missing, err := ReadExtraMarketplaces(filepath.Join(tmp, "nope", "settings.json"))
+	if err != nil || len(missing) != 0 {
+		t.Errorf("missing file = (%v, %v), want no entries", missing, err)
+	}
+}
+
+func TestSetExtraMarketplace(t *testing.T) {
+	tmp := setupTempDir(t, "extras-*")
+	original := "{\n  \"enabledPlugins\": {\n    \"p@mp\": true\n  }\n}\n"
+	settingsPath := setupClaudeDir(t, tmp, original)
+	entry := &MarketplaceEntry{Source: &GitHubSource{Repo: "owner/repo"}}
+
+	before, after, err := PreviewExtraMarketplace(settingsPath, "mp", entry)
+	if err != nil {
+		t.Fatalf("PreviewExtraMarketplace: %v", err)
+	}
+	if string(before) != original || !strings.Contains(string(after), `"repo": "owner/repo"`) {
+		t.Errorf("preview = (%s, %s), want the entry added", before, after)
+	}
+	if got, _ := os.ReadFile(settingsPath); string(got) != original {
+		t.Error("preview should not write")
+	}
+
+	if err := SetExtraMarketplace(settingsPath, "mp", entry); err != nil {
+		t.Fatalf("SetExtraMarketplace: %v", err)
+	}
+	if got, _ := os.ReadFile(settingsPath); string(got) != string(after) {
+		t.Errorf("written = %s, want the previewed contents %s", got, after)
+	}
+
+	if err := SetExtraMarketplace(settingsPath, "mp", nil); err != nil {
+		t.Fatalf("SetExtraMarketplace(nil): %v", err)
+	}
+	if got, _ := os.ReadFile(settingsPath); strings.Contains(string(got), "extraKnownMarketplaces") {
+		t.Errorf("settings = %s, want the empty key dropped", got)
+	}
+}
+
+func TestEditSource(t *testing.T) {
+	tests := []struct {
+		src     MarketplaceSource
+		name    string
+		ref     string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "github ref and path", src: &GitHubSource{Repo: "o/r"}, ref: "v2", path: "plugins", want: "o/r@v2/plugins"},
+		{name: "clear ref", src: &GitHubSource{Repo: "o/r", Ref: "v1"}, want: "o/r"},
+		{name: "git ref", src: &GitSource{URL: "https://example.com/r.git"}, ref: " main ", want: "https://example.com/r.git@main"},
+		{name: "directory path", src: &DirectorySource{Path: "/old"}, path: "/new", want: "/new"},
+		{name: "directory needs path", src: &DirectorySource{Path: "/old"}, wantErr: true},
+		{name: "no ref on directory", src: &DirectorySource{Path: "/old"}, ref: "v1", path: "/old", wantErr: true},
+		{name: "no path on npm", src: &NPMSource{Package: "pkg"}, path: "x", wantErr: true},
+		{name: "ref with spaces", src: &GitHubSource{Repo: "o/r"}, ref: "a b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EditSource(tt.src, tt.ref, tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EditSource() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EditSource: %v", err)
+			}
+			if got.SourceType() != tt.src.SourceType() || got.String() != tt.want {
+				t.Errorf("EditSource() = %s %q, want %s %q", got.SourceType(), got.String(), tt.src.SourceType(), tt.want)
+			}
+		})
+	}
+}
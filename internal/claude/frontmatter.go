@@ -0,0 +1,310 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Component describes a skill, agent, or command, including the metadata
+// declared in its markdown frontmatter.
+type Component struct {
+	Name           string
+	Description    string
+	Model          string
+	ArgumentHint   string
+	Path           string   // Path relative to the plugin root
+	AllowedTools   []string // From allowed-tools (skills, commands) or tools (agents)
+	HasFrontmatter bool
+}
+
+// errUnterminatedFrontmatter is returned when a file opens a frontmatter block but never closes it.
+var errUnterminatedFrontmatter = errors.New("unterminated frontmatter block")
+
+// frontmatter holds the top-level keys of a YAML frontmatter block.
+// Block lists are joined with ", " so every value is a plain string.
+type frontmatter map[string]string
+
+// parseFrontmatter extracts the YAML frontmatter from a markdown document.
+// Only the subset of YAML used by Claude Code components is supported: scalar
+// values (plain or quoted), inline and block lists, block scalars (| and >),
+// and trailing comments.
+// Returns found=false when the document has no frontmatter.
+func parseFrontmatter(data []byte) (fm frontmatter, found bool, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return nil, false, nil
+	}
+
+	var lines []string
+	closed := false
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "---" {
+			closed = true
+			break
+		}
+		lines = append(lines, line)
+	}
+	if !closed {
+		return nil, true, errUnterminatedFrontmatter
+	}
+
+	fm, err = parseFrontmatterLines(lines)
+	return fm, true, err
+}
+
+// parseFrontmatterLines parses the lines between the frontmatter delimiters.
+func parseFrontmatterLines(lines []string) (frontmatter, error) {
+	fm := make(frontmatter)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("frontmatter line %d: unexpected indentation", i+1)
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("frontmatter line %d: expected \"key: value\"", i+1)
+		}
+		key = strings.TrimSpace(key)
+		value = stripYAMLComment(strings.TrimSpace(value))
+
+		// Collect indented continuation lines for block lists and block
+		// scalars. Block list items may also start at column 0.
+		var block []string
+		for i+1 < len(lines) && isContinuation(lines[i+1], value == "") {
+			i++
+			block = append(block, strings.TrimSpace(lines[i]))
+		}
+
+		fm[key] = frontmatterValue(value, block)
+	}
+	return fm, nil
+}
+
+// isContinuation reports whether line continues the previous key's value:
+// it is blank or indented, or, after a key with no inline value, a block
+// list item.
+func isContinuation(line string, emptyValue bool) bool {
+	if line == "" || line[0] == ' ' || line[0] == '\t' {
+		return true
+	}
+	return emptyValue && (line == "-" || strings.HasPrefix(line, "- "))
+}
+
+// frontmatterValue resolves a key's value from its inline text and any indented block lines.
+func frontmatterValue(value string, block []string) string {
+	switch {
+	case strings.HasPrefix(value, "|"):
+		return strings.TrimSpace(strings.Join(block, "\n"))
+	case strings.HasPrefix(value, ">"):
+		return joinNonEmpty(block, " ")
+	case value == "" && len(block) > 0 && strings.HasPrefix(block[0], "- "):
+		var items []string
+		for _, b := range block {
+			if item, ok := strings.CutPrefix(b, "- "); ok {
+				items = append(items, unquoteYAML(stripYAMLComment(strings.TrimSpace(item))))
+			}
+		}
+		return strings.Join(items, ", ")
+	case value == "":
+		return joinNonEmpty(block, " ")
+	default:
+		// Plain scalars may continue on indented lines
+		if len(block) > 0 {
+			value += " " + joinNonEmpty(block, " ")
+		}
+		return unquoteYAML(value)
+	}
+}
+
+// joinNonEmpty joins the non-blank lines with sep.
+func joinNonEmpty(lines []string, sep string) string {
+	var parts []string
+	for _, l := range lines {
+		if l != "" {
+			parts = append(parts, l)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// stripYAMLComment removes a trailing "# comment" from a trimmed scalar. In
+// plain scalars a comment starts at a # after whitespace; in quoted scalars it
+// can only follow the closing quote.
+func stripYAMLComment(s string) string {
+	if strings.HasPrefix(s, "#") {
+		return ""
+	}
+	start := 0
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		start = closingQuote(s)
+		if start < 0 {
+			return s
+		}
+	}
+	for i := start; i < len(s); i++ {
+		if s[i] == '#' && i > 0 && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return strings.TrimSpace(s[:i])
+		}
+	}
+	return s
+}
+
+// closingQuote returns the index of the quote closing the scalar s opens, or
+// -1 if it isn't closed.
+func closingQuote(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++ // Skip the escaped character
+		case q == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++ // '' is an escaped quote
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteYAML strips YAML single or double quotes from a scalar.
+func unquoteYAML(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"':
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	default:
+		return s
+	}
+}
+
+// splitToolList splits an allowed-tools value into individual tools.
+// Accepts "Read, Grep", "[Read, Grep]", and patterns with commas inside
+// parentheses such as "Bash(git add:*, git commit:*)".
+func splitToolList(s string) []string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		s = s[1 : len(s)-1]
+	}
+
+	var tools []string
+	depth, start := 0, 0
+	add := func(t string) {
+		if t = unquoteYAML(strings.TrimSpace(t)); t != "" {
+			tools = append(tools, t)
+		}
+	}
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				add(s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(s[start:])
+	return tools
+}
+
+// readComponentFS reads a component's markdown file and returns its metadata.
+// fallbackName is used when the frontmatter doesn't declare a name (or for
+// commands, whose name always comes from the file name).
+func readComponentFS(fsys fs.FS, p, fallbackName string, useFrontmatterName bool) Component {
+	c := Component{Name: fallbackName, Path: p}
+
+	data, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return c
+	}
+	fm, found, err := parseFrontmatter(data)
+	if !found || err != nil {
+		return c
+	}
+
+	c.HasFrontmatter = true
+	if name := fm["name"]; useFrontmatterName && name != "" {
+		c.Name = name
+	}
+	c.Description = fm["description"]
+	c.Model = fm["model"]
+	c.ArgumentHint = fm["argument-hint"]
+	if tools, ok := fm["allowed-tools"]; ok {
+		c.AllowedTools = splitToolList(tools)
+	} else if tools, ok := fm["tools"]; ok {
+		c.AllowedTools = splitToolList(tools)
+	}
+	return c
+}
+
+// scanSkillsFS returns the skills under dir; each subdirectory is a skill described by its SKILL.md.
+func scanSkillsFS(fsys fs.FS, dir string) []Component {
+	var skills []Component
+	for _, name := range listSubdirectoriesFS(fsys, dir) {
+		skills = append(skills, readComponentFS(fsys, path.Join(dir, name, "SKILL.md"), name, true))
+	}
+	return skills
+}
+
+// scanMarkdownComponentsFS returns a component for each .md file under p,
+// which may be a directory or a single markdown file.
+func scanMarkdownComponentsFS(fsys fs.FS, p string, useFrontmatterName bool) []Component {
+	info, err := fs.Stat(fsys, p)
+	if err != nil {
+		return nil
+	}
+
+	if !info.IsDir() {
+		name, ok := strings.CutSuffix(path.Base(p), ".md")
+		if !ok {
+			return nil
+		}
+		return []Component{readComponentFS(fsys, p, name, useFrontmatterName)}
+	}
+
+	var components []Component
+	for _, name := range listMarkdownFilesFS(fsys, p) {
+		components = append(components, readComponentFS(fsys, path.Join(p, name+".md"), name, useFrontmatterName))
+	}
+	return components
+}
+
+// appendComponents appends components, skipping any whose path is already present.
+func appendComponents(list []Component, items ...Component) []Component {
+	for _, item := range items {
+		dup := false
+		for _, existing := range list {
+			if existing.Path == item.Path {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,162 @@
+package claude
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	t.Run("scalars and lists", func(t *testing.T) {
+		data := []byte(`---
+name: reviewer
+description: "Reviews code: carefully"
+model: sonnet
+tools:
+  - Read
+  - Grep
+allowed-tools: [Bash(git add:*, git status:*), Read]
+---
+# Body
+`)
+		fm, found, err := parseFrontmatter(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !found {
+			t.Fatal("expected frontmatter to be found")
+		}
+		if fm["name"] != "reviewer" {
+			t.Errorf("name = %q, want reviewer", fm["name"])
+		}
+		if fm["description"] != "Reviews code: carefully" {
+			t.Errorf("description = %q", fm["description"])
+		}
+		if fm["tools"] != "Read, Grep" {
+			t.Errorf("tools = %q, want %q", fm["tools"], "Read, Grep")
+		}
+		tools := splitToolList(fm["allowed-tools"])
+		want := []string{"Bash(git add:*, git status:*)", "Read"}
+		if !slices.Equal(tools, want) {
+			t.Errorf("allowed-tools = %v, want %v", tools, want)
+		}
+	})
+
+	t.Run("unindented lists and comments", func(t *testing.T) {
+		data := []byte(`---
+name: reviewer # shown in /agents
+description: "Finds # bugs" # quoted
+model: 'it''s' # single quoted
+tools:
+- Read
+- Grep # search
+color: blue#green
+---
+`)
+		fm, _, err := parseFrontmatter(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := frontmatter{
+			"name":        "reviewer",
+			"description": "Finds # bugs",
+			"model":       "it's",
+			"tools":       "Read, Grep",
+			"color":       "blue#green",
+		}
+		for k, v := range want {
+			if fm[k] != v {
+				t.Errorf("%s = %q, want %q", k, fm[k], v)
+			}
+		}
+	})
+
+	t.Run("block scalars", func(t *testing.T) {
+		data := []byte("---\ndescription: >\n  Folded\n  text\nnotes: |\n  line one\n  line two\n---\n")
+		fm, _, err := parseFrontmatter(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fm["description"] != "Folded text" {
+			t.Errorf("description = %q, want %q", fm["description"], "Folded text")
+		}
+		if fm["notes"] != "line one\nline two" {
+			t.Errorf("notes = %q", fm["notes"])
+		}
+	})
+
+	t.Run("no frontmatter", func(t *testing.T) {
+		_, found, err := parseFrontmatter([]byte("# Just markdown\n"))
+		if err != nil || found {
+			t.Errorf("found = %v, err = %v; want false, nil", found, err)
+		}
+	})
+
+	t.Run("unterminated", func(t *testing.T) {
+		_, found, err := parseFrontmatter([]byte("---\nname: x\n"))
+		if !found || !errors.Is(err, errUnterminatedFrontmatter) {
+			t.Errorf("found = %v, err = %v; want true, errUnterminatedFrontmatter", found, err)
+		}
+	})
+
+	t.Run("malformed line", func(t *testing.T) {
+		_, _, err := parseFrontmatter([]byte("---\njust text\n---\n"))
+		if err == nil {
+			t.Error("expected error for line without key")
+		}
+	})
+}
+
+func TestScanPluginComponentsFrontmatter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"skills/pdf/SKILL.md": &fstest.MapFile{
+			Data: []byte("---\nname: pdf-tools\ndescription: Work with PDFs\nallowed-tools: Read, Bash\n---\n"),
+		},
+		"agents/planner.md": &fstest.MapFile{
+			Data: []byte("---\nname: code-planner\ndescription: Plans changes\nmodel: opus\n---\n"),
+		},
+		"commands/review.md": &fstest.MapFile{
+			Data: []byte("---\nname: ignored\ndescription: Review a PR\nargument-hint: [pr-number]\n---\n"),
+		},
+		"commands/bare.md": &fstest.MapFile{Data: []byte("No frontmatter here.\n")},
+	}
+
+	components := ScanPluginComponentsFS(fsys)
+
+	if len(components.Skills) != 1 {
+		t.Fatalf("Skills = %v, want 1 entry", components.Skills)
+	}
+	skill := components.Skills[0]
+	if skill.Name != "pdf-tools" || skill.Description != "Work with PDFs" {
+		t.Errorf("skill = %+v", skill)
+	}
+	if !slices.Equal(skill.AllowedTools, []string{"Read", "Bash"}) {
+		t.Errorf("skill tools = %v", skill.AllowedTools)
+	}
+	if skill.Path != "skills/pdf/SKILL.md" {
+		t.Errorf("skill path = %q", skill.Path)
+	}
+
+	if len(components.Agents) != 1 || components.Agents[0].Name != "code-planner" || components.Agents[0].Model != "opus" {
+		t.Errorf("Agents = %+v", components.Agents)
+	}
+
+	if len(components.Commands) != 2 {
+		t.Fatalf("Commands = %+v, want 2", components.Commands)
+	}
+	for _, c := range components.Commands {
+		switch c.Name {
+		case "review":
+			if c.Description != "Review a PR" || c.ArgumentHint != "[pr-number]" || !c.HasFrontmatter {
+				t.Errorf("review command = %+v", c)
+			}
+		case "bare":
+			if c.HasFrontmatter {
+				t.Error("bare command should not have frontmatter")
+			}
+		default:
+			t.Errorf("unexpected command %q (commands are named by file)", c.Name)
+		}
+	}
+}
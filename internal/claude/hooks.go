@@ -0,0 +1,161 @@
+package claude
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// pluginRootVar is the placeholder Claude Code expands to the plugin's install directory.
+const pluginRootVar = "${CLAUDE_PLUGIN_ROOT}"
+
+// hookEventOrder is the lifecycle order used to sort hook events for display.
+var hookEventOrder = []string{
+	"SessionStart",
+	"UserPromptSubmit",
+	"PreToolUse",
+	"PostToolUse",
+	"Notification",
+	"Stop",
+	"SubagentStop",
+	"PreCompact",
+	"SessionEnd",
+}
+
+// Hook is a single handler from a plugin's hooks configuration.
+type Hook struct {
+	Event   string // Lifecycle event, e.g. "PreToolUse"
+	Matcher string // Tool matcher; empty when the event has none
+	Type    string // "command" or "prompt"
+	Command string // Command line for command hooks, prompt text for prompt hooks
+	Source  string // Config the hook came from, relative to the plugin root
+	Timeout int    // Timeout in seconds, 0 if unset
+}
+
+// String describes the hook, e.g. "on PreToolUse(Bash) runs scripts/check.sh".
+func (h Hook) String() string {
+	trigger := "on " + h.Event
+	if h.Matcher != "" && h.Matcher != "*" {
+		trigger += "(" + h.Matcher + ")"
+	}
+	if h.Type == "prompt" {
+		return trigger + " prompts " + truncate(h.Command, 60)
+	}
+	return trigger + " runs " + trimPluginRoot(h.Command)
+}
+
+// trimPluginRoot shortens "${CLAUDE_PLUGIN_ROOT}/scripts/x.sh" to "scripts/x.sh" for display.
+func trimPluginRoot(command string) string {
+	return strings.ReplaceAll(command, pluginRootVar+"/", "")
+}
+
+// truncate shortens s to at most n runes, adding "..." when cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
+
+// hookMatcherRaw is one matcher group in a hooks config.
+type hookMatcherRaw struct {
+	Matcher string           `json:"matcher,omitempty"`
+	Hooks   []hookHandlerRaw `json:"hooks"`
+}
+
+// hookHandlerRaw is one handler within a matcher group.
+type hookHandlerRaw struct {
+	Type    string `json:"type"`
+	Command string `json:"command,omitempty"`
+	Prompt  string `json:"prompt,omitempty"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+// parseHooksConfig parses a hooks config into a flat list of hooks. The config
+// is either the hooks.json form {"hooks": {"Event": [...]}} or, for configs
+// declared inline in plugin.json, the bare event map.
+func parseHooksConfig(data []byte, source string) ([]Hook, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, err
+	}
+	eventsRaw := json.RawMessage(data)
+	if inner, ok := top["hooks"]; ok {
+		eventsRaw = inner
+	}
+
+	var events map[string][]hookMatcherRaw
+	if err := json.Unmarshal(eventsRaw, &events); err != nil {
+		return nil, fmt.Errorf("parse hook events: %w", err)
+	}
+
+	var hooks []Hook
+	for _, event := range sortHookEvents(slices.Collect(maps.Keys(events))) {
+		for _, group := range events[event] {
+			for _, handler := range group.Hooks {
+				h := Hook{
+					Event:   event,
+					Matcher: group.Matcher,
+					Type:    cmp.Or(handler.Type, "command"),
+					Command: handler.Command,
+					Source:  source,
+					Timeout: handler.Timeout,
+				}
+				if h.Type == "prompt" {
+					h.Command = handler.Prompt
+				}
+				hooks = append(hooks, h)
+			}
+		}
+	}
+	return hooks, nil
+}
+
+// sortHookEvents orders events by lifecycle, with unknown events last in alphabetical order.
+func sortHookEvents(events []string) []string {
+	rank := func(e string) int {
+		if i := slices.Index(hookEventOrder, e); i >= 0 {
+			return i
+		}
+		return len(hookEventOrder)
+	}
+	slices.SortFunc(events, func(a, b string) int {
+		if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return events
+}
+
+// readHooksFS reads and parses a hooks config file.
+func readHooksFS(fsys fs.FS, p string) ([]Hook, error) {
+	data, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return nil, err
+	}
+	return parseHooksConfig(data, p)
+}
+
+// scanHooksFS collects hooks from hooks/hooks.json, any hook config paths
+// declared in the manifest, and hooks declared inline in plugin.json.
+// Missing or malformed configs are skipped.
+func scanHooksFS(fsys fs.FS, manifest *PluginManifest) []Hook {
+	var hooks []Hook
+	for _, p := range configPaths("hooks/hooks.json", manifest.Hooks) {
+		if parsed, err := readHooksFS(fsys, p); err == nil {
+			hooks = append(hooks, parsed...)
+		}
+	}
+	if len(manifest.InlineHooks) > 0 {
+		if parsed, err := parseHooksConfig(manifest.InlineHooks, manifestPath); err == nil {
+			hooks = append(hooks, parsed...)
+		}
+	}
+	return hooks
+}
@@ -0,0 +1,75 @@
+package claude
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseHooksConfig(t *testing.T) {
+	t.Run("hooks.json form", func(t *testing.T) {
+		data := []byte(`{
+			"description": "Safety checks",
+			"hooks": {
+				"PostToolUse": [{"matcher": "Write|Edit", "hooks": [{"type": "command", "command": "${CLAUDE_PLUGIN_ROOT}/scripts/format.sh", "timeout": 30}]}],
+				"PreToolUse": [{"matcher": "Bash", "hooks": [{"type": "command", "command": "${CLAUDE_PLUGIN_ROOT}/scripts/check.sh"}]}],
+				"Stop": [{"hooks": [{"type": "prompt", "prompt": "Check that all tasks are complete"}]}]
+			}
+		}`)
+
+		hooks, err := parseHooksConfig(data, "hooks/hooks.json")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(hooks) != 3 {
+			t.Fatalf("len(hooks) = %d, want 3", len(hooks))
+		}
+
+		want := []string{
+			"on PreToolUse(Bash) runs scripts/check.sh",
+			"on PostToolUse(Write|Edit) runs scripts/format.sh",
+			"on Stop prompts Check that all tasks are complete",
+		}
+		for i, h := range hooks {
+			if h.String() != want[i] {
+				t.Errorf("hooks[%d] = %q, want %q", i, h.String(), want[i])
+			}
+		}
+		if hooks[1].Timeout != 30 {
+			t.Errorf("Timeout = %d, want 30", hooks[1].Timeout)
+		}
+		if hooks[0].Source != "hooks/hooks.json" {
+			t.Errorf("Source = %q", hooks[0].Source)
+		}
+	})
+
+	t.Run("inline event map", func(t *testing.T) {
+		data := []byte(`{"SessionStart": [{"matcher": "*", "hooks": [{"type": "command", "command": "echo start"}]}]}`)
+
+		hooks, err := parseHooksConfig(data, ".claude-plugin/plugin.json")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(hooks) != 1 || hooks[0].String() != "on SessionStart runs echo start" {
+			t.Errorf("hooks = %+v", hooks)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		if _, err := parseHooksConfig([]byte(`{"hooks": {"PreToolUse": "nope"}}`), "hooks/hooks.json"); err == nil {
+			t.Error("expected error for malformed hooks")
+		}
+	})
+}
+
+func TestScanHooksInlineManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		".claude-plugin/plugin.json": &fstest.MapFile{
+			Data: []byte(`{"name":"p","hooks":{"hooks":{"PreToolUse":[{"matcher":"Read","hooks":[{"type":"command","command":"audit"}]}]}}}`),
+		},
+	}
+
+	components := ScanPluginComponentsFS(fsys)
+	if len(components.Hooks) != 1 || components.Hooks[0].String() != "on PreToolUse(Read) runs audit" {
+		t.Errorf("Hooks = %+v", components.Hooks)
+	}
+}
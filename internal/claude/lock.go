@@ -0,0 +1,128 @@
+package claude
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Settings files are shared with Claude Code and other cpm instances. Writers
+// take an advisory lock file next to the settings file, and because Claude
+// Code doesn't honor it, each write also checks the file is unchanged since it
+// was read before renaming over it.
+const (
+	lockStaleAfter = 30 * time.Second       // Locks older than this are left over from a crash
+	lockPoll       = 25 * time.Millisecond  // Delay between attempts to take the lock
+	editAttempts   = 3                      // Read-modify-write attempts before reporting a conflict
+	editRetryDelay = 100 * time.Millisecond // Delay before re-reading a file that changed mid-edit
+)
+
+// lockWait is how long to wait for another writer's lock. Tests shorten it.
+var lockWait = 5 * time.Second
+
+var (
+	// ErrSettingsLocked is returned when another writer holds a settings file's lock.
+	ErrSettingsLocked = errors.New("settings file is locked by another process")
+
+	// ErrSettingsConflict is returned when a settings file keeps changing while
+	// it is being edited, so the edit can't be applied without losing changes.
+	ErrSettingsConflict = errors.New("settings file changed by another process while editing")
+
+	// errChangedSinceRead is returned by a write that found the file changed since it was read.
+	errChangedSinceRead = errors.New("file changed since it was read")
+)
+
+// lockRoot takes an advisory lock on name in root by exclusively creating
+// name.lock, waiting up to lockWait for another holder. A lock file older than
+// lockStaleAfter is removed as stale. The returned function releases the lock.
+func lockRoot(root *os.Root, name string) (func(), error) {
+	lockName := name + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := root.OpenFile(lockName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, _ = f.WriteString(strconv.Itoa(os.Getpid()) + "\n") // For humans investigating a stuck lock
+			_ = f.Close()
+			return func() { _ = root.Remove(lockName) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("create lock file: %w", err)
+		}
+
+		if info, statErr := root.Stat(lockName); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			_ = root.Remove(lockName)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w (remove %s if no other cpm is running)", ErrSettingsLocked, lockName)
+		}
+		time.Sleep(lockPoll)
+	}
+}
+
+// editRoot applies edit to the contents of name in root under its lock and
+// writes the result atomically if it changed. A missing file is passed to
+// edit as nil data when create is set and left alone otherwise. If another
+// process changes the file between the read and the write, the edit is
+// retried against the new contents, and ErrSettingsConflict is returned if
+// the file keeps changing.
+func editRoot(root *os.Root, name string, create bool, edit func([]byte) ([]byte, error)) error {
+	unlock, err := lockRoot(root, name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for attempt := range editAttempts {
+		if attempt > 0 {
+			time.Sleep(editRetryDelay)
+		}
+
+		data, readErr := fs.ReadFile(root.FS(), name)
+		exists := readErr == nil
+		switch {
+		case exists:
+		case errors.Is(readErr, fs.ErrNotExist) && create:
+		case errors.Is(readErr, fs.ErrNotExist):
+			return nil
+		default:
+			return fmt.Errorf("read settings: %w", readErr)
+		}
+
+		output, editErr := edit(data)
+		if editErr != nil {
+			return editErr
+		}
+		if exists && bytes.Equal(output, data) {
+			return nil
+		}
+
+		err := atomicWriteRoot(root, name, output, 0o644, func() error {
+			return checkUnchanged(root, name, data, exists)
+		})
+		if !errors.Is(err, errChangedSinceRead) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrSettingsConflict, name)
+}
+
+// checkUnchanged returns errChangedSinceRead unless name still has the given
+// contents, or is still missing if it didn't exist.
+func checkUnchanged(root *os.Root, name string, data []byte, exists bool) error {
+	current, err := fs.ReadFile(root.FS(), name)
+	switch {
+	case err == nil && exists && bytes.Equal(current, data):
+		return nil
+	case errors.Is(err, fs.ErrNotExist) && !exists:
+		return nil
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("re-read settings: %w", err)
+	default:
+		return errChangedSinceRead
+	}
+}
@@ -0,0 +1,150 @@
+package claude
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openTestRoot opens dir as an os.Root closed at the end of the test.
+func openTestRoot(t *testing.T, dir string) *os.Root {
+	t.Helper()
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = root.Close() })
+	return root
+}
+
+func TestLockRoot(t *testing.T) {
+	saved := lockWait
+	lockWait = 50 * time.Millisecond
+	t.Cleanup(func() { lockWait = saved })
+
+	tmp := t.TempDir()
+	root := openTestRoot(t, tmp)
+
+	unlock, err := lockRoot(root, "settings.json")
+	if err != nil {
+		t.Fatalf("lockRoot: %v", err)
+	}
+	if _, err := lockRoot(root, "settings.json"); !errors.Is(err, ErrSettingsLocked) {
+		t.Errorf("second lockRoot err = %v, want ErrSettingsLocked", err)
+	}
+	unlock()
+
+	if _, err := os.Stat(filepath.Join(tmp, "settings.json.lock")); !os.IsNotExist(err) {
+		t.Errorf("lock file should be removed on unlock, stat err = %v", err)
+	}
+	unlock, err = lockRoot(root, "settings.json")
+	if err != nil {
+		t.Fatalf("lockRoot after unlock: %v", err)
+	}
+	unlock()
+}
+
+func TestLockRootRemovesStaleLock(t *testing.T) {
+	tmp := t.TempDir()
+	root := openTestRoot(t, tmp)
+
+	lockPath := filepath.Join(tmp, "settings.json.lock")
+	if err := os.WriteFile(lockPath, []byte("12345\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * lockStaleAfter)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := lockRoot(root, "settings.json")
+	if err != nil {
+		t.Fatalf("lockRoot with stale lock: %v", err)
+	}
+	unlock()
+}
+
+func TestEditRootRetriesWhenFileChanges(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "settings.json")
+	if err := os.WriteFile(path, []byte(`{"a": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := openTestRoot(t, tmp)
+
+	calls := 0
+	err := editRoot(root, "settings.json", false, func(data []byte) ([]byte, error) {
+		calls++
+		if calls == 1 {
+			// Another writer saves between our read and our rename
+			if err := os.WriteFile(path, []byte(`{"a": 1, "b": 2}`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return []byte(strings.Replace(string(data), "}", `, "c": 3}`, 1)), nil
+	})
+	if err != nil {
+		t.Fatalf("editRoot: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("edit called %d times, want 2", calls)
+	}
+
+	got, _ := os.ReadFile(path)
+	if string(got) != `{"a": 1, "b": 2, "c": 3}` {
+		t.Errorf("file = %s, want both edits", got)
+	}
+}
+
+func TestEditRootReportsConflict(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "settings.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := openTestRoot(t, tmp)
+
+	calls := 0
+	err := editRoot(root, "settings.json", false, func(data []byte) ([]byte, error) {
+		calls++
+		external := `{"n": ` + strings.Repeat("1", calls) + `}`
+		if err := os.WriteFile(path, []byte(external), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return []byte(`{"mine": true}`), nil
+	})
+	if !errors.Is(err, ErrSettingsConflict) {
+		t.Fatalf("editRoot err = %v, want ErrSettingsConflict", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(got), `{"n": `) {
+		t.Errorf("file = %s, external edit should survive", got)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, ".tmp.settings.json")); !os.IsNotExist(err) {
+		t.Errorf("temp file should be removed, stat err = %v", err)
+	}
+}
+
+func TestEditRootMissingFile(t *testing.T) {
+	tmp := t.TempDir()
+	root := openTestRoot(t, tmp)
+
+	edit := func([]byte) ([]byte, error) { return []byte(`{"x": true}`), nil }
+	if err := editRoot(root, "settings.json", false, edit); err != nil {
+		t.Fatalf("editRoot without create: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "settings.json")); !os.IsNotExist(err) {
+		t.Error("file should not be created without create")
+	}
+
+	if err := editRoot(root, "settings.json", true, edit); err != nil {
+		t.Fatalf("editRoot with create: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(tmp, "settings.json")); string(got) != `{"x": true}` {
+		t.Errorf("file = %s, want created", got)
+	}
+}
@@ -1,13 +1,21 @@
 package claude
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"io/fs"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/open-cli-collective/cpm/internal/jsonedit"
 )
 
 // PluginManifest represents the plugin.json file in a plugin's .claude-plugin directory.
@@ -17,14 +25,39 @@ type PluginManifest struct {
 	Version     string `json:"version,omitempty"`
 	AuthorName  string // Author name (from string or object.name)
 	AuthorEmail string // Author email (from object.email, if available)
+	Homepage    string
+	Repository  string // Repository URL (from string or object.url)
+	License     string
+	Keywords    []string
+
+	// Custom component locations. Paths are relative to the plugin root and
+	// supplement the default directories rather than replacing them.
+	Commands   []string // Extra command files or directories
+	Agents     []string // Extra agent files or directories
+	Hooks      []string // Extra hook config files
+	MCPServers []string // Extra MCP server config files
+
+	// Inline component configs declared directly in plugin.json.
+	InlineHooks      json.RawMessage
+	InlineMCPServers json.RawMessage
 }
 
-// pluginManifestRaw is used for initial parsing to handle flexible author field.
+// pluginManifestRaw is used for initial parsing to handle flexible fields.
+// Optional metadata is kept raw so a mistyped field is ignored rather than
+// failing the whole manifest.
 type pluginManifestRaw struct {
 	Name        string          `json:"name"`
 	Description string          `json:"description"`
 	Version     string          `json:"version,omitempty"`
 	Author      json.RawMessage `json:"author,omitempty"`
+	Homepage    json.RawMessage `json:"homepage,omitempty"`
+	Repository  json.RawMessage `json:"repository,omitempty"`
+	License     json.RawMessage `json:"license,omitempty"`
+	Keywords    json.RawMessage `json:"keywords,omitempty"`
+	Commands    json.RawMessage `json:"commands,omitempty"`
+	Agents      json.RawMessage `json:"agents,omitempty"`
+	Hooks       json.RawMessage `json:"hooks,omitempty"`
+	MCPServers  json.RawMessage `json:"mcpServers,omitempty"`
 }
 
 // authorObject represents the object form of author field.
@@ -33,13 +66,19 @@ type authorObject struct {
 	Email string `json:"email,omitempty"`
 }
 
+// repositoryObject represents the object form of the repository field.
+type repositoryObject struct {
+	Type string `json:"type,omitempty"`
+	URL  string `json:"url"`
+}
+
 // PluginComponents represents the skills, agents, commands, hooks, and MCPs a plugin provides.
 type PluginComponents struct {
-	Skills   []string
-	Agents   []string
-	Commands []string
-	Hooks    []string
-	MCPs     []string
+	Skills   []Component
+	Agents   []Component
+	Commands []Component
+	Hooks    []Hook
+	MCPs     []MCPServer
 }
 
 // ReadPluginManifest reads the plugin.json manifest from the given install path.
@@ -70,25 +109,138 @@ func ReadPluginManifestFS(fsys fs.FS) (*PluginManifest, error) {
 		Name:        raw.Name,
 		Description: raw.Description,
 		Version:     raw.Version,
+		Homepage:    parseOptionalString(raw.Homepage),
+		License:     parseOptionalString(raw.License),
+		Keywords:    parseKeywords(raw.Keywords),
+	}
+
+	manifest.AuthorName, manifest.AuthorEmail = parseAuthor(raw.Author)
+	manifest.Repository = parseRepository(raw.Repository)
+
+	// Component paths: commands and agents are always paths; hooks and
+	// mcpServers can also be inline objects.
+	manifest.Commands, _ = parseComponentPaths(raw.Commands)
+	manifest.Agents, _ = parseComponentPaths(raw.Agents)
+	manifest.Hooks, manifest.InlineHooks = parseComponentPaths(raw.Hooks)
+	manifest.MCPServers, manifest.InlineMCPServers = parseComponentPaths(raw.MCPServers)
+
+	return manifest, nil
+}
+
+// parseAuthor parses an author field, which can be a string or an object with name and email.
+func parseAuthor(raw json.RawMessage) (name, email string) {
+	if len(raw) == 0 {
+		return "", ""
+	}
+	var authorStr string
+	if err := json.Unmarshal(raw, &authorStr); err == nil {
+		return authorStr, ""
+	}
+	var authorObj authorObject
+	if err := json.Unmarshal(raw, &authorObj); err == nil {
+		return authorObj.Name, authorObj.Email
+	}
+	return "", ""
+}
+
+// parseOptionalString returns an optional string field, or "" if it is
+// missing or not a string.
+func parseOptionalString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return ""
+	}
+	return s
+}
+
+// parseKeywords returns the string items of the keywords array, ignoring
+// items and values of other types.
+func parseKeywords(raw json.RawMessage) []string {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil
+	}
+	var keywords []string
+	for _, item := range items {
+		if k := parseOptionalString(item); k != "" {
+			keywords = append(keywords, k)
+		}
+	}
+	return keywords
+}
+
+// parseRepository parses a repository field, which can be a URL string or an object with a url.
+func parseRepository(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var repoStr string
+	if err := json.Unmarshal(raw, &repoStr); err == nil {
+		return repoStr
+	}
+	var repoObj repositoryObject
+	if err := json.Unmarshal(raw, &repoObj); err == nil {
+		return repoObj.URL
+	}
+	return ""
+}
+
+// parseComponentPaths parses a plugin.json component field that can be a single
+// path, an array of paths, or an inline object. Paths are returned cleaned and
+// relative to the plugin root; an inline object is returned as-is.
+func parseComponentPaths(raw json.RawMessage) ([]string, json.RawMessage) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		if p, ok := cleanComponentPath(single); ok {
+			return []string{p}, nil
+		}
+		return nil, nil
 	}
 
-	// Parse author field (can be string or object)
-	if len(raw.Author) > 0 {
-		// Try string first
-		var authorStr string
-		if err := json.Unmarshal(raw.Author, &authorStr); err == nil {
-			manifest.AuthorName = authorStr
-		} else {
-			// Try object form
-			var authorObj authorObject
-			if err := json.Unmarshal(raw.Author, &authorObj); err == nil {
-				manifest.AuthorName = authorObj.Name
-				manifest.AuthorEmail = authorObj.Email
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		var paths []string
+		for _, item := range list {
+			if p, ok := cleanComponentPath(item); ok {
+				paths = append(paths, p)
 			}
 		}
+		return paths, nil
 	}
 
-	return manifest, nil
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		return nil, raw
+	}
+
+	return nil, nil
+}
+
+// cleanComponentPath normalizes a manifest path ("./custom/cmds/") into a form
+// usable with fs.FS ("custom/cmds"). Paths escaping the plugin root are rejected.
+func cleanComponentPath(p string) (string, bool) {
+	p = path.Clean(strings.TrimPrefix(p, "./"))
+	if p == "." || !fs.ValidPath(p) {
+		return "", false
+	}
+	return p, true
+}
+
+// configPaths returns a component's default config path followed by the
+// config paths the manifest declares, without duplicates. Declared paths are
+// already cleaned, so a manifest listing the default path repeats it exactly.
+func configPaths(defaultPath string, declared []string) []string {
+	paths := []string{defaultPath}
+	for _, p := range declared {
+		if !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	return paths
 }
 
 // ScanPluginComponents scans the plugin directory for skills, agents, commands, hooks, and MCPs.
@@ -103,31 +255,36 @@ func ScanPluginComponents(installPath string) *PluginComponents {
 }
 
 // ScanPluginComponentsFS scans the filesystem for skills, agents, commands, hooks, and MCPs.
+// Default directories are always scanned; custom paths declared in plugin.json are
+// scanned in addition.
 func ScanPluginComponentsFS(fsys fs.FS) *PluginComponents {
 	components := &PluginComponents{}
 
-	// Scan skills/ directory (subdirectories are skill names)
-	components.Skills = listSubdirectoriesFS(fsys, "skills")
+	manifest, err := ReadPluginManifestFS(fsys)
+	if err != nil {
+		manifest = &PluginManifest{}
+	}
+
+	// Scan skills/ directory (subdirectories are skills described by SKILL.md)
+	components.Skills = scanSkillsFS(fsys, "skills")
 
 	// Scan agents/ directory (.md files are agent definitions)
-	components.Agents = listMarkdownFilesFS(fsys, "agents")
-
-	// Scan commands/ directory (.md files are command definitions)
-	components.Commands = listMarkdownFilesFS(fsys, "commands")
+	components.Agents = scanMarkdownComponentsFS(fsys, "agents", true)
+	for _, p := range manifest.Agents {
+		components.Agents = appendComponents(components.Agents, scanMarkdownComponentsFS(fsys, p, true)...)
+	}
 
-	// Scan hooks/ directory (can be subdirectories or .md files)
-	hooks := listSubdirectoriesFS(fsys, "hooks")
-	hooks = append(hooks, listMarkdownFilesFS(fsys, "hooks")...)
-	components.Hooks = hooks
+	// Scan commands/ directory (.md files are command definitions, named by file)
+	components.Commands = scanMarkdownComponentsFS(fsys, "commands", false)
+	for _, p := range manifest.Commands {
+		components.Commands = appendComponents(components.Commands, scanMarkdownComponentsFS(fsys, p, false)...)
+	}
 
-	// Scan mcp-servers/ directory (subdirectories are MCP server names)
-	components.MCPs = listSubdirectoriesFS(fsys, "mcp-servers")
+	// Hooks are defined in hooks/hooks.json, declared config paths, or inline in plugin.json
+	components.Hooks = scanHooksFS(fsys, manifest)
 
-	// Also check for .mcp.json at root (indicates MCP-only plugin)
-	if _, err := fs.Stat(fsys, ".mcp.json"); err == nil && len(components.MCPs) == 0 {
-		// Plugin has an MCP server defined at root level
-		components.MCPs = append(components.MCPs, "mcp-server")
-	}
+	// MCP servers come from .mcp.json, declared config paths, inline plugin.json, and mcp-servers/
+	components.MCPs = scanMCPServersFS(fsys, manifest)
 
 	return components
 }
@@ -184,6 +341,7 @@ func ReadProjectSettings(settingsPath string) (*ProjectSettings, error) {
 }
 
 // readSettingsFromFS reads a settings file from the given filesystem.
+// Comments and trailing commas are allowed, as Claude Code accepts them.
 func readSettingsFromFS(fsys fs.FS, name string) (*ProjectSettings, error) {
 	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
@@ -191,32 +349,75 @@ func readSettingsFromFS(fsys fs.FS, name string) (*ProjectSettings, error) {
 	}
 
 	var settings ProjectSettings
-	if err := json.Unmarshal(data, &settings); err != nil {
+	if err := jsonedit.Unmarshal(data, &settings); err != nil {
 		return nil, err
 	}
 
 	return &settings, nil
 }
 
+// SettingsError describes a settings file that exists but can't be parsed.
+type SettingsError struct {
+	Err   error
+	Path  string
+	Scope Scope
+}
+
+func (e SettingsError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e SettingsError) Unwrap() error {
+	return e.Err
+}
+
 // ScopeState tracks the enabled state of a plugin at a specific scope.
 // The outer map key is the plugin ID, the inner map key is the scope,
 // and the bool value is true=enabled, false=disabled-but-present.
 type ScopeState map[string]map[Scope]bool
 
-// GetAllEnabledPlugins reads all three settings files (user, project, local)
-// and returns a map of plugin ID to scope set with enabled state.
-// Missing settings files are silently ignored.
+// GetAllEnabledPlugins reads the user, project, and local settings files and
+// the managed settings file, and returns a map of plugin ID to scope set with
+// enabled state. Missing settings files are silently ignored; see
+// CheckSettings for unparseable ones.
 func GetAllEnabledPlugins(workingDir string) ScopeState {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "" // Will fail to read user settings, which is handled gracefully
+	result, _ := readAllSettings(workingDir, ConfigDir(), ManagedSettingsPath())
+	return result
+}
+
+// CheckSettings returns an error for each settings file in effect for
+// workingDir that exists but can't be parsed. GetAllEnabledPlugins leaves
+// the plugins of those files out.
+func CheckSettings(workingDir string) []SettingsError {
+	_, errs := readAllSettings(workingDir, ConfigDir(), ManagedSettingsPath())
+	return errs
+}
+
+// ManagedSettingsPath returns the platform's managed (enterprise policy)
+// settings file, which administrators deploy and users can't override.
+func ManagedSettingsPath() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "/Library/Application Support/ClaudeCode/managed-settings.json"
+	case "windows":
+		return `C:\ProgramData\ClaudeCode\managed-settings.json`
+	default:
+		return "/etc/claude-code/managed-settings.json"
 	}
-	return getAllEnabledPlugins(workingDir, homeDir)
 }
 
-// getAllEnabledPlugins is the internal implementation with injectable homeDir for testing.
-func getAllEnabledPlugins(workingDir, homeDir string) ScopeState {
+// getAllEnabledPlugins is the internal implementation with injectable configDir for testing.
+// It doesn't read managed settings.
+func getAllEnabledPlugins(workingDir, configDir string) ScopeState {
+	result, _ := readAllSettings(workingDir, configDir, "")
+	return result
+}
+
+// readAllSettings reads the enabled plugins of every scope, collecting parse errors.
+// managedPath may be empty to skip managed settings.
+func readAllSettings(workingDir, configDir, managedPath string) (ScopeState, []SettingsError) {
 	result := make(ScopeState)
+	var errs []SettingsError
 
 	// Helper to accumulate plugins from a .claude directory root
 	addFromRoot := func(claudeDir string, entries []struct {
@@ -233,8 +434,12 @@ func getAllEnabledPlugins(workingDir, homeDir string) ScopeState {
 		rootFS := root.FS()
 		for _, e := range entries {
 			settings, err := readSettingsFromFS(rootFS, e.file)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // Missing file — skip silently
+			}
 			if err != nil {
-				continue // Missing or unreadable file — skip silently
+				errs = append(errs, SettingsError{Path: filepath.Join(claudeDir, e.file), Scope: e.scope, Err: err})
+				continue
 			}
 			for pluginID, enabled := range settings.EnabledPlugins {
 				if result[pluginID] == nil {
@@ -245,9 +450,9 @@ func getAllEnabledPlugins(workingDir, homeDir string) ScopeState {
 		}
 	}
 
-	// User scope: {homeDir}/.claude/settings.json
-	if homeDir != "" {
-		addFromRoot(filepath.Join(homeDir, ".claude"), []struct {
+	// User scope: {configDir}/settings.json
+	if configDir != "" {
+		addFromRoot(configDir, []struct {
 			file  string
 			scope Scope
 		}{
@@ -264,7 +469,17 @@ func getAllEnabledPlugins(workingDir, homeDir string) ScopeState {
 		{"settings.local.json", ScopeLocal},
 	})
 
-	return result
+	// Managed scope: the platform's policy file
+	if managedPath != "" {
+		addFromRoot(filepath.Dir(managedPath), []struct {
+			file  string
+			scope Scope
+		}{
+			{filepath.Base(managedPath), ScopeManaged},
+		})
+	}
+
+	return result, errs
 }
 
 // ConfigFile represents a JSON configuration file found in a plugin.
@@ -349,24 +564,41 @@ func readAndFormatJSONFS(fsys fs.FS, name string) (string, error) {
 		return "", err
 	}
 
-	formatted, err := json.MarshalIndent(parsed, "", "  ")
+	// Never display environment or header values (MCP server secrets)
+	redactSecretValues(parsed)
+
+	// Shown as text, so <redacted> and & in URLs are left unescaped
+	var formatted strings.Builder
+	enc := json.NewEncoder(&formatted)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(parsed)
 	if err != nil {
 		return "", err
 	}
 
-	return string(formatted), nil
+	return strings.TrimSuffix(formatted.String(), "\n"), nil
 }
 
 // ResolveMarketplaceSourcePath resolves the full path to a plugin in a marketplace.
 // It combines ~/.claude/plugins/marketplaces/<marketplace>/ with the source field.
 func ResolveMarketplaceSourcePath(marketplace string, source any) string {
+	pluginsDir, err := userPluginsDir()
+	if err != nil {
+		return ""
+	}
+	return resolveMarketplaceSourcePath(pluginsDir, marketplace, source)
+}
+
+// resolveMarketplaceSourcePath is the internal implementation with injectable pluginsDir for testing.
+func resolveMarketplaceSourcePath(pluginsDir, marketplace string, source any) string {
 	// Get source as string - source can be string or object
 	var sourcePath string
 	switch s := source.(type) {
 	case string:
 		sourcePath = s
 	default:
-		// If source is not a string (e.g., an object), we can't resolve it
+		// Object sources live outside the marketplace clone
 		return ""
 	}
 
@@ -374,17 +606,88 @@ func ResolveMarketplaceSourcePath(marketplace string, source any) string {
 		return ""
 	}
 
-	// Get user home directory
-	homeDir, err := os.UserHomeDir()
+	// Clean up source path (remove leading ./)
+	sourcePath = strings.TrimPrefix(sourcePath, "./")
+
+	// Construct full path: ~/.claude/plugins/marketplaces/<marketplace>/<source>
+	return filepath.Join(pluginsDir, "marketplaces", marketplace, sourcePath)
+}
+
+// ResolvePluginSourcePath resolves a local directory holding a marketplace
+// plugin's files. Relative sources resolve into the marketplace clone; object
+// sources (github, git, url, npm) resolve to a cached copy under
+// ~/.claude/plugins/cache/<marketplace>/<plugin>/, and directory sources to
+// the directory itself. Returns "" if no local copy exists.
+func ResolvePluginSourcePath(marketplace, plugin string, source any) string {
+	pluginsDir, err := userPluginsDir()
 	if err != nil {
 		return ""
 	}
+	return resolvePluginSourcePath(pluginsDir, marketplace, plugin, source)
+}
 
-	// Clean up source path (remove leading ./)
-	sourcePath = strings.TrimPrefix(sourcePath, "./")
+// resolvePluginSourcePath is the internal implementation with injectable pluginsDir for testing.
+func resolvePluginSourcePath(pluginsDir, marketplace, plugin string, source any) string {
+	if _, ok := source.(string); ok {
+		return resolveMarketplaceSourcePath(pluginsDir, marketplace, source)
+	}
 
-	// Construct full path: ~/.claude/plugins/marketplaces/<marketplace>/<source>
-	return filepath.Join(homeDir, ".claude", "plugins", "marketplaces", marketplace, sourcePath)
+	src := ParsePluginSource(source)
+	if src == nil {
+		return ""
+	}
+	if dir, ok := src.(*DirectorySource); ok {
+		if hasPluginManifest(dir.Path) {
+			return dir.Path
+		}
+		return ""
+	}
+	return cachedPluginPath(filepath.Join(pluginsDir, "cache", marketplace, plugin))
+}
+
+// cachedPluginPath returns the plugin directory within a cache entry. The entry
+// is either the plugin root itself or holds one subdirectory per cached version,
+// in which case the most recently modified one is used.
+func cachedPluginPath(dir string) string {
+	if hasPluginManifest(dir) {
+		return dir
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var newest string
+	var newestTime time.Time
+	for _, entry := range entries {
+		candidate := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() || !hasPluginManifest(candidate) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest, newestTime = candidate, info.ModTime()
+		}
+	}
+	return newest
+}
+
+// hasPluginManifest reports whether dir contains .claude-plugin/plugin.json.
+func hasPluginManifest(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".claude-plugin", "plugin.json"))
+	return err == nil
+}
+
+// userPluginsDir returns the plugins directory of the Claude config directory.
+func userPluginsDir() (string, error) {
+	configDir := ConfigDir()
+	if configDir == "" {
+		return "", errNoConfigDir
+	}
+	return filepath.Join(configDir, "plugins"), nil
 }
 
 // MarketplaceNameFromPluginID extracts the marketplace name from a plugin ID.
@@ -409,13 +712,49 @@ func SettingsPathForScope(workingDir string, scope Scope) string {
 	}
 }
 
+// UserSettingsPath returns settings.json in the Claude config directory, which
+// holds the user scope's enabledPlugins. Returns empty string if the config
+// directory is unknown.
+func UserSettingsPath() string {
+	configDir := ConfigDir()
+	if configDir == "" {
+		return ""
+	}
+	return filepath.Join(configDir, "settings.json")
+}
+
+// WatchedFiles returns the files whose changes affect the plugin list for
+// workingDir: the settings file of every scope, known_marketplaces.json, and
+// installed_plugins.json. Files need not exist.
+func WatchedFiles(workingDir string) []string {
+	return watchedFiles(workingDir, ConfigDir(), ManagedSettingsPath())
+}
+
+// watchedFiles is the internal implementation with injectable paths for testing.
+func watchedFiles(workingDir, configDir, managedPath string) []string {
+	files := []string{
+		SettingsPathForScope(workingDir, ScopeProject),
+		SettingsPathForScope(workingDir, ScopeLocal),
+		managedPath,
+	}
+	if configDir != "" {
+		pluginsDir := filepath.Join(configDir, "plugins")
+		files = append(files,
+			filepath.Join(configDir, "settings.json"),
+			filepath.Join(pluginsDir, "known_marketplaces.json"),
+			filepath.Join(pluginsDir, "installed_plugins.json"),
+		)
+	}
+	return files
+}
+
 // ReadKnownMarketplaces reads ~/.claude/plugins/known_marketplaces.json.
 func ReadKnownMarketplaces() (map[string]KnownMarketplace, error) {
-	homeDir, err := os.UserHomeDir()
+	pluginsDir, err := userPluginsDir()
 	if err != nil {
 		return nil, err
 	}
-	return ReadKnownMarketplacesFrom(filepath.Join(homeDir, ".claude", "plugins"))
+	return ReadKnownMarketplacesFrom(pluginsDir)
 }
 
 // ReadKnownMarketplacesFrom reads known_marketplaces.json from the given directory.
@@ -438,8 +777,46 @@ func ReadKnownMarketplacesFrom(dir string) (map[string]KnownMarketplace, error)
 	return result, nil
 }
 
+// ErrUnknownMarketplace is returned when a marketplace isn't in known_marketplaces.json.
+var ErrUnknownMarketplace = errors.New("unknown marketplace")
+
+// SetMarketplaceAutoUpdate turns autoUpdate on or off for a marketplace in
+// ~/.claude/plugins/known_marketplaces.json.
+func SetMarketplaceAutoUpdate(name string, on bool) error {
+	pluginsDir, err := userPluginsDir()
+	if err != nil {
+		return err
+	}
+	return SetMarketplaceAutoUpdateIn(pluginsDir, name, on)
+}
+
+// SetMarketplaceAutoUpdateIn turns autoUpdate on or off for a marketplace in
+// dir's known_marketplaces.json. The file is edited in place under its lock
+// and written atomically, so every other field is kept as it was.
+func SetMarketplaceAutoUpdateIn(dir, name string, on bool) error {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = root.Close() }()
+
+	// create is set so a missing file reaches the edit and reports the name as unknown
+	return editRoot(root, "known_marketplaces.json", true, func(data []byte) ([]byte, error) {
+		known, parseErr := parseRawSettings(data)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMarketplace, name)
+		}
+		return jsonedit.Set(data, []string{name, "autoUpdate"}, on)
+	})
+}
+
 // atomicWriteRoot writes data to a file atomically using Root.Rename.
-func atomicWriteRoot(root *os.Root, name string, data []byte, perm os.FileMode) error {
+// check, if non-nil, runs just before the rename; an error from it abandons
+// the write and is returned unwrapped.
+func atomicWriteRoot(root *os.Root, name string, data []byte, perm os.FileMode, check func() error) error {
 	tmpName := ".tmp." + name
 
 	f, err := root.Create(tmpName)
@@ -464,6 +841,13 @@ func atomicWriteRoot(root *os.Root, name string, data []byte, perm os.FileMode)
 		return fmt.Errorf("close temp file: %w", err)
 	}
 
+	if check != nil {
+		if err := check(); err != nil {
+			_ = root.Remove(tmpName)
+			return err
+		}
+	}
+
 	if err := root.Rename(tmpName, name); err != nil {
 		_ = root.Remove(tmpName)
 		return fmt.Errorf("rename temp file: %w", err)
@@ -475,8 +859,9 @@ func atomicWriteRoot(root *os.Root, name string, data []byte, perm os.FileMode)
 // SyncExtraMarketplaces reconciles extraKnownMarketplaces in a settings file
 // based on the enabledPlugins that are currently present.
 // It adds entries for marketplaces that have plugins but no entry,
-// and removes entries for marketplaces with no remaining plugins.
-func SyncExtraMarketplaces(settingsPath string, knownMarketplaces map[string]KnownMarketplace) error {
+// and removes entries for marketplaces with no remaining plugins unless they
+// are listed in keep, such as entries the user added by hand.
+func SyncExtraMarketplaces(settingsPath string, knownMarketplaces map[string]KnownMarketplace, keep []string) error {
 	dir := filepath.Dir(settingsPath)
 	file := filepath.Base(settingsPath)
 
@@ -487,52 +872,99 @@ func SyncExtraMarketplaces(settingsPath string, knownMarketplaces map[string]Kno
 	}
 	defer func() { _ = root.Close() }()
 
-	return syncExtraMarketplacesRoot(root, file, knownMarketplaces)
+	return syncExtraMarketplacesRoot(root, file, knownMarketplaces, keep)
 }
 
 // syncExtraMarketplacesRoot is the internal implementation operating on an os.Root.
-func syncExtraMarketplacesRoot(root *os.Root, name string, knownMarketplaces map[string]KnownMarketplace) error {
-	rawSettings, readErr := readRawSettings(root, name)
-	if readErr != nil {
-		return readErr
+// Only the extraKnownMarketplaces entries that change are rewritten; the rest
+// of the file keeps its key order and formatting.
+func syncExtraMarketplacesRoot(root *os.Root, name string, knownMarketplaces map[string]KnownMarketplace, keep []string) error {
+	return editRoot(root, name, false, func(data []byte) ([]byte, error) {
+		return syncExtra(data, knownMarketplaces, keep)
+	})
+}
+
+// syncExtra returns settings file contents with extraKnownMarketplaces
+// reconciled against enabledPlugins, leaving the entries in keep. Unchanged
+// contents are returned as is.
+func syncExtra(data []byte, knownMarketplaces map[string]KnownMarketplace, keep []string) ([]byte, error) {
+	rawSettings, parseErr := parseRawSettings(data)
+	if parseErr != nil {
+		return nil, parseErr
 	}
 
 	neededMarketplaces := extractNeededMarketplaces(rawSettings)
 	currentExtra := parseCurrentExtra(rawSettings)
-	desiredExtra := computeDesiredExtra(neededMarketplaces, currentExtra, knownMarketplaces)
+	desiredExtra := computeDesiredExtra(neededMarketplaces, currentExtra, knownMarketplaces, keep)
 
 	if mapsEqual(currentExtra, desiredExtra) {
-		return nil
+		return data, nil
 	}
 
-	applyExtraToSettings(rawSettings, desiredExtra)
+	output, editErr := applyExtraToSettings(data, currentExtra, desiredExtra)
+	if editErr != nil {
+		return nil, fmt.Errorf("update settings: %w", editErr)
+	}
+	return output, nil
+}
 
-	output, marshalErr := json.MarshalIndent(rawSettings, "", "  ")
-	if marshalErr != nil {
-		return fmt.Errorf("marshal settings: %w", marshalErr)
+// parseRawSettings parses settings file contents into a raw JSON map.
+// Empty contents yield an empty map.
+func parseRawSettings(data []byte) (map[string]json.RawMessage, error) {
+	raw := make(map[string]json.RawMessage)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return raw, nil
+	}
+	if err := jsonedit.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse settings: %w", err)
 	}
-	output = append(output, '\n')
+	return raw, nil
+}
 
-	return atomicWriteRoot(root, name, output, 0o644)
+// SetPluginEnabled sets a plugin's enabledPlugins entry in a settings file,
+// creating the file and its directory if needed. Only that entry is rewritten.
+func SetPluginEnabled(settingsPath, pluginID string, enabled bool) error {
+	return editSettings(settingsPath, true, func(data []byte) ([]byte, error) {
+		return jsonedit.Set(data, []string{"enabledPlugins", pluginID}, enabled)
+	})
 }
 
-// readRawSettings reads a settings file into a raw JSON map, or returns an empty map if not found.
-func readRawSettings(root *os.Root, name string) (map[string]json.RawMessage, error) {
-	f, err := root.Open(name)
-	if err != nil {
-		return make(map[string]json.RawMessage), nil
-	}
-	defer func() { _ = f.Close() }()
+// RemovePluginEnabled removes a plugin's enabledPlugins entry from a settings file.
+// A missing file or entry is not an error.
+func RemovePluginEnabled(settingsPath, pluginID string) error {
+	return editSettings(settingsPath, false, func(data []byte) ([]byte, error) {
+		return jsonedit.Delete(data, []string{"enabledPlugins", pluginID})
+	})
+}
 
-	data, err := io.ReadAll(f)
-	if err != nil {
-		return nil, fmt.Errorf("read settings: %w", err)
+// editSettings applies edit to the contents of a settings file and writes the
+// result atomically if it changed, under the file's lock. With create set, a
+// missing file and its directory are created; otherwise a missing file is left alone.
+func editSettings(settingsPath string, create bool, edit func([]byte) ([]byte, error)) error {
+	dir := filepath.Dir(settingsPath)
+	name := filepath.Base(settingsPath)
+
+	if create {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("create settings directory: %w", err)
+		}
 	}
-	var raw map[string]json.RawMessage
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return nil, fmt.Errorf("parse settings: %w", err)
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		if !create && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
 	}
-	return raw, nil
+	defer func() { _ = root.Close() }()
+
+	return editRoot(root, name, create, func(data []byte) ([]byte, error) {
+		output, editErr := edit(data)
+		if editErr != nil {
+			return nil, fmt.Errorf("update settings: %w", editErr)
+		}
+		return output, nil
+	})
 }
 
 // extractNeededMarketplaces parses enabledPlugins to find which marketplaces are in use.
@@ -564,7 +996,8 @@ func parseCurrentExtra(rawSettings map[string]json.RawMessage) map[string]Market
 }
 
 // computeDesiredExtra computes the desired extraKnownMarketplaces state.
-func computeDesiredExtra(needed map[string]bool, current map[string]MarketplaceEntry, known map[string]KnownMarketplace) map[string]MarketplaceEntry {
+// Current entries named in keep stay even when no plugin needs them.
+func computeDesiredExtra(needed map[string]bool, current map[string]MarketplaceEntry, known map[string]KnownMarketplace, keep []string) map[string]MarketplaceEntry {
 	desired := make(map[string]MarketplaceEntry)
 	for mp := range needed {
 		if existing, ok := current[mp]; ok {
@@ -573,19 +1006,39 @@ func computeDesiredExtra(needed map[string]bool, current map[string]MarketplaceE
 			desired[mp] = MarketplaceEntry{Source: km.Source}
 		}
 	}
+	for _, mp := range keep {
+		if existing, ok := current[mp]; ok {
+			desired[mp] = existing
+		}
+	}
 	return desired
 }
 
-// applyExtraToSettings updates the rawSettings map with the desired extra marketplaces.
-func applyExtraToSettings(rawSettings map[string]json.RawMessage, desired map[string]MarketplaceEntry) {
-	if len(desired) > 0 {
-		extraJSON, err := json.Marshal(desired)
-		if err == nil {
-			rawSettings["extraKnownMarketplaces"] = extraJSON
+// applyExtraToSettings edits extraKnownMarketplaces in the settings data from
+// current to desired. Entries that stay are left as written; removed entries are
+// deleted and new ones appended in name order. The key is dropped when empty.
+func applyExtraToSettings(data []byte, current, desired map[string]MarketplaceEntry) ([]byte, error) {
+	const key = "extraKnownMarketplaces"
+	if len(desired) == 0 {
+		return jsonedit.Delete(data, []string{key})
+	}
+
+	var err error
+	for _, mp := range slices.Sorted(maps.Keys(current)) {
+		if _, keep := desired[mp]; !keep {
+			if data, err = jsonedit.Delete(data, []string{key, mp}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, mp := range slices.Sorted(maps.Keys(desired)) {
+		if _, exists := current[mp]; !exists {
+			if data, err = jsonedit.Set(data, []string{key, mp}, desired[mp]); err != nil {
+				return nil, err
+			}
 		}
-	} else {
-		delete(rawSettings, "extraKnownMarketplaces")
 	}
+	return data, nil
 }
 
 // mapsEqual compares two MarketplaceEntry maps for equality.
@@ -2,10 +2,15 @@ package claude
 
 import (
 	"encoding/json"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 )
 
 func TestReadPluginManifestFS(t *testing.T) {
@@ -53,6 +58,98 @@ func TestReadPluginManifestFS(t *testing.T) {
 		}
 	})
 
+	t.Run("full schema", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": &fstest.MapFile{
+				Data: []byte(`{
+					"name": "full",
+					"homepage": "https://example.com/full",
+					"repository": {"type": "git", "url": "https://github.com/acme/full"},
+					"license": "MIT",
+					"keywords": ["review", "git"],
+					"commands": ["./custom/commands/", "./extra/deploy.md"],
+					"agents": "./custom/agents",
+					"hooks": "./config/hooks.json",
+					"mcpServers": {"db": {"command": "db-server"}}
+				}`),
+			},
+		}
+
+		manifest, err := ReadPluginManifestFS(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if manifest.Homepage != "https://example.com/full" {
+			t.Errorf("Homepage = %q", manifest.Homepage)
+		}
+		if manifest.Repository != "https://github.com/acme/full" {
+			t.Errorf("Repository = %q", manifest.Repository)
+		}
+		if manifest.License != "MIT" {
+			t.Errorf("License = %q, want MIT", manifest.License)
+		}
+		if len(manifest.Keywords) != 2 || manifest.Keywords[0] != "review" {
+			t.Errorf("Keywords = %v, want [review git]", manifest.Keywords)
+		}
+		if len(manifest.Commands) != 2 || manifest.Commands[0] != "custom/commands" || manifest.Commands[1] != "extra/deploy.md" {
+			t.Errorf("Commands = %v, want [custom/commands extra/deploy.md]", manifest.Commands)
+		}
+		if len(manifest.Agents) != 1 || manifest.Agents[0] != "custom/agents" {
+			t.Errorf("Agents = %v, want [custom/agents]", manifest.Agents)
+		}
+		if len(manifest.Hooks) != 1 || manifest.Hooks[0] != "config/hooks.json" {
+			t.Errorf("Hooks = %v, want [config/hooks.json]", manifest.Hooks)
+		}
+		if len(manifest.MCPServers) != 0 || len(manifest.InlineMCPServers) == 0 {
+			t.Errorf("expected inline mcpServers, got paths %v", manifest.MCPServers)
+		}
+	})
+
+	t.Run("mistyped optional fields", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": &fstest.MapFile{
+				Data: []byte(`{
+					"name": "loose",
+					"description": "Still shown",
+					"author": "Alice",
+					"homepage": 42,
+					"license": {"spdx": "MIT"},
+					"keywords": "a, b"
+				}`),
+			},
+		}
+
+		manifest, err := ReadPluginManifestFS(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if manifest.Description != "Still shown" || manifest.AuthorName != "Alice" {
+			t.Errorf("Description = %q, AuthorName = %q", manifest.Description, manifest.AuthorName)
+		}
+		if manifest.Homepage != "" || manifest.License != "" || manifest.Keywords != nil {
+			t.Errorf("Homepage = %q, License = %q, Keywords = %v, want all empty", manifest.Homepage, manifest.License, manifest.Keywords)
+		}
+	})
+
+	t.Run("string repository and escaping paths", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": &fstest.MapFile{
+				Data: []byte(`{"name":"p","repository":"https://github.com/acme/p","commands":["../outside","./ok"]}`),
+			},
+		}
+
+		manifest, err := ReadPluginManifestFS(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if manifest.Repository != "https://github.com/acme/p" {
+			t.Errorf("Repository = %q", manifest.Repository)
+		}
+		if len(manifest.Commands) != 1 || manifest.Commands[0] != "ok" {
+			t.Errorf("Commands = %v, want [ok]", manifest.Commands)
+		}
+	})
+
 	t.Run("missing manifest", func(t *testing.T) {
 		fsys := fstest.MapFS{}
 
@@ -82,37 +179,95 @@ func TestScanPluginComponentsFS(t *testing.T) {
 			"skills/my-skill/SKILL.md":      &fstest.MapFile{},
 			"agents/helper.md":              &fstest.MapFile{},
 			"commands/run.md":               &fstest.MapFile{},
-			"hooks/pre-commit/hook.sh":      &fstest.MapFile{},
-			"hooks/post-build.md":           &fstest.MapFile{},
 			"mcp-servers/my-server/main.go": &fstest.MapFile{},
+			"hooks/hooks.json": &fstest.MapFile{
+				Data: []byte(`{"hooks":{
+					"PreToolUse":[{"matcher":"Bash","hooks":[{"type":"command","command":"${CLAUDE_PLUGIN_ROOT}/scripts/check.sh"}]}],
+					"SessionStart":[{"hooks":[{"type":"command","command":"echo hi"}]}]
+				}}`),
+			},
 		}
 
 		components := ScanPluginComponentsFS(fsys)
-		if len(components.Skills) != 1 || components.Skills[0] != "my-skill" {
+		if len(components.Skills) != 1 || components.Skills[0].Name != "my-skill" {
 			t.Errorf("Skills = %v, want [my-skill]", components.Skills)
 		}
-		if len(components.Agents) != 1 || components.Agents[0] != "helper" {
+		if len(components.Agents) != 1 || components.Agents[0].Name != "helper" {
 			t.Errorf("Agents = %v, want [helper]", components.Agents)
 		}
-		if len(components.Commands) != 1 || components.Commands[0] != "run" {
+		if len(components.Commands) != 1 || components.Commands[0].Name != "run" {
 			t.Errorf("Commands = %v, want [run]", components.Commands)
 		}
 		if len(components.Hooks) != 2 {
-			t.Errorf("Hooks length = %d, want 2", len(components.Hooks))
+			t.Fatalf("Hooks length = %d, want 2", len(components.Hooks))
+		}
+		if components.Hooks[0].Event != "SessionStart" || components.Hooks[1].Event != "PreToolUse" {
+			t.Errorf("Hooks not in lifecycle order: %+v", components.Hooks)
 		}
-		if len(components.MCPs) != 1 || components.MCPs[0] != "my-server" {
+		if len(components.MCPs) != 1 || components.MCPs[0].Name != "my-server" {
 			t.Errorf("MCPs = %v, want [my-server]", components.MCPs)
 		}
 	})
 
-	t.Run("mcp.json fallback", func(t *testing.T) {
+	t.Run("custom manifest paths", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": &fstest.MapFile{
+				Data: []byte(`{
+					"name": "custom",
+					"commands": ["./custom/commands/", "./extra/deploy.md"],
+					"agents": "./custom/agents",
+					"hooks": "./config/hooks.json",
+					"mcpServers": "./config/servers.json"
+				}`),
+			},
+			"commands/run.md":          &fstest.MapFile{},
+			"custom/commands/lint.md":  &fstest.MapFile{},
+			"extra/deploy.md":          &fstest.MapFile{},
+			"custom/agents/planner.md": &fstest.MapFile{},
+			"config/hooks.json": &fstest.MapFile{
+				Data: []byte(`{"hooks":{"Stop":[{"hooks":[{"type":"command","command":"notify"}]}]}}`),
+			},
+			"config/servers.json": &fstest.MapFile{
+				Data: []byte(`{"mcpServers":{"github":{"command":"gh-mcp"},"db":{"command":"db"}}}`),
+			},
+		}
+
+		components := ScanPluginComponentsFS(fsys)
+		if len(components.Commands) != 3 {
+			t.Errorf("Commands = %v, want [run lint deploy]", components.Commands)
+		}
+		if len(components.Agents) != 1 || components.Agents[0].Name != "planner" {
+			t.Errorf("Agents = %v, want [planner]", components.Agents)
+		}
+		if len(components.Hooks) != 1 || components.Hooks[0].Source != "config/hooks.json" {
+			t.Errorf("Hooks = %+v, want one hook from config/hooks.json", components.Hooks)
+		}
+		if len(components.MCPs) != 2 || components.MCPs[0].Name != "db" || components.MCPs[1].Name != "github" {
+			t.Errorf("MCPs = %v, want [db github]", components.MCPs)
+		}
+	})
+
+	t.Run("inline mcpServers", func(t *testing.T) {
 		fsys := fstest.MapFS{
-			".mcp.json": &fstest.MapFile{Data: []byte(`{}`)},
+			".claude-plugin/plugin.json": &fstest.MapFile{
+				Data: []byte(`{"name":"inline","mcpServers":{"search":{"command":"search-mcp"}}}`),
+			},
 		}
 
 		components := ScanPluginComponentsFS(fsys)
-		if len(components.MCPs) != 1 || components.MCPs[0] != "mcp-server" {
-			t.Errorf("MCPs = %v, want [mcp-server]", components.MCPs)
+		if len(components.MCPs) != 1 || components.MCPs[0].Name != "search" {
+			t.Errorf("MCPs = %v, want [search]", components.MCPs)
+		}
+	})
+
+	t.Run("mcp.json servers", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".mcp.json": &fstest.MapFile{Data: []byte(`{"mcpServers":{"linear":{"type":"sse","url":"https://mcp.linear.app/sse"}}}`)},
+		}
+
+		components := ScanPluginComponentsFS(fsys)
+		if len(components.MCPs) != 1 || components.MCPs[0].Name != "linear" {
+			t.Errorf("MCPs = %+v, want [linear]", components.MCPs)
 		}
 	})
 
@@ -208,6 +363,99 @@ func TestReadSettingsFromFS(t *testing.T) {
 			t.Error("expected error for missing file, got nil")
 		}
 	})
+
+	t.Run("comments and trailing commas", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"settings.json": &fstest.MapFile{
+				Data: []byte("{\n  // team plugins\n  \"enabledPlugins\": {\n    \"plugin-a@mp\": true, /* CI */\n  },\n}\n"),
+			},
+		}
+
+		settings, err := readSettingsFromFS(fsys, "settings.json")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !settings.EnabledPlugins["plugin-a@mp"] {
+			t.Error("plugin-a@mp should be enabled")
+		}
+	})
+}
+
+func TestReadAllSettingsReportsParseErrors(t *testing.T) {
+	tmpHome := setupTempDir(t, "home-*")
+	tmpWork := setupTempDir(t, "work-*")
+	setupClaudeDir(t, tmpHome, `{"enabledPlugins": {"user-plugin@mp": true}}`)
+	setupClaudeDir(t, tmpWork, `{"enabledPlugins": {"project-plugin@mp": true}}`)
+	localPath := filepath.Join(tmpWork, ".claude", "settings.local.json")
+	if err := os.WriteFile(localPath, []byte("{\n  \"enabledPlugins\": {\n    \"local-plugin@mp\": true\n  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, errs := readAllSettings(tmpWork, filepath.Join(tmpHome, ".claude"), "")
+
+	if len(errs) != 1 {
+		t.Fatalf("len(errs) = %d, want 1: %v", len(errs), errs)
+	}
+	if errs[0].Path != localPath || errs[0].Scope != ScopeLocal {
+		t.Errorf("error = %+v, want local settings", errs[0])
+	}
+	if !strings.Contains(errs[0].Error(), "settings.local.json: line") {
+		t.Errorf("Error() = %q, want path and line", errs[0].Error())
+	}
+	if !state.Enabled("user-plugin@mp") || !state.Enabled("project-plugin@mp") {
+		t.Error("readable scopes should still be loaded")
+	}
+}
+
+func TestReadAllSettingsManaged(t *testing.T) {
+	tmpHome := setupTempDir(t, "home-*")
+	tmpWork := setupTempDir(t, "work-*")
+	setupClaudeDir(t, tmpWork, `{"enabledPlugins": {"blocked@mp": true}}`)
+	managedPath := filepath.Join(setupTempDir(t, "managed-*"), "managed-settings.json")
+	managed := `{"enabledPlugins": {"required@mp": true, "blocked@mp": false}}`
+	if err := os.WriteFile(managedPath, []byte(managed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, errs := readAllSettings(tmpWork, filepath.Join(tmpHome, ".claude"), managedPath)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if enabled, ok := state["required@mp"][ScopeManaged]; !ok || !enabled {
+		t.Errorf("required@mp scopes = %v, want managed enabled", state["required@mp"])
+	}
+	if state.Enabled("blocked@mp") {
+		t.Error("managed policy should override project settings")
+	}
+	if !state["blocked@mp"][ScopeProject] {
+		t.Error("project scope entry should still be reported")
+	}
+}
+
+func TestSyncExtraMarketplacesKeepsComments(t *testing.T) {
+	tmp := setupTempDir(t, "sync-mp-*")
+	settings := "{\n  // Shared with the team\n  \"enabledPlugins\": {\n    \"p@mp\": true, // linter\n  },\n}\n"
+	settingsPath := setupClaudeDir(t, tmp, settings)
+
+	known := map[string]KnownMarketplace{
+		"mp": {Source: &GitHubSource{Repo: "owner/repo"}},
+	}
+	if err := SyncExtraMarketplaces(settingsPath, known, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, comment := range []string{"// Shared with the team", "// linter"} {
+		if !strings.Contains(string(got), comment) {
+			t.Errorf("comment %q was lost:\n%s", comment, got)
+		}
+	}
+	if !strings.Contains(string(got), `"extraKnownMarketplaces"`) {
+		t.Errorf("extraKnownMarketplaces not added:\n%s", got)
+	}
 }
 
 // TestGetAllEnabledPluginsUserScope verifies AC2.1: user-scoped plugins
@@ -247,7 +495,7 @@ func TestGetAllEnabledPluginsUserScope(t *testing.T) {
 	}
 
 	// Call getAllEnabledPlugins with temp dirs
-	result := getAllEnabledPlugins(tmpWork, tmpHome)
+	result := getAllEnabledPlugins(tmpWork, filepath.Join(tmpHome, ".claude"))
 
 	// Verify plugin-a@mp is present with ScopeUser
 	if _, ok := result["plugin-a@mp"]; !ok {
@@ -316,7 +564,7 @@ func TestGetAllEnabledPluginsProjectAndLocalScope(t *testing.T) {
 	}
 
 	// Call getAllEnabledPlugins
-	result := getAllEnabledPlugins(tmpWork, tmpHome)
+	result := getAllEnabledPlugins(tmpWork, filepath.Join(tmpHome, ".claude"))
 
 	// Verify plugin-b@mp has ScopeProject
 	if _, ok := result["plugin-b@mp"]; !ok {
@@ -359,7 +607,7 @@ func TestGetAllEnabledPluginsMissingFiles(t *testing.T) {
 	defer os.RemoveAll(tmpWork)
 
 	// Call getAllEnabledPlugins with no settings files
-	result := getAllEnabledPlugins(tmpWork, tmpHome)
+	result := getAllEnabledPlugins(tmpWork, filepath.Join(tmpHome, ".claude"))
 
 	// Verify empty map returned, no error/panic
 	if result == nil {
@@ -428,7 +676,7 @@ func TestGetAllEnabledPluginsMultipleScopes(t *testing.T) {
 	}
 
 	// Call getAllEnabledPlugins
-	result := getAllEnabledPlugins(tmpWork, tmpHome)
+	result := getAllEnabledPlugins(tmpWork, filepath.Join(tmpHome, ".claude"))
 
 	// Verify plugin-x@mp appears in both ScopeUser and ScopeLocal
 	if _, ok := result["plugin-x@mp"]; !ok {
@@ -486,7 +734,7 @@ func TestGetAllEnabledPluginsDisabledButPresent(t *testing.T) {
 	}
 
 	// Call getAllEnabledPlugins
-	result := getAllEnabledPlugins(tmpWork, tmpHome)
+	result := getAllEnabledPlugins(tmpWork, filepath.Join(tmpHome, ".claude"))
 
 	// Verify plugin-d@mp is present even though disabled
 	if _, ok := result["plugin-d@mp"]; !ok {
@@ -537,6 +785,26 @@ func TestSettingsPathForScope(t *testing.T) {
 	}
 }
 
+func TestWatchedFiles(t *testing.T) {
+	got := watchedFiles("/work", "/home/u/.claude", "/etc/claude-code/managed-settings.json")
+	want := []string{
+		"/work/.claude/settings.json",
+		"/work/.claude/settings.local.json",
+		"/etc/claude-code/managed-settings.json",
+		"/home/u/.claude/settings.json",
+		"/home/u/.claude/plugins/known_marketplaces.json",
+		"/home/u/.claude/plugins/installed_plugins.json",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("watchedFiles() = %q, want %q", got, want)
+	}
+
+	// Without a config directory only the project and managed files are watched
+	if got := watchedFiles("/work", "", "/managed.json"); len(got) != 3 {
+		t.Errorf("watchedFiles() without config dir = %q, want 3 files", got)
+	}
+}
+
 // setupTempDir creates a temp directory and returns its path with a cleanup function.
 func setupTempDir(t *testing.T, pattern string) string {
 	t.Helper()
@@ -610,6 +878,58 @@ func TestReadKnownMarketplaces(t *testing.T) {
 	}
 }
 
+func TestSetMarketplaceAutoUpdateIn(t *testing.T) {
+	tmp := setupTempDir(t, "auto-update-*")
+	path := filepath.Join(tmp, "known_marketplaces.json")
+	data := `{
+  "mp": {
+    "source": {"source": "github", "repo": "owner/mp"},
+    "installLocation": "/test/mp",
+    "lastUpdated": "2026-01-01T00:00:00Z",
+    "futureField": 1
+  },
+  "other": {"source": {"source": "directory", "path": "/src"}, "autoUpdate": true}
+}
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetMarketplaceAutoUpdateIn(tmp, "mp", true); err != nil {
+		t.Fatalf("SetMarketplaceAutoUpdateIn: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"futureField": 1`, `"installLocation": "/test/mp"`, `"autoUpdate": true`} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("file missing %s:\n%s", want, got)
+		}
+	}
+
+	if err := SetMarketplaceAutoUpdateIn(tmp, "other", false); err != nil {
+		t.Fatal(err)
+	}
+	known, err := ReadKnownMarketplacesFrom(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !known["mp"].AutoUpdate || known["other"].AutoUpdate {
+		t.Errorf("AutoUpdate = %v, %v, want true, false", known["mp"].AutoUpdate, known["other"].AutoUpdate)
+	}
+	if _, err := os.Stat(path + ".lock"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+
+	if err := SetMarketplaceAutoUpdateIn(tmp, "missing", true); !errors.Is(err, ErrUnknownMarketplace) {
+		t.Errorf("unknown marketplace error = %v, want ErrUnknownMarketplace", err)
+	}
+	if err := SetMarketplaceAutoUpdateIn(t.TempDir(), "mp", true); !errors.Is(err, ErrUnknownMarketplace) {
+		t.Errorf("missing file error = %v, want ErrUnknownMarketplace", err)
+	}
+}
+
 func TestSyncExtraMarketplacesAddsEntry(t *testing.T) {
 	tmp := setupTempDir(t, "sync-mp-*")
 	settingsPath := setupClaudeDir(t, tmp, `{"enabledPlugins":{"my-plugin@ed3d-plugins":true}}`)
@@ -618,7 +938,7 @@ func TestSyncExtraMarketplacesAddsEntry(t *testing.T) {
 		"ed3d-plugins": {Source: GitHubSource{Repo: "ed3dai/ed3d-plugins"}},
 	}
 
-	syncErr := SyncExtraMarketplaces(settingsPath, known)
+	syncErr := SyncExtraMarketplaces(settingsPath, known, nil)
 	if syncErr != nil {
 		t.Fatalf("SyncExtraMarketplaces: %v", syncErr)
 	}
@@ -658,7 +978,7 @@ func TestSyncExtraMarketplacesRemovesEntry(t *testing.T) {
 		"ed3d-plugins": {Source: GitHubSource{Repo: "ed3dai/ed3d-plugins"}},
 	}
 
-	syncErr := SyncExtraMarketplaces(settingsPath, known)
+	syncErr := SyncExtraMarketplaces(settingsPath, known, nil)
 	if syncErr != nil {
 		t.Fatalf("SyncExtraMarketplaces: %v", syncErr)
 	}
@@ -669,6 +989,25 @@ func TestSyncExtraMarketplacesRemovesEntry(t *testing.T) {
 	}
 }
 
+func TestSyncExtraMarketplacesKeepsListedEntry(t *testing.T) {
+	tmp := setupTempDir(t, "sync-mp-*")
+	settingsPath := setupClaudeDir(t, tmp,
+		`{"enabledPlugins":{},"extraKnownMarketplaces":{"kept":{"source":{"source":"github","repo":"owner/kept"}},"stale":{"source":{"source":"github","repo":"owner/stale"}}}}`)
+
+	syncErr := SyncExtraMarketplaces(settingsPath, map[string]KnownMarketplace{}, []string{"kept", "missing"})
+	if syncErr != nil {
+		t.Fatalf("SyncExtraMarketplaces: %v", syncErr)
+	}
+
+	extra, err := ReadExtraMarketplaces(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := extra["kept"]; !ok || len(extra) != 1 {
+		t.Errorf("extraKnownMarketplaces = %v, want only kept", extra)
+	}
+}
+
 func TestSyncExtraMarketplacesPreservesUnknownFields(t *testing.T) {
 	tmp := setupTempDir(t, "sync-mp-*")
 	settingsPath := setupClaudeDir(t, tmp,
@@ -678,7 +1017,7 @@ func TestSyncExtraMarketplacesPreservesUnknownFields(t *testing.T) {
 		"mp": {Source: GitHubSource{Repo: "owner/repo"}},
 	}
 
-	syncErr := SyncExtraMarketplaces(settingsPath, known)
+	syncErr := SyncExtraMarketplaces(settingsPath, known, nil)
 	if syncErr != nil {
 		t.Fatal(syncErr)
 	}
@@ -706,7 +1045,7 @@ func TestSyncExtraMarketplacesIdempotent(t *testing.T) {
 
 	infoBefore, _ := os.Stat(settingsPath)
 
-	syncErr := SyncExtraMarketplaces(settingsPath, known)
+	syncErr := SyncExtraMarketplaces(settingsPath, known, nil)
 	if syncErr != nil {
 		t.Fatal(syncErr)
 	}
@@ -725,7 +1064,7 @@ func TestSyncExtraMarketplacesCreatesFile(t *testing.T) {
 		"mp": {Source: GitHubSource{Repo: "owner/repo"}},
 	}
 
-	syncErr := SyncExtraMarketplaces(settingsPath, known)
+	syncErr := SyncExtraMarketplaces(settingsPath, known, nil)
 	if syncErr != nil {
 		t.Fatal(syncErr)
 	}
@@ -734,3 +1073,158 @@ func TestSyncExtraMarketplacesCreatesFile(t *testing.T) {
 		t.Error("file should not have been created when there are no plugins")
 	}
 }
+
+func TestSyncExtraMarketplacesPreservesFormatting(t *testing.T) {
+	tmp := setupTempDir(t, "sync-mp-*")
+	settings := `{
+    "permissions": {
+        "allow": ["Bash(git status)"]
+    },
+    "enabledPlugins": {
+        "p@mp": true,
+        "q@other": true
+    },
+    "extraKnownMarketplaces": {
+        "other": {"source": {"source": "github", "repo": "o/other"}},
+        "stale": {"source": {"source": "github", "repo": "o/stale"}}
+    },
+    "alwaysThinkingEnabled": true
+}
+`
+	settingsPath := setupClaudeDir(t, tmp, settings)
+
+	known := map[string]KnownMarketplace{
+		"mp": {Source: &GitHubSource{Repo: "owner/repo"}},
+	}
+	if err := SyncExtraMarketplaces(settingsPath, known, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{
+    "permissions": {
+        "allow": ["Bash(git status)"]
+    },
+    "enabledPlugins": {
+        "p@mp": true,
+        "q@other": true
+    },
+    "extraKnownMarketplaces": {
+        "other": {"source": {"source": "github", "repo": "o/other"}},
+        "mp": {
+            "source": {
+                "repo": "owner/repo",
+                "source": "github"
+            }
+        }
+    },
+    "alwaysThinkingEnabled": true
+}
+`
+	got, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("settings =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSetPluginEnabled(t *testing.T) {
+	tmp := setupTempDir(t, "enabled-*")
+	settingsPath := setupClaudeDir(t, tmp, "{\n  \"model\": \"opus\",\n  \"enabledPlugins\": {\n    \"a@mp\": true\n  }\n}\n")
+
+	if err := SetPluginEnabled(settingsPath, "a@mp", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPluginEnabled(settingsPath, "b@mp", true); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"model\": \"opus\",\n  \"enabledPlugins\": {\n    \"a@mp\": false,\n    \"b@mp\": true\n  }\n}\n"
+	if got, _ := os.ReadFile(settingsPath); string(got) != want {
+		t.Errorf("after set =\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := RemovePluginEnabled(settingsPath, "a@mp"); err != nil {
+		t.Fatal(err)
+	}
+	want = "{\n  \"model\": \"opus\",\n  \"enabledPlugins\": {\n    \"b@mp\": true\n  }\n}\n"
+	if got, _ := os.ReadFile(settingsPath); string(got) != want {
+		t.Errorf("after remove =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSetPluginEnabledCreatesFile(t *testing.T) {
+	tmp := setupTempDir(t, "enabled-*")
+	settingsPath := filepath.Join(tmp, ".claude", "settings.local.json")
+
+	if err := RemovePluginEnabled(settingsPath, "a@mp"); err != nil {
+		t.Fatalf("RemovePluginEnabled on missing file: %v", err)
+	}
+	if _, err := os.Stat(settingsPath); err == nil {
+		t.Fatal("RemovePluginEnabled should not create the file")
+	}
+
+	if err := SetPluginEnabled(settingsPath, "a@mp", true); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"enabledPlugins\": {\n    \"a@mp\": true\n  }\n}\n"
+	if got, _ := os.ReadFile(settingsPath); string(got) != want {
+		t.Errorf("settings =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestResolvePluginSourcePath(t *testing.T) {
+	tmp := setupTempDir(t, "resolve-source-*")
+
+	writeManifest := func(dir string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(dir, ".claude-plugin"), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".claude-plugin", "plugin.json"), []byte(`{"name":"p"}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Versioned cache entries: the newer one should win
+	oldVersion := filepath.Join(tmp, "cache", "mp", "deployer", "1.0.0")
+	newVersion := filepath.Join(tmp, "cache", "mp", "deployer", "2.0.0")
+	writeManifest(oldVersion)
+	writeManifest(newVersion)
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(oldVersion, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unversioned cache entry
+	flat := filepath.Join(tmp, "cache", "mp", "linter")
+	writeManifest(flat)
+
+	// Local directory source
+	localDir := filepath.Join(tmp, "local-plugin")
+	writeManifest(localDir)
+
+	github := map[string]any{"source": "github", "repo": "acme/deployer"}
+	tests := []struct {
+		name   string
+		plugin string
+		source any
+		want   string
+	}{
+		{"relative path", "foo", "./plugins/foo", filepath.Join(tmp, "marketplaces", "mp", "plugins", "foo")},
+		{"github cached by version", "deployer", github, newVersion},
+		{"npm cached flat", "linter", map[string]any{"source": "npm", "package": "@acme/linter"}, flat},
+		{"not cached", "missing", github, ""},
+		{"directory", "anything", map[string]any{"source": "directory", "path": localDir}, localDir},
+		{"directory without manifest", "anything", map[string]any{"source": "directory", "path": tmp}, ""},
+		{"unparseable", "deployer", map[string]any{"source": "ftp"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolvePluginSourcePath(tmp, "mp", tt.plugin, tt.source); got != tt.want {
+				t.Errorf("resolvePluginSourcePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package claude
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Marketplace is a marketplace catalogue read from .claude-plugin/marketplace.json
+// in a cloned marketplace directory.
+type Marketplace struct {
+	Name        string
+	Description string
+	Version     string
+	PluginRoot  string // Base directory for relative plugin sources
+	Owner       MarketplaceOwner
+	Plugins     []MarketplacePlugin
+}
+
+// MarketplaceOwner identifies who maintains a marketplace.
+type MarketplaceOwner struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// MarketplacePlugin is a plugin entry in a marketplace catalogue.
+type MarketplacePlugin struct {
+	Source      any // Relative path string or source object, as in AvailablePlugin.Source
+	Name        string
+	Description string
+	Version     string
+	Category    string
+	AuthorName  string
+	AuthorEmail string
+	Homepage    string
+	Repository  string
+	License     string
+	Tags        []string
+	Keywords    []string
+}
+
+// marketplaceRaw is the JSON form of marketplace.json.
+type marketplaceRaw struct {
+	Name     string           `json:"name"`
+	Owner    MarketplaceOwner `json:"owner"`
+	Metadata struct {
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version,omitempty"`
+		PluginRoot  string `json:"pluginRoot,omitempty"`
+	} `json:"metadata"`
+	Plugins []marketplacePluginRaw `json:"plugins"`
+}
+
+// marketplacePluginRaw is the JSON form of a catalogue entry, with flexible author and repository fields.
+type marketplacePluginRaw struct {
+	Source      any             `json:"source"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Version     string          `json:"version,omitempty"`
+	Category    string          `json:"category,omitempty"`
+	Author      json.RawMessage `json:"author,omitempty"`
+	Homepage    string          `json:"homepage,omitempty"`
+	Repository  json.RawMessage `json:"repository,omitempty"`
+	License     string          `json:"license,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Keywords    []string        `json:"keywords,omitempty"`
+}
+
+// ReadMarketplace reads the marketplace.json catalogue from a marketplace directory.
+func ReadMarketplace(dir string) (*Marketplace, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+
+	return ReadMarketplaceFS(root.FS())
+}
+
+// ReadMarketplaceFS reads the marketplace.json catalogue from the given filesystem.
+func ReadMarketplaceFS(fsys fs.FS) (*Marketplace, error) {
+	data, err := fs.ReadFile(fsys, ".claude-plugin/marketplace.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var raw marketplaceRaw
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	m := &Marketplace{
+		Name:        raw.Name,
+		Description: raw.Metadata.Description,
+		Version:     raw.Metadata.Version,
+		PluginRoot:  raw.Metadata.PluginRoot,
+		Owner:       raw.Owner,
+		Plugins:     make([]MarketplacePlugin, 0, len(raw.Plugins)),
+	}
+	for _, p := range raw.Plugins {
+		authorName, authorEmail := parseAuthor(p.Author)
+		m.Plugins = append(m.Plugins, MarketplacePlugin{
+			Source:      p.Source,
+			Name:        p.Name,
+			Description: p.Description,
+			Version:     p.Version,
+			Category:    p.Category,
+			AuthorName:  authorName,
+			AuthorEmail: authorEmail,
+			Homepage:    p.Homepage,
+			Repository:  parseRepository(p.Repository),
+			License:     p.License,
+			Tags:        p.Tags,
+			Keywords:    p.Keywords,
+		})
+	}
+	return m, nil
+}
+
+// Plugin returns the catalogue entry with the given name.
+func (m *Marketplace) Plugin(name string) (*MarketplacePlugin, bool) {
+	for i := range m.Plugins {
+		if m.Plugins[i].Name == name {
+			return &m.Plugins[i], true
+		}
+	}
+	return nil, false
+}
+
+// Categories returns the distinct plugin categories in the catalogue, sorted.
+func (m *Marketplace) Categories() []string {
+	var categories []string
+	for _, p := range m.Plugins {
+		if p.Category != "" {
+			categories = append(categories, p.Category)
+		}
+	}
+	slices.Sort(categories)
+	return slices.Compact(categories)
+}
+
+// AvailablePlugins converts the catalogue into the form returned by
+// `claude plugin list --available`. name is the marketplace's registered name,
+// which plugin IDs use and which may differ from the name in marketplace.json.
+func (m *Marketplace) AvailablePlugins(name string) []AvailablePlugin {
+	plugins := make([]AvailablePlugin, 0, len(m.Plugins))
+	for _, p := range m.Plugins {
+		plugins = append(plugins, AvailablePlugin{
+			PluginID:        p.Name + "@" + name,
+			Name:            p.Name,
+			Description:     p.Description,
+			MarketplaceName: name,
+			Source:          p.Source,
+			Version:         p.Version,
+		})
+	}
+	return plugins
+}
+
+// ReadMarketplaceCatalogs reads the catalogue of every marketplace under
+// ~/.claude/plugins, keyed by registered marketplace name.
+func ReadMarketplaceCatalogs() map[string]*Marketplace {
+	pluginsDir, err := userPluginsDir()
+	if err != nil {
+		return map[string]*Marketplace{}
+	}
+	return ReadMarketplaceCatalogsFrom(pluginsDir)
+}
+
+// ReadMarketplaceCatalogsFrom reads marketplace catalogues from a plugins directory.
+// Marketplaces listed in known_marketplaces.json are read from their install
+// location; clones under marketplaces/ that aren't listed are read too.
+// Marketplaces without a readable catalogue are skipped.
+func ReadMarketplaceCatalogsFrom(pluginsDir string) map[string]*Marketplace {
+	dirs := make(map[string]string)
+	if entries, err := os.ReadDir(filepath.Join(pluginsDir, "marketplaces")); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				dirs[entry.Name()] = filepath.Join(pluginsDir, "marketplaces", entry.Name())
+			}
+		}
+	}
+	if known, err := ReadKnownMarketplacesFrom(pluginsDir); err == nil {
+		for name, km := range known {
+			if km.InstallLocation != "" {
+				dirs[name] = km.InstallLocation
+			}
+		}
+	}
+
+	catalogs := make(map[string]*Marketplace)
+	for name, dir := range dirs {
+		if m, err := ReadMarketplace(dir); err == nil {
+			catalogs[name] = m
+		}
+	}
+	return catalogs
+}
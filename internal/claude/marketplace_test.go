@@ -0,0 +1,180 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+const testMarketplaceJSON = `{
+	"name": "acme-tools",
+	"owner": {"name": "Acme", "email": "plugins@acme.dev"},
+	"metadata": {"description": "Acme's plugins", "version": "2.0.0", "pluginRoot": "./plugins"},
+	"plugins": [
+		{
+			"name": "linter",
+			"source": "./plugins/linter",
+			"description": "Lint code",
+			"version": "1.4.0",
+			"category": "quality",
+			"author": {"name": "Jane Doe", "email": "jane@acme.dev"},
+			"repository": {"type": "git", "url": "https://github.com/acme/linter"},
+			"license": "MIT",
+			"tags": ["lint", "ci"],
+			"keywords": ["eslint"]
+		},
+		{
+			"name": "deployer",
+			"source": {"source": "github", "repo": "acme/deployer"},
+			"category": "ops",
+			"author": "Ops Team"
+		},
+		{
+			"name": "formatter",
+			"source": "./plugins/formatter",
+			"category": "quality"
+		}
+	]
+}`
+
+func TestReadMarketplaceFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".claude-plugin/marketplace.json": {Data: []byte(testMarketplaceJSON)},
+	}
+
+	m, err := ReadMarketplaceFS(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m.Name != "acme-tools" || m.Description != "Acme's plugins" || m.Version != "2.0.0" || m.PluginRoot != "./plugins" {
+		t.Errorf("metadata = %+v", m)
+	}
+	if m.Owner.Name != "Acme" || m.Owner.Email != "plugins@acme.dev" {
+		t.Errorf("Owner = %+v", m.Owner)
+	}
+	if len(m.Plugins) != 3 {
+		t.Fatalf("len(Plugins) = %d, want 3", len(m.Plugins))
+	}
+
+	linter, ok := m.Plugin("linter")
+	if !ok {
+		t.Fatal("Plugin(linter) not found")
+	}
+	if linter.AuthorName != "Jane Doe" || linter.AuthorEmail != "jane@acme.dev" {
+		t.Errorf("linter author = %q <%q>", linter.AuthorName, linter.AuthorEmail)
+	}
+	if linter.Repository != "https://github.com/acme/linter" {
+		t.Errorf("linter.Repository = %q", linter.Repository)
+	}
+	if linter.Source != "./plugins/linter" {
+		t.Errorf("linter.Source = %v", linter.Source)
+	}
+	if !slices.Equal(linter.Tags, []string{"lint", "ci"}) {
+		t.Errorf("linter.Tags = %v", linter.Tags)
+	}
+
+	deployer, _ := m.Plugin("deployer")
+	if deployer.AuthorName != "Ops Team" {
+		t.Errorf("deployer.AuthorName = %q, want %q", deployer.AuthorName, "Ops Team")
+	}
+	if _, isObj := deployer.Source.(map[string]any); !isObj {
+		t.Errorf("deployer.Source = %T, want object", deployer.Source)
+	}
+
+	if _, ok := m.Plugin("missing"); ok {
+		t.Error("Plugin(missing) found, want not found")
+	}
+
+	if got := m.Categories(); !slices.Equal(got, []string{"ops", "quality"}) {
+		t.Errorf("Categories() = %v, want [ops quality]", got)
+	}
+}
+
+func TestReadMarketplaceFSErrors(t *testing.T) {
+	if _, err := ReadMarketplaceFS(fstest.MapFS{}); err == nil {
+		t.Error("expected error for missing marketplace.json")
+	}
+
+	fsys := fstest.MapFS{
+		".claude-plugin/marketplace.json": {Data: []byte(`{"plugins": [`)},
+	}
+	if _, err := ReadMarketplaceFS(fsys); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestMarketplaceAvailablePlugins(t *testing.T) {
+	m, err := ReadMarketplaceFS(fstest.MapFS{
+		".claude-plugin/marketplace.json": {Data: []byte(testMarketplaceJSON)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Registered name differs from the catalogue's own name
+	available := m.AvailablePlugins("acme")
+	if len(available) != 3 {
+		t.Fatalf("len(available) = %d, want 3", len(available))
+	}
+	first := available[0]
+	if first.PluginID != "linter@acme" || first.MarketplaceName != "acme" {
+		t.Errorf("first = %+v", first)
+	}
+	if first.Version != "1.4.0" || first.Description != "Lint code" || first.Source != "./plugins/linter" {
+		t.Errorf("first = %+v", first)
+	}
+}
+
+func TestReadMarketplaceCatalogsFrom(t *testing.T) {
+	tmp := setupTempDir(t, "catalogs-*")
+
+	writeCatalog := func(dir, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(dir, ".claude-plugin"), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".claude-plugin", "marketplace.json"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Clone in the default location
+	writeCatalog(filepath.Join(tmp, "marketplaces", "acme"), testMarketplaceJSON)
+	// Clone elsewhere, registered via installLocation
+	elsewhere := filepath.Join(tmp, "elsewhere")
+	writeCatalog(elsewhere, `{"name": "other", "plugins": [{"name": "tool", "source": "./tool"}]}`)
+	// Clone without a catalogue is skipped
+	if err := os.MkdirAll(filepath.Join(tmp, "marketplaces", "empty"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	known := `{"other": {"source": {"source": "directory", "path": "/x"}, "installLocation": "` +
+		filepath.ToSlash(elsewhere) + `", "lastUpdated": ""}}`
+	if err := os.WriteFile(filepath.Join(tmp, "known_marketplaces.json"), []byte(known), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	catalogs := ReadMarketplaceCatalogsFrom(tmp)
+	if len(catalogs) != 2 {
+		t.Fatalf("len(catalogs) = %d, want 2: %v", len(catalogs), catalogs)
+	}
+	if catalogs["acme"] == nil || catalogs["acme"].Name != "acme-tools" {
+		t.Errorf("catalogs[acme] = %+v", catalogs["acme"])
+	}
+	if catalogs["other"] == nil || len(catalogs["other"].Plugins) != 1 {
+		t.Errorf("catalogs[other] = %+v", catalogs["other"])
+	}
+	if _, ok := catalogs["empty"]; ok {
+		t.Error("catalogs[empty] present, want skipped")
+	}
+}
+
+func TestReadMarketplaceCatalogsFromMissingDir(t *testing.T) {
+	catalogs := ReadMarketplaceCatalogsFrom(filepath.Join(os.TempDir(), "cpm-does-not-exist"))
+	if len(catalogs) != 0 {
+		t.Errorf("len(catalogs) = %d, want 0", len(catalogs))
+	}
+}
@@ -0,0 +1,286 @@
+package claude
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// redactedValue replaces secret values in displayed configs.
+const redactedValue = "<redacted>"
+
+// envRefPattern matches ${VAR} and ${VAR:-default} references in MCP server configs.
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-[^}]*)?\}`)
+
+// secretNamePattern matches flag and query parameter names whose values are
+// likely secrets.
+var secretNamePattern = regexp.MustCompile(`(?i)token|secret|passw|api[-_]?key|auth|credential`)
+
+// MCPServer describes an MCP server a plugin launches or connects to.
+// Only the names of environment variables are kept; values are never read into it.
+type MCPServer struct {
+	Name    string
+	Type    string // "stdio", "sse", or "http"
+	Command string // Executable for stdio servers
+	URL     string // Endpoint for sse/http servers
+	Source  string // Config the server came from, relative to the plugin root
+	Args    []string
+	EnvVars []string // Names of environment variables the server requires
+}
+
+// Endpoint returns the command line or URL the server uses, for display.
+// Values of secret-looking flags, query parameters, and URL credentials are
+// redacted; ${VAR} references are kept since they only name the secret.
+func (s MCPServer) Endpoint() string {
+	if s.URL != "" {
+		return redactURL(s.URL)
+	}
+	return trimPluginRoot(strings.TrimSpace(s.Command + " " + strings.Join(redactArgs(s.Args), " ")))
+}
+
+// redactSecret returns redactedValue in place of a literal secret value.
+func redactSecret(value string) string {
+	if value == "" || envRefPattern.MatchString(value) {
+		return value
+	}
+	return redactedValue
+}
+
+// redactArgs returns args with the values of secret-looking flags redacted,
+// in both the --token=value and --token value forms.
+func redactArgs(args []string) []string {
+	out := slices.Clone(args)
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if !strings.HasPrefix(arg, "-") || !secretNamePattern.MatchString(arg) {
+			continue
+		}
+		if name, value, ok := strings.Cut(arg, "="); ok {
+			if secretNamePattern.MatchString(name) {
+				out[i] = name + "=" + redactSecret(value)
+			}
+		} else if i+1 < len(out) && !strings.HasPrefix(out[i+1], "-") {
+			i++
+			out[i] = redactSecret(out[i])
+		}
+	}
+	return out
+}
+
+// redactURL redacts the credentials and secret-looking query parameters of
+// an endpoint URL. URLs that don't parse are returned unchanged.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		if name, value, ok := strings.Cut(param, "="); ok && secretNamePattern.MatchString(name) {
+			params[i] = name + "=" + redactSecret(value)
+		}
+	}
+	u.RawQuery = strings.Join(params, "&")
+
+	hasUser := u.User != nil
+	u.User = nil
+	s := u.String()
+	if hasUser {
+		s = strings.Replace(s, "//", "//"+redactedValue+"@", 1)
+	}
+	return s
+}
+
+// mcpServerRaw is the JSON form of a single MCP server config. Env and
+// header values are decoded loosely, since only their names and ${VAR}
+// references are used.
+type mcpServerRaw struct {
+	Env     map[string]any `json:"env,omitempty"`
+	Headers map[string]any `json:"headers,omitempty"`
+	Type    string         `json:"type,omitempty"`
+	Command string         `json:"command,omitempty"`
+	URL     string         `json:"url,omitempty"`
+	Args    []string       `json:"args,omitempty"`
+}
+
+// toServer converts a raw config into an MCPServer, collecting required env var names.
+func (r mcpServerRaw) toServer(name, source string) MCPServer {
+	s := MCPServer{
+		Name:    name,
+		Type:    r.Type,
+		Command: r.Command,
+		URL:     r.URL,
+		Args:    r.Args,
+		Source:  source,
+	}
+	if s.Type == "" {
+		s.Type = "stdio"
+		if r.URL != "" {
+			s.Type = "http"
+		}
+	}
+
+	// Referenced values (${VAR}) are required from the user's environment
+	refs := []string{r.Command, r.URL}
+	refs = append(refs, r.Args...)
+	for _, v := range r.Headers {
+		if ref, ok := v.(string); ok {
+			refs = append(refs, ref)
+		}
+	}
+	for _, v := range r.Env {
+		if ref, ok := v.(string); ok {
+			refs = append(refs, ref)
+		}
+	}
+
+	vars := slices.Collect(maps.Keys(r.Env))
+	for _, ref := range refs {
+		for _, m := range envRefPattern.FindAllStringSubmatch(ref, -1) {
+			if m[1] != "CLAUDE_PLUGIN_ROOT" {
+				vars = append(vars, m[1])
+			}
+		}
+	}
+	slices.Sort(vars)
+	s.EnvVars = slices.Compact(vars)
+	return s
+}
+
+// parseMCPConfig parses an MCP config, which is either {"mcpServers": {...}}
+// (as in .mcp.json) or a bare map of server name to config (as inline in plugin.json).
+// Servers are returned sorted by name.
+func parseMCPConfig(data []byte, source string) ([]MCPServer, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, err
+	}
+	serversRaw := json.RawMessage(data)
+	if inner, ok := top["mcpServers"]; ok {
+		serversRaw = inner
+	}
+
+	var raw map[string]mcpServerRaw
+	if err := json.Unmarshal(serversRaw, &raw); err != nil {
+		return nil, fmt.Errorf("parse mcp servers: %w", err)
+	}
+
+	servers := make([]MCPServer, 0, len(raw))
+	for _, name := range slices.Sorted(maps.Keys(raw)) {
+		servers = append(servers, raw[name].toServer(name, source))
+	}
+	return servers, nil
+}
+
+// readMCPServersFS reads and parses an MCP config file.
+func readMCPServersFS(fsys fs.FS, p string) ([]MCPServer, error) {
+	data, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return nil, err
+	}
+	return parseMCPConfig(data, p)
+}
+
+// scanMCPServersFS collects MCP servers from .mcp.json, config paths declared in
+// the manifest, servers declared inline in plugin.json, and mcp-servers/ subdirectories.
+// Missing or malformed configs are skipped.
+func scanMCPServersFS(fsys fs.FS, manifest *PluginManifest) []MCPServer {
+	var servers []MCPServer
+	add := func(found []MCPServer) {
+		for _, s := range found {
+			if !slices.ContainsFunc(servers, func(e MCPServer) bool { return e.Name == s.Name }) {
+				servers = append(servers, s)
+			}
+		}
+	}
+
+	for _, p := range configPaths(".mcp.json", manifest.MCPServers) {
+		if found, err := readMCPServersFS(fsys, p); err == nil {
+			add(found)
+		}
+	}
+	if len(manifest.InlineMCPServers) > 0 {
+		if found, err := parseMCPConfig(manifest.InlineMCPServers, manifestPath); err == nil {
+			add(found)
+		}
+	}
+
+	// mcp-servers/<name>/ directories, optionally with a config.json for the server
+	for _, name := range listSubdirectoriesFS(fsys, "mcp-servers") {
+		s := MCPServer{Name: name, Source: path.Join("mcp-servers", name)}
+		configPath := path.Join("mcp-servers", name, "config.json")
+		if data, err := fs.ReadFile(fsys, configPath); err == nil {
+			var raw mcpServerRaw
+			if json.Unmarshal(data, &raw) == nil {
+				s = raw.toServer(name, configPath)
+			}
+		}
+		add([]MCPServer{s})
+	}
+
+	slices.SortFunc(servers, func(a, b MCPServer) int { return cmp.Compare(a.Name, b.Name) })
+	return servers
+}
+
+// redactSecretValues redacts secrets in the MCP server configs of a parsed
+// JSON document as Endpoint does, so that tokens and Authorization headers are
+// never displayed: every "env" and "headers" value, secret-looking "args", and
+// the credentials and secret-looking query parameters of a "url".
+func redactSecretValues(v any) {
+	switch node := v.(type) {
+	case map[string]any:
+		for key, child := range node {
+			if redacted, ok := redactSecretField(key, child); ok {
+				node[key] = redacted
+				continue
+			}
+			redactSecretValues(child)
+		}
+	case []any:
+		for _, child := range node {
+			redactSecretValues(child)
+		}
+	}
+}
+
+// redactSecretField returns the redacted value of a field that can hold MCP
+// server secrets, or false if key names no such field.
+func redactSecretField(key string, child any) (any, bool) {
+	switch value := child.(type) {
+	case map[string]any:
+		if key != "env" && key != "headers" {
+			return nil, false
+		}
+		for name := range value {
+			value[name] = redactedValue
+		}
+		return value, true
+	case []any:
+		args := make([]string, len(value))
+		for i, arg := range value {
+			s, ok := arg.(string)
+			if !ok || key != "args" {
+				return nil, false
+			}
+			args[i] = s
+		}
+		for i, arg := range redactArgs(args) {
+			value[i] = arg
+		}
+		return value, true
+	case string:
+		if key != "url" {
+			return nil, false
+		}
+		return redactURL(value), true
+	default:
+		return nil, false
+	}
+}
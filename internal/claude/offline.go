@@ -0,0 +1,224 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ErrNeedsCLI is returned by the offline client for operations that only the
+// claude CLI can perform, such as downloading a plugin.
+var ErrNeedsCLI = errors.New("requires the claude CLI")
+
+// OfflineClient is a Client that edits the enabledPlugins entries of the
+// settings files directly instead of running the claude CLI. It can only
+// install plugins whose files are already in the local plugin cache.
+type OfflineClient interface {
+	Client
+
+	// IsCached reports whether the plugin's files are in the local plugin cache.
+	IsCached(pluginID string) bool
+}
+
+// offlineClient implements OfflineClient from the files the CLI maintains
+// under ~/.claude and the project's .claude directory.
+type offlineClient struct {
+	workingDir  string
+	configDir   string
+	managedPath string
+}
+
+// NewOfflineClient creates a Client for use when the claude CLI is missing or
+// broken. Plugins are listed from installed_plugins.json, the settings files,
+// and the cloned marketplace catalogues.
+func NewOfflineClient(workingDir string) OfflineClient {
+	return &offlineClient{workingDir: workingDir, configDir: ConfigDir(), managedPath: ManagedSettingsPath()}
+}
+
+// pluginsDir returns the plugins directory of the Claude config directory.
+func (c *offlineClient) pluginsDir() string {
+	return filepath.Join(c.configDir, "plugins")
+}
+
+// ListPlugins implements Client.ListPlugins.
+func (c *offlineClient) ListPlugins(includeAvailable bool) (*PluginList, error) {
+	records, err := ReadInstalledPluginsFrom(c.pluginsDir())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	scopes, _ := readAllSettings(c.workingDir, c.configDir, c.managedPath)
+
+	list := &PluginList{}
+	recorded := make(map[string]map[Scope]bool)
+	for _, p := range records {
+		if p.Scope == ScopeUser || p.ProjectPath == c.workingDir {
+			p.Enabled = scopes[p.ID][p.Scope]
+			if recorded[p.ID] == nil {
+				recorded[p.ID] = make(map[Scope]bool)
+			}
+			recorded[p.ID][p.Scope] = true
+		}
+		list.Installed = append(list.Installed, p)
+	}
+
+	// Settings entries the CLI didn't record, e.g. added by hand or by cpm offline
+	for _, id := range slices.Sorted(maps.Keys(scopes)) {
+		for _, scope := range []Scope{ScopeUser, ScopeProject, ScopeLocal} {
+			enabled, ok := scopes[id][scope]
+			if !ok || recorded[id][scope] {
+				continue
+			}
+			p := InstalledPlugin{ID: id, Scope: scope, Enabled: enabled, InstallPath: c.cachedPath(id)}
+			if scope != ScopeUser {
+				p.ProjectPath = c.workingDir
+			}
+			list.Installed = append(list.Installed, p)
+		}
+	}
+
+	if includeAvailable {
+		catalogs := ReadMarketplaceCatalogsFrom(c.pluginsDir())
+		for _, name := range slices.Sorted(maps.Keys(catalogs)) {
+			list.Available = append(list.Available, catalogs[name].AvailablePlugins(name)...)
+		}
+	}
+	return list, nil
+}
+
+// IsCached implements OfflineClient.IsCached.
+func (c *offlineClient) IsCached(pluginID string) bool {
+	return c.cachedPath(pluginID) != ""
+}
+
+// cachedPath returns the cached copy of a plugin, or "" if there is none.
+func (c *offlineClient) cachedPath(pluginID string) string {
+	marketplace := MarketplaceNameFromPluginID(pluginID)
+	if marketplace == "" {
+		return ""
+	}
+	name := pluginID[:len(pluginID)-len(marketplace)-1]
+	return cachedPluginPath(filepath.Join(c.pluginsDir(), "cache", marketplace, name))
+}
+
+// settingsPath returns the settings file that holds a scope's enabledPlugins.
+func (c *offlineClient) settingsPath(scope Scope) (string, error) {
+	switch scope {
+	case ScopeUser:
+		if c.configDir == "" {
+			return "", errNoConfigDir
+		}
+		return filepath.Join(c.configDir, "settings.json"), nil
+	case ScopeProject, ScopeLocal:
+		return SettingsPathForScope(c.workingDir, scope), nil
+	default:
+		return "", fmt.Errorf("can't edit %q settings", scope)
+	}
+}
+
+// setEnabled writes a plugin's enabledPlugins entry for a scope.
+func (c *offlineClient) setEnabled(pluginID string, scope Scope, enabled bool) error {
+	path, err := c.settingsPath(scope)
+	if err != nil {
+		return err
+	}
+	return SetPluginEnabled(path, pluginID, enabled)
+}
+
+// InstallPlugin implements Client.InstallPlugin. Only cached plugins can be
+// installed; installing enables the plugin in the scope's settings file.
+func (c *offlineClient) InstallPlugin(pluginID string, scope Scope) error {
+	if !c.IsCached(pluginID) {
+		return fmt.Errorf("%s isn't in the plugin cache; downloading it %w", pluginID, ErrNeedsCLI)
+	}
+	return c.setEnabled(pluginID, scope, true)
+}
+
+// UninstallPlugin implements Client.UninstallPlugin. The plugin's entry is
+// removed from the scope's settings file; the cached files are kept.
+func (c *offlineClient) UninstallPlugin(pluginID string, scope Scope) error {
+	path, err := c.settingsPath(scope)
+	if err != nil {
+		return err
+	}
+	return RemovePluginEnabled(path, pluginID)
+}
+
+// EnablePlugin implements Client.EnablePlugin.
+func (c *offlineClient) EnablePlugin(pluginID string, scope Scope) error {
+	return c.setEnabled(pluginID, scope, true)
+}
+
+// DisablePlugin implements Client.DisablePlugin.
+func (c *offlineClient) DisablePlugin(pluginID string, scope Scope) error {
+	return c.setEnabled(pluginID, scope, false)
+}
+
+// AddMarketplace implements Client.AddMarketplace. Cloning a marketplace
+// needs the CLI.
+func (c *offlineClient) AddMarketplace(source string, _ Scope) error {
+	return fmt.Errorf("adding marketplace %s %w", source, ErrNeedsCLI)
+}
+
+// UpdateMarketplace implements Client.UpdateMarketplace. Fetching a
+// marketplace needs the CLI.
+func (c *offlineClient) UpdateMarketplace(name string) error {
+	return fmt.Errorf("updating marketplace %s %w", name, ErrNeedsCLI)
+}
+
+// RemoveMarketplace implements Client.RemoveMarketplace. The CLI keeps the
+// marketplace registry, so removing one needs it.
+func (c *offlineClient) RemoveMarketplace(name string) error {
+	return fmt.Errorf("removing marketplace %s %w", name, ErrNeedsCLI)
+}
+
+// installedPluginsFile is the CLI's record of plugin installations.
+// Version 1 keys one entry by plugin ID; version 2 keeps a list of
+// installations per plugin, one per scope (and project).
+type installedPluginsFile struct {
+	Plugins map[string]json.RawMessage `json:"plugins"`
+	Version int                        `json:"version"`
+}
+
+// ReadInstalledPluginsFrom reads installed_plugins.json from a plugins
+// directory. Enabled state isn't recorded there; it comes from the settings files.
+func ReadInstalledPluginsFrom(pluginsDir string) ([]InstalledPlugin, error) {
+	root, err := os.OpenRoot(pluginsDir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+
+	data, err := fs.ReadFile(root.FS(), "installed_plugins.json")
+	if err != nil {
+		return nil, err
+	}
+	var file installedPluginsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse installed_plugins.json: %w", err)
+	}
+
+	var result []InstalledPlugin
+	for _, id := range slices.Sorted(maps.Keys(file.Plugins)) {
+		var entries []InstalledPlugin
+		if err := json.Unmarshal(file.Plugins[id], &entries); err != nil {
+			var single InstalledPlugin
+			if err := json.Unmarshal(file.Plugins[id], &single); err != nil {
+				return nil, fmt.Errorf("parse installed_plugins.json entry %q: %w", id, err)
+			}
+			entries = []InstalledPlugin{single}
+		}
+		for _, p := range entries {
+			p.ID = id
+			if p.Scope == ScopeNone {
+				p.Scope = ScopeUser // Version 1 only had user installs
+			}
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
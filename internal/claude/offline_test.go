@@ -0,0 +1,144 @@
+package claude
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadInstalledPluginsFrom(t *testing.T) {
+	t.Run("version 2", func(t *testing.T) {
+		tmp := t.TempDir()
+		data := `{"version": 2, "plugins": {
+			"b@mp": [
+				{"scope": "user", "installPath": "/cache/mp/b/1.0.0", "version": "1.0.0"},
+				{"scope": "local", "projectPath": "/work", "installPath": "/cache/mp/b/1.0.0", "version": "1.0.0"}
+			],
+			"a@mp": [{"scope": "project", "projectPath": "/other", "version": "2.0.0"}]
+		}}`
+		if err := os.WriteFile(filepath.Join(tmp, "installed_plugins.json"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ReadInstalledPluginsFrom(tmp)
+		if err != nil {
+			t.Fatalf("ReadInstalledPluginsFrom: %v", err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("got %d installations, want 3: %+v", len(got), got)
+		}
+		if got[0].ID != "a@mp" || got[0].Scope != ScopeProject || got[0].ProjectPath != "/other" {
+			t.Errorf("got[0] = %+v, want a@mp at project scope in /other", got[0])
+		}
+		if got[2].ID != "b@mp" || got[2].Scope != ScopeLocal || got[2].InstallPath != "/cache/mp/b/1.0.0" {
+			t.Errorf("got[2] = %+v, want b@mp at local scope", got[2])
+		}
+	})
+
+	t.Run("version 1", func(t *testing.T) {
+		tmp := t.TempDir()
+		data := `{"version": 1, "plugins": {"a@mp": {"version": "1.0.0", "installPath": "/cache/a"}}}`
+		if err := os.WriteFile(filepath.Join(tmp, "installed_plugins.json"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ReadInstalledPluginsFrom(tmp)
+		if err != nil {
+			t.Fatalf("ReadInstalledPluginsFrom: %v", err)
+		}
+		if len(got) != 1 || got[0].ID != "a@mp" || got[0].Scope != ScopeUser {
+			t.Errorf("got %+v, want a@mp at user scope", got)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := ReadInstalledPluginsFrom(t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("err = %v, want ErrNotExist", err)
+		}
+	})
+}
+
+// setupOfflineHome creates a home directory with a cached copy of cached@mp.
+func setupOfflineHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	manifest := filepath.Join(home, ".claude", "plugins", "cache", "mp", "cached", "1.0.0", ".claude-plugin", "plugin.json")
+	if err := os.MkdirAll(filepath.Dir(manifest), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(manifest, []byte(`{"name": "cached"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+func TestOfflineClientListPlugins(t *testing.T) {
+	home := setupOfflineHome(t)
+	work := t.TempDir()
+	setupClaudeDir(t, home, `{"enabledPlugins": {"cached@mp": false, "manual@mp": true}}`)
+	records := `{"version": 2, "plugins": {"cached@mp": [{"scope": "user", "version": "1.0.0"}]}}`
+	if err := os.WriteFile(filepath.Join(home, ".claude", "plugins", "installed_plugins.json"), []byte(records), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &offlineClient{workingDir: work, configDir: filepath.Join(home, ".claude")}
+	list, err := client.ListPlugins(true)
+	if err != nil {
+		t.Fatalf("ListPlugins: %v", err)
+	}
+	if len(list.Installed) != 2 {
+		t.Fatalf("Installed = %+v, want 2 entries", list.Installed)
+	}
+	if p := list.Installed[0]; p.ID != "cached@mp" || p.Enabled || p.Version != "1.0.0" {
+		t.Errorf("Installed[0] = %+v, want disabled cached@mp from installed_plugins.json", p)
+	}
+	if p := list.Installed[1]; p.ID != "manual@mp" || !p.Enabled || p.Scope != ScopeUser {
+		t.Errorf("Installed[1] = %+v, want enabled manual@mp from settings", p)
+	}
+}
+
+func TestOfflineClientEdits(t *testing.T) {
+	home := setupOfflineHome(t)
+	work := t.TempDir()
+	client := &offlineClient{workingDir: work, configDir: filepath.Join(home, ".claude")}
+	localPath := filepath.Join(work, ".claude", "settings.local.json")
+
+	if err := client.InstallPlugin("missing@mp", ScopeLocal); !errors.Is(err, ErrNeedsCLI) {
+		t.Errorf("InstallPlugin(uncached) err = %v, want ErrNeedsCLI", err)
+	}
+	if _, err := os.Stat(localPath); !os.IsNotExist(err) {
+		t.Error("failed install should not create a settings file")
+	}
+
+	if err := client.InstallPlugin("cached@mp", ScopeLocal); err != nil {
+		t.Fatalf("InstallPlugin(cached): %v", err)
+	}
+	if err := client.DisablePlugin("cached@mp", ScopeLocal); err != nil {
+		t.Fatalf("DisablePlugin: %v", err)
+	}
+	data, _ := os.ReadFile(localPath)
+	if !strings.Contains(string(data), `"cached@mp": false`) {
+		t.Errorf("settings = %s, want cached@mp disabled", data)
+	}
+
+	if err := client.UninstallPlugin("cached@mp", ScopeLocal); err != nil {
+		t.Fatalf("UninstallPlugin: %v", err)
+	}
+	data, _ = os.ReadFile(localPath)
+	if strings.Contains(string(data), "cached@mp") {
+		t.Errorf("settings = %s, want cached@mp removed", data)
+	}
+
+	if err := client.EnablePlugin("cached@mp", ScopeUser); err != nil {
+		t.Fatalf("EnablePlugin(user): %v", err)
+	}
+	if got := getAllEnabledPlugins(work, filepath.Join(home, ".claude"))["cached@mp"]; !got[ScopeUser] {
+		t.Errorf("user scope = %v, want enabled", got)
+	}
+
+	if err := client.EnablePlugin("cached@mp", ScopeManaged); err == nil {
+		t.Error("EnablePlugin(managed) should fail")
+	}
+}
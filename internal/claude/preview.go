@@ -0,0 +1,57 @@
+package claude
+
+import (
+	"fmt"
+
+	"github.com/open-cli-collective/cpm/internal/jsonedit"
+)
+
+// SettingsChange is a change to one plugin's enabledPlugins entry.
+type SettingsChange struct {
+	PluginID string
+	Enabled  bool // Value to set; ignored when Remove is set
+	Remove   bool // Remove the entry instead of setting it
+}
+
+// PreviewSettings returns a settings file's current contents and its contents
+// after applying changes in order, as SetPluginEnabled and RemovePluginEnabled
+// would. If knownMarketplaces is non-nil, extraKnownMarketplaces is then
+// reconciled as SyncExtraMarketplaces would with keep. A missing file reads as
+// empty. Nothing is written.
+func PreviewSettings(settingsPath string, changes []SettingsChange, knownMarketplaces map[string]KnownMarketplace, keep []string) (before, after []byte, err error) {
+	before, err = readSettingsFile(settingsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	after, err = previewChanges(before, changes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if knownMarketplaces != nil && after != nil {
+		if after, err = syncExtra(after, knownMarketplaces, keep); err != nil {
+			return nil, nil, err
+		}
+	}
+	return before, after, nil
+}
+
+// previewChanges applies changes to settings file contents. Nil data is a
+// missing file: removals leave it missing and the first set creates it.
+func previewChanges(data []byte, changes []SettingsChange) ([]byte, error) {
+	var err error
+	for _, c := range changes {
+		path := []string{"enabledPlugins", c.PluginID}
+		switch {
+		case c.Remove && data == nil:
+		case c.Remove:
+			data, err = jsonedit.Delete(data, path)
+		default:
+			data, err = jsonedit.Set(data, path, c.Enabled)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("update settings: %w", err)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,70 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreviewSettings(t *testing.T) {
+	tmp := setupTempDir(t, "preview-*")
+	original := "{\n  \"enabledPlugins\": {\n    \"old@other\": true\n  },\n  \"extraKnownMarketplaces\": {\n    \"other\": {\"source\": {\"source\": \"github\", \"repo\": \"o/other\"}}\n  }\n}\n"
+	settingsPath := setupClaudeDir(t, tmp, original)
+	known := map[string]KnownMarketplace{
+		"mp": {Source: &GitHubSource{Repo: "owner/repo"}},
+	}
+
+	changes := []SettingsChange{
+		{PluginID: "old@other", Remove: true},
+		{PluginID: "new@mp", Enabled: true},
+	}
+	before, after, err := PreviewSettings(settingsPath, changes, known, nil)
+	if err != nil {
+		t.Fatalf("PreviewSettings: %v", err)
+	}
+	if string(before) != original {
+		t.Errorf("before = %s, want the file contents", before)
+	}
+	for _, want := range []string{`"new@mp": true`, `"mp": {`, `"owner/repo"`} {
+		if !strings.Contains(string(after), want) {
+			t.Errorf("after missing %s:\n%s", want, after)
+		}
+	}
+	for _, gone := range []string{"old@other", "o/other"} {
+		if strings.Contains(string(after), gone) {
+			t.Errorf("after still contains %s:\n%s", gone, after)
+		}
+	}
+
+	got, _ := os.ReadFile(settingsPath)
+	if string(got) != original {
+		t.Error("PreviewSettings should not write the file")
+	}
+
+	// Without known marketplaces, extraKnownMarketplaces is left alone
+	_, after, err = PreviewSettings(settingsPath, changes, nil, nil)
+	if err != nil {
+		t.Fatalf("PreviewSettings without sync: %v", err)
+	}
+	if !strings.Contains(string(after), "o/other") {
+		t.Errorf("after = %s, want extraKnownMarketplaces unchanged", after)
+	}
+}
+
+func TestPreviewSettingsMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "settings.json")
+
+	before, after, err := PreviewSettings(path, []SettingsChange{{PluginID: "p@mp", Remove: true}}, nil, nil)
+	if err != nil || before != nil || after != nil {
+		t.Errorf("removal from missing file = (%q, %q, %v), want nothing", before, after, err)
+	}
+
+	_, after, err = PreviewSettings(path, []SettingsChange{{PluginID: "p@mp", Enabled: true}}, nil, nil)
+	if err != nil {
+		t.Fatalf("PreviewSettings: %v", err)
+	}
+	if !strings.Contains(string(after), `"p@mp": true`) {
+		t.Errorf("after = %s, want new file enabling p@mp", after)
+	}
+}
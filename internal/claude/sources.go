@@ -0,0 +1,268 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SourceTypes lists the marketplace source types in the order forms offer them.
+var SourceTypes = []string{"github", "git", "url", "npm", "file", "directory", "hostPattern"}
+
+// SourceField is an input a form asks for to build a source of some type.
+type SourceField struct {
+	Key      string // Field name in the source's JSON form
+	Label    string
+	Hint     string // Example value
+	Required bool
+}
+
+// headersKey is the URLSource field entered as "Name: value" pairs.
+const headersKey = "headers"
+
+var (
+	githubRepoPattern      = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	marketplaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+)
+
+// SourceFormFields returns the fields of a source type, or nil for unknown types.
+func SourceFormFields(sourceType string) []SourceField {
+	ref := SourceField{Key: "ref", Label: "Ref", Hint: "branch, tag, or commit"}
+	subdir := SourceField{Key: "path", Label: "Path", Hint: "directory within the repository"}
+	switch sourceType {
+	case "github":
+		return []SourceField{{Key: "repo", Label: "Repo", Hint: "owner/repo", Required: true}, ref, subdir}
+	case "git":
+		return []SourceField{{Key: "url", Label: "URL", Hint: "https://host/repo.git", Required: true}, ref, subdir}
+	case "url":
+		return []SourceField{
+			{Key: "url", Label: "URL", Hint: "https://host/marketplace.json", Required: true},
+			{Key: headersKey, Label: "Headers", Hint: "Name: value, Name: value"},
+		}
+	case "npm":
+		return []SourceField{{Key: "package", Label: "Package", Hint: "@scope/name", Required: true}}
+	case "file":
+		return []SourceField{{Key: "path", Label: "Path", Hint: "path/to/marketplace.json", Required: true}}
+	case "directory":
+		return []SourceField{{Key: "path", Label: "Path", Hint: "path/to/marketplace", Required: true}}
+	case "hostPattern":
+		return []SourceField{{Key: "hostPattern", Label: "Pattern", Hint: `^github\.example\.com$`, Required: true}}
+	default:
+		return nil
+	}
+}
+
+// NewSource builds and validates a source of the given type from form values
+// keyed by SourceField.Key. Empty optional values are left out.
+func NewSource(sourceType string, values map[string]string) (MarketplaceSource, error) {
+	fields := SourceFormFields(sourceType)
+	if fields == nil {
+		return nil, fmt.Errorf("unknown marketplace source type: %q", sourceType)
+	}
+
+	obj := map[string]any{"source": sourceType}
+	for _, f := range fields {
+		value := strings.TrimSpace(values[f.Key])
+		if value == "" {
+			if f.Required {
+				return nil, fmt.Errorf("%s is required", f.Label)
+			}
+			continue
+		}
+		if err := validateSourceField(sourceType, f.Key, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Label, err)
+		}
+		if f.Key == headersKey {
+			headers, err := parseHeaders(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Label, err)
+			}
+			obj[f.Key] = headers
+			continue
+		}
+		obj[f.Key] = value
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalSource(data)
+}
+
+// validateSourceField checks one non-empty form value.
+func validateSourceField(sourceType, key, value string) error {
+	switch sourceType {
+	case "github":
+		return validateGitHubField(key, value)
+	case "git":
+		return validateGitField(key, value)
+	case "url":
+		return validateURLField(key, value)
+	case "hostPattern":
+		return validateHostPatternField(key, value)
+	default:
+		return nil
+	}
+}
+
+// validateGitHubField checks a github source's repo and ref.
+func validateGitHubField(key, value string) error {
+	if key == "repo" && !githubRepoPattern.MatchString(value) {
+		return fmt.Errorf("%q is not owner/repo", value)
+	}
+	return validateRef(key, value)
+}
+
+// validateGitField checks a git source's URL and ref.
+func validateGitField(key, value string) error {
+	if key == "url" && !strings.HasPrefix(value, "git@") && !strings.Contains(value, "://") {
+		return fmt.Errorf("%q is not a git URL", value)
+	}
+	return validateRef(key, value)
+}
+
+// validateRef checks the ref of a github or git source.
+func validateRef(key, value string) error {
+	if key == "ref" && strings.ContainsAny(value, " \t") {
+		return fmt.Errorf("invalid ref %q", value)
+	}
+	return nil
+}
+
+// validateURLField checks a url source's URL.
+func validateURLField(key, value string) error {
+	if key != "url" {
+		return nil
+	}
+	if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http(s) URL", value)
+	}
+	return nil
+}
+
+// validateHostPatternField checks that a hostPattern source's pattern compiles.
+func validateHostPatternField(key, value string) error {
+	if key != "hostPattern" {
+		return nil
+	}
+	if _, err := regexp.Compile(value); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	return nil
+}
+
+// parseHeaders parses comma-separated "Name: value" pairs.
+func parseHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for pair := range strings.SplitSeq(value, ",") {
+		name, val, ok := strings.Cut(pair, ":")
+		name, val = strings.TrimSpace(name), strings.TrimSpace(val)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("%q is not Name: value", strings.TrimSpace(pair))
+		}
+		headers[name] = val
+	}
+	return headers, nil
+}
+
+// sourceLocation holds the fields of a source that decide how it can be added.
+type sourceLocation struct {
+	Headers map[string]string `json:"headers"`
+	Repo    string            `json:"repo"`
+	URL     string            `json:"url"`
+	Package string            `json:"package"`
+	Ref     string            `json:"ref"`
+	Path    string            `json:"path"`
+}
+
+// readSourceLocation returns a source's location fields.
+func readSourceLocation(src MarketplaceSource) sourceLocation {
+	var loc sourceLocation
+	if data, err := marshalSource(src); err == nil {
+		_ = json.Unmarshal(data, &loc)
+	}
+	return loc
+}
+
+// MarketplaceAddArg returns the argument "claude plugin marketplace add"
+// takes for src, or false if the CLI can't express it (see CanAddWithCLI).
+func MarketplaceAddArg(src MarketplaceSource) (string, bool) {
+	loc := readSourceLocation(src)
+	if !cliAddable(src.SourceType(), loc.Path != "", len(loc.Headers) > 0) {
+		return "", false
+	}
+	withRef := func(location string) string {
+		if loc.Ref != "" {
+			return location + "#" + loc.Ref
+		}
+		return location
+	}
+	switch src.SourceType() {
+	case "github":
+		return withRef(loc.Repo), true
+	case "git":
+		return withRef(loc.URL), true
+	case "url":
+		return loc.URL, true
+	default:
+		return loc.Path, true
+	}
+}
+
+// CanAddWithCLI reports whether a source built from form values could be
+// added with the CLI, without validating them. The CLI takes a single
+// location, so npm and hostPattern sources, repository subdirectories, and
+// URL headers can only be declared in extraKnownMarketplaces.
+func CanAddWithCLI(sourceType string, values map[string]string) bool {
+	return cliAddable(sourceType, strings.TrimSpace(values["path"]) != "", strings.TrimSpace(values[headersKey]) != "")
+}
+
+// cliAddable is CanAddWithCLI for a source with or without a path and headers.
+func cliAddable(sourceType string, hasPath, hasHeaders bool) bool {
+	switch sourceType {
+	case "github", "git":
+		return !hasPath
+	case "url":
+		return !hasHeaders
+	case "file", "directory":
+		return true
+	default:
+		return false
+	}
+}
+
+// SuggestMarketplaceName guesses a marketplace name from its source, for
+// declaring it in extraKnownMarketplaces. It returns "" when there's no
+// obvious name.
+func SuggestMarketplaceName(src MarketplaceSource) string {
+	loc := readSourceLocation(src)
+	var base string
+	switch src.SourceType() {
+	case "github":
+		base = path.Base(loc.Repo)
+	case "git":
+		base = strings.TrimSuffix(path.Base(strings.ReplaceAll(loc.URL, ":", "/")), ".git")
+	case "npm":
+		base = path.Base(loc.Package)
+	}
+	if ValidateMarketplaceName(base) != nil {
+		return ""
+	}
+	return base
+}
+
+// ValidateMarketplaceName checks a name for use as an extraKnownMarketplaces key.
+func ValidateMarketplaceName(name string) error {
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if !marketplaceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid marketplace name %q", name)
+	}
+	return nil
+}
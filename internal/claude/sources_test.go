@@ -0,0 +1,179 @@
+package claude
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewSource(t *testing.T) {
+	tests := []struct {
+		values     map[string]string
+		name       string
+		sourceType string
+		wantString string
+		wantErr    string
+	}{
+		{
+			name:       "github with ref",
+			sourceType: "github",
+			values:     map[string]string{"repo": " owner/repo ", "ref": "v1"},
+			wantString: "owner/repo@v1",
+		},
+		{
+			name:       "github bad repo",
+			sourceType: "github",
+			values:     map[string]string{"repo": "just-a-name"},
+			wantErr:    "is not owner/repo",
+		},
+		{
+			name:       "missing required",
+			sourceType: "git",
+			values:     map[string]string{"ref": "main"},
+			wantErr:    "URL is required",
+		},
+		{
+			name:       "git ssh",
+			sourceType: "git",
+			values:     map[string]string{"url": "git@example.com:team/plugins.git", "path": "mp"},
+			wantString: "git@example.com:team/plugins.git/mp",
+		},
+		{
+			name:       "url not http",
+			sourceType: "url",
+			values:     map[string]string{"url": "ftp://example.com/m.json"},
+			wantErr:    "not an http(s) URL",
+		},
+		{
+			name:       "url bad headers",
+			sourceType: "url",
+			values:     map[string]string{"url": "https://example.com/m.json", "headers": "Authorization"},
+			wantErr:    "not Name: value",
+		},
+		{
+			name:       "npm",
+			sourceType: "npm",
+			values:     map[string]string{"package": "@acme/plugins"},
+			wantString: "@acme/plugins",
+		},
+		{
+			name:       "hostPattern bad regexp",
+			sourceType: "hostPattern",
+			values:     map[string]string{"hostPattern": "(("},
+			wantErr:    "invalid pattern",
+		},
+		{
+			name:       "unknown type",
+			sourceType: "svn",
+			wantErr:    "unknown marketplace source type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := NewSource(tt.sourceType, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewSource() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSource() error = %v", err)
+			}
+			if src.SourceType() != tt.sourceType {
+				t.Errorf("SourceType() = %q, want %q", src.SourceType(), tt.sourceType)
+			}
+			if got := src.String(); got != tt.wantString {
+				t.Errorf("String() = %q, want %q", got, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestNewSourceHeaders(t *testing.T) {
+	src, err := NewSource("url", map[string]string{
+		"url":     "https://example.com/m.json",
+		"headers": "Authorization: Bearer x, X-Team: core",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, ok := src.(*URLSource)
+	if !ok {
+		t.Fatalf("source = %T, want *URLSource", src)
+	}
+	if u.Headers["Authorization"] != "Bearer x" || u.Headers["X-Team"] != "core" || len(u.Headers) != 2 {
+		t.Errorf("Headers = %v", u.Headers)
+	}
+}
+
+func TestMarketplaceAddArg(t *testing.T) {
+	tests := []struct {
+		src    MarketplaceSource
+		want   string
+		wantOK bool
+	}{
+		{GitHubSource{Repo: "owner/repo"}, "owner/repo", true},
+		{&GitHubSource{Repo: "owner/repo", Ref: "v2"}, "owner/repo#v2", true},
+		{GitHubSource{Repo: "owner/repo", Path: "sub"}, "", false},
+		{GitSource{URL: "https://example.com/r.git", Ref: "main"}, "https://example.com/r.git#main", true},
+		{URLSource{URL: "https://example.com/m.json"}, "https://example.com/m.json", true},
+		{URLSource{URL: "https://example.com/m.json", Headers: map[string]string{"A": "b"}}, "", false},
+		{DirectorySource{Path: "/src/mp"}, "/src/mp", true},
+		{FileSource{Path: "m.json"}, "m.json", true},
+		{NPMSource{Package: "pkg"}, "", false},
+		{HostPatternSource{HostPattern: ".*"}, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := MarketplaceAddArg(tt.src)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("MarketplaceAddArg(%s %s) = %q, %v, want %q, %v", tt.src.SourceType(), tt.src, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCanAddWithCLI(t *testing.T) {
+	tests := []struct {
+		values     map[string]string
+		sourceType string
+		want       bool
+	}{
+		{map[string]string{"repo": "o/r"}, "github", true},
+		{map[string]string{"repo": "o/r", "path": " sub "}, "github", false},
+		{map[string]string{"url": "u", "headers": " "}, "url", true},
+		{map[string]string{"url": "u", "headers": "A: b"}, "url", false},
+		{map[string]string{"path": "p"}, "directory", true},
+		{map[string]string{"package": "p"}, "npm", false},
+	}
+	for _, tt := range tests {
+		if got := CanAddWithCLI(tt.sourceType, tt.values); got != tt.want {
+			t.Errorf("CanAddWithCLI(%q, %v) = %v, want %v", tt.sourceType, tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestMarketplaceName(t *testing.T) {
+	tests := []struct {
+		src  MarketplaceSource
+		want string
+	}{
+		{GitHubSource{Repo: "owner/tools"}, "tools"},
+		{GitSource{URL: "git@example.com:team/plugins.git"}, "plugins"},
+		{NPMSource{Package: "@acme/market"}, "market"},
+		{HostPatternSource{HostPattern: ".*"}, ""},
+	}
+	for _, tt := range tests {
+		if got := SuggestMarketplaceName(tt.src); got != tt.want {
+			t.Errorf("SuggestMarketplaceName(%s) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestValidateMarketplaceName(t *testing.T) {
+	for name, wantErr := range map[string]bool{"tools": false, "my-mp.v2": false, "": true, "has space": true, "-x": true} {
+		if err := ValidateMarketplaceName(name); (err != nil) != wantErr {
+			t.Errorf("ValidateMarketplaceName(%q) error = %v, wantErr %v", name, err, wantErr)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package claude
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // Scope represents the installation scope of a plugin.
@@ -18,6 +19,9 @@ const (
 	ScopeProject Scope = "project"
 	// ScopeLocal is the local project scope (.claude/settings.local.json).
 	ScopeLocal Scope = "local"
+	// ScopeManaged is the read-only enterprise policy scope (see ManagedSettingsPath).
+	// It overrides every other scope and can't be changed through cpm.
+	ScopeManaged Scope = "managed"
 )
 
 // InstalledPlugin represents a plugin that is currently installed.
@@ -52,6 +56,18 @@ type PluginList struct {
 // MarketplaceSource is the interface for marketplace source type discriminated union.
 type MarketplaceSource interface {
 	SourceType() string
+	String() string // Short display form, e.g. "owner/repo@ref/path"
+}
+
+// withRefAndPath formats a repository location as "repo@ref/path", omitting empty parts.
+func withRefAndPath(repo, ref, p string) string {
+	if ref != "" {
+		repo += "@" + ref
+	}
+	if p = strings.Trim(strings.TrimPrefix(p, "./"), "/"); p != "" {
+		repo += "/" + p
+	}
+	return repo
 }
 
 // GitHubSource represents a GitHub-hosted marketplace source.
@@ -63,6 +79,8 @@ type GitHubSource struct {
 
 func (s GitHubSource) SourceType() string { return "github" }
 
+func (s GitHubSource) String() string { return withRefAndPath(s.Repo, s.Ref, s.Path) }
+
 // GitSource represents a generic Git repository source.
 type GitSource struct {
 	URL  string `json:"url"`
@@ -72,6 +90,8 @@ type GitSource struct {
 
 func (s GitSource) SourceType() string { return "git" }
 
+func (s GitSource) String() string { return withRefAndPath(s.URL, s.Ref, s.Path) }
+
 // URLSource represents a URL-based marketplace source.
 type URLSource struct {
 	Headers map[string]string `json:"headers,omitempty"`
@@ -80,6 +100,8 @@ type URLSource struct {
 
 func (s URLSource) SourceType() string { return "url" }
 
+func (s URLSource) String() string { return s.URL }
+
 // NPMSource represents an NPM package source.
 type NPMSource struct {
 	Package string `json:"package"`
@@ -87,6 +109,8 @@ type NPMSource struct {
 
 func (s NPMSource) SourceType() string { return "npm" }
 
+func (s NPMSource) String() string { return s.Package }
+
 // FileSource represents a local file source.
 type FileSource struct {
 	Path string `json:"path"`
@@ -94,6 +118,8 @@ type FileSource struct {
 
 func (s FileSource) SourceType() string { return "file" }
 
+func (s FileSource) String() string { return s.Path }
+
 // DirectorySource represents a local directory source.
 type DirectorySource struct {
 	Path string `json:"path"`
@@ -101,6 +127,8 @@ type DirectorySource struct {
 
 func (s DirectorySource) SourceType() string { return "directory" }
 
+func (s DirectorySource) String() string { return s.Path }
+
 // HostPatternSource represents a host-pattern based source.
 type HostPatternSource struct {
 	HostPattern string `json:"hostPattern"`
@@ -108,6 +136,8 @@ type HostPatternSource struct {
 
 func (s HostPatternSource) SourceType() string { return "hostPattern" }
 
+func (s HostPatternSource) String() string { return s.HostPattern }
+
 // unmarshalSource parses JSON with a "source" discriminator field into the correct concrete type.
 func unmarshalSource(data []byte) (MarketplaceSource, error) {
 	var disc struct {
@@ -128,6 +158,24 @@ func unmarshalSource(data []byte) (MarketplaceSource, error) {
 	return target, nil
 }
 
+// ParsePluginSource parses the object form of a marketplace plugin's source
+// field into a typed MarketplaceSource. Returns nil for relative path strings
+// (plugins inside the marketplace clone) and for sources that can't be parsed.
+func ParsePluginSource(source any) MarketplaceSource {
+	if _, ok := source.(map[string]any); !ok {
+		return nil
+	}
+	data, err := json.Marshal(source)
+	if err != nil {
+		return nil
+	}
+	src, err := unmarshalSource(data)
+	if err != nil {
+		return nil
+	}
+	return src
+}
+
 // newSourceByType returns a pointer to a zero-value concrete source type.
 func newSourceByType(sourceType string) MarketplaceSource {
 	switch sourceType {
@@ -148,6 +148,45 @@ func TestMarketplaceSourceRoundTrip(t *testing.T) {
 	}
 }
 
+func TestParsePluginSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   any
+		wantType string
+		wantStr  string
+	}{
+		{"relative path", "./plugins/foo", "", ""},
+		{"nil", nil, "", ""},
+		{"unknown type", map[string]any{"source": "ftp"}, "", ""},
+		{"github", map[string]any{"source": "github", "repo": "acme/tools", "ref": "v1.2", "path": "./plugins/foo"}, "github", "acme/tools@v1.2/plugins/foo"},
+		{"github minimal", map[string]any{"source": "github", "repo": "acme/tools"}, "github", "acme/tools"},
+		{"git", map[string]any{"source": "git", "url": "https://git.example.com/tools.git", "ref": "main"}, "git", "https://git.example.com/tools.git@main"},
+		{"url", map[string]any{"source": "url", "url": "https://gitlab.com/acme/plugin.git"}, "url", "https://gitlab.com/acme/plugin.git"},
+		{"npm", map[string]any{"source": "npm", "package": "@acme/plugin"}, "npm", "@acme/plugin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := ParsePluginSource(tt.source)
+			if tt.wantType == "" {
+				if src != nil {
+					t.Errorf("ParsePluginSource() = %v, want nil", src)
+				}
+				return
+			}
+			if src == nil {
+				t.Fatal("ParsePluginSource() = nil")
+			}
+			if src.SourceType() != tt.wantType {
+				t.Errorf("SourceType() = %q, want %q", src.SourceType(), tt.wantType)
+			}
+			if src.String() != tt.wantStr {
+				t.Errorf("String() = %q, want %q", src.String(), tt.wantStr)
+			}
+		})
+	}
+}
+
 func TestUnmarshalSourceUnknownType(t *testing.T) {
 	_, err := unmarshalSource([]byte(`{"source":"unknown"}`))
 	if err == nil {
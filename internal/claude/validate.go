@@ -0,0 +1,252 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+)
+
+// Severity classifies a validation issue.
+type Severity string
+
+const (
+	// SeverityError marks problems that break the plugin or a component.
+	SeverityError Severity = "error"
+	// SeverityWarning marks problems that degrade the plugin but don't break it.
+	SeverityWarning Severity = "warning"
+)
+
+// ValidationIssue is a single problem found while validating a plugin directory.
+type ValidationIssue struct {
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"` // File the issue relates to, relative to the plugin root
+	Message  string   `json:"message"`
+}
+
+// semverPattern matches semantic versions (https://semver.org), without a leading "v".
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// pluginRootRefPattern matches file references like ${CLAUDE_PLUGIN_ROOT}/scripts/check.sh.
+var pluginRootRefPattern = regexp.MustCompile(`\$\{CLAUDE_PLUGIN_ROOT\}/([^\s"'` + "`" + `;|&)]+)`)
+
+// manifestPath is the location of plugin.json relative to the plugin root.
+const manifestPath = ".claude-plugin/plugin.json"
+
+// ValidatePlugin validates the plugin directory at dir.
+func ValidatePlugin(dir string) ([]ValidationIssue, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+
+	return ValidatePluginFS(root.FS()), nil
+}
+
+// ValidatePluginFS checks a plugin for a missing or invalid manifest, component
+// files without usable frontmatter, malformed hook and MCP configs, and
+// references to script files that don't exist.
+func ValidatePluginFS(fsys fs.FS) []ValidationIssue {
+	v := &validator{fsys: fsys}
+
+	manifest := v.checkManifest()
+	components := ScanPluginComponentsFS(fsys)
+
+	v.checkComponents(components)
+	v.checkHookConfigs(manifest)
+	v.checkMCPConfigs(manifest)
+	v.checkFileReferences(components)
+
+	return v.issues
+}
+
+// validator accumulates issues while checking a plugin filesystem.
+type validator struct {
+	fsys   fs.FS
+	issues []ValidationIssue
+}
+
+func (v *validator) errorf(p, format string, args ...any) {
+	v.issues = append(v.issues, ValidationIssue{Severity: SeverityError, Path: p, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(p, format string, args ...any) {
+	v.issues = append(v.issues, ValidationIssue{Severity: SeverityWarning, Path: p, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkManifest validates plugin.json and returns it (empty if unreadable).
+func (v *validator) checkManifest() *PluginManifest {
+	manifest, err := ReadPluginManifestFS(v.fsys)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		v.errorf(manifestPath, "missing plugin manifest")
+		return &PluginManifest{}
+	case err != nil:
+		v.errorf(manifestPath, "invalid JSON: %v", err)
+		return &PluginManifest{}
+	}
+
+	if manifest.Name == "" {
+		v.errorf(manifestPath, "name is empty")
+	}
+	if manifest.Version != "" && !semverPattern.MatchString(manifest.Version) {
+		v.errorf(manifestPath, "version %q is not a semantic version (MAJOR.MINOR.PATCH)", manifest.Version)
+	}
+
+	declared := []struct {
+		field string
+		paths []string
+	}{
+		{"commands", manifest.Commands},
+		{"agents", manifest.Agents},
+		{"hooks", manifest.Hooks},
+		{"mcpServers", manifest.MCPServers},
+	}
+	for _, d := range declared {
+		for _, p := range d.paths {
+			if _, statErr := fs.Stat(v.fsys, p); statErr != nil {
+				v.errorf(manifestPath, "%s path %q does not exist", d.field, p)
+			}
+		}
+	}
+
+	return manifest
+}
+
+// checkComponents reports skills, agents, and commands with missing or malformed frontmatter.
+func (v *validator) checkComponents(components *PluginComponents) {
+	for _, skill := range components.Skills {
+		if _, err := fs.Stat(v.fsys, skill.Path); err != nil {
+			v.errorf(skill.Path, "skill %q has no SKILL.md", skill.Name)
+			continue
+		}
+		v.checkFrontmatter(skill, "skill", SeverityError)
+	}
+	for _, agent := range components.Agents {
+		v.checkFrontmatter(agent, "agent", SeverityError)
+	}
+	for _, command := range components.Commands {
+		v.checkFrontmatter(command, "command", SeverityWarning)
+	}
+}
+
+// checkFrontmatter reports a component whose frontmatter is missing, malformed, or lacks a description.
+// Missing frontmatter is reported at the given severity; parse errors are always errors.
+func (v *validator) checkFrontmatter(c Component, kind string, missing Severity) {
+	data, err := fs.ReadFile(v.fsys, c.Path)
+	if err != nil {
+		v.errorf(c.Path, "cannot read %s: %v", kind, err)
+		return
+	}
+
+	_, found, err := parseFrontmatter(data)
+	report := v.warnf
+	if missing == SeverityError {
+		report = v.errorf
+	}
+	switch {
+	case err != nil:
+		v.errorf(c.Path, "malformed frontmatter: %v", err)
+	case !found:
+		report(c.Path, "%s %q has no frontmatter", kind, c.Name)
+	case c.Description == "":
+		report(c.Path, "%s %q frontmatter has no description", kind, c.Name)
+	}
+}
+
+// checkHookConfigs reports hook configs that can't be parsed or declare empty commands.
+func (v *validator) checkHookConfigs(manifest *PluginManifest) {
+	check := func(source string, hooks []Hook, err error) {
+		if err != nil {
+			v.errorf(source, "malformed hooks config: %v", err)
+			return
+		}
+		for _, h := range hooks {
+			if h.Command == "" {
+				v.errorf(source, "%s hook has an empty %s", h.Event, h.Type)
+			}
+		}
+	}
+
+	for _, p := range configPaths("hooks/hooks.json", manifest.Hooks) {
+		if _, err := fs.Stat(v.fsys, p); err != nil {
+			continue // Missing declared paths are reported by checkManifest
+		}
+		hooks, err := readHooksFS(v.fsys, p)
+		check(p, hooks, err)
+	}
+	if len(manifest.InlineHooks) > 0 {
+		hooks, err := parseHooksConfig(manifest.InlineHooks, manifestPath)
+		check(manifestPath, hooks, err)
+	}
+}
+
+// checkMCPConfigs reports MCP configs that can't be parsed or servers with neither command nor URL.
+func (v *validator) checkMCPConfigs(manifest *PluginManifest) {
+	check := func(source string, servers []MCPServer, err error) {
+		if err != nil {
+			v.errorf(source, "malformed MCP config: %v", err)
+			return
+		}
+		for _, s := range servers {
+			if s.Command == "" && s.URL == "" {
+				v.errorf(source, "MCP server %q has neither command nor url", s.Name)
+			}
+		}
+	}
+
+	for _, p := range configPaths(".mcp.json", manifest.MCPServers) {
+		if _, err := fs.Stat(v.fsys, p); err != nil {
+			continue
+		}
+		servers, err := readMCPServersFS(v.fsys, p)
+		check(p, servers, err)
+	}
+	if len(manifest.InlineMCPServers) > 0 {
+		servers, err := parseMCPConfig(manifest.InlineMCPServers, manifestPath)
+		check(manifestPath, servers, err)
+	}
+}
+
+// checkFileReferences reports ${CLAUDE_PLUGIN_ROOT}/... references in hook
+// commands and MCP server launch commands that point at files that don't exist.
+func (v *validator) checkFileReferences(components *PluginComponents) {
+	check := func(source, text string) {
+		for _, m := range pluginRootRefPattern.FindAllStringSubmatch(text, -1) {
+			ref, ok := cleanComponentPath(m[1])
+			if !ok {
+				v.errorf(source, "reference %q escapes the plugin directory", m[0])
+				continue
+			}
+			if _, err := fs.Stat(v.fsys, ref); err != nil {
+				v.errorf(source, "references missing file %s", ref)
+			}
+		}
+	}
+
+	for _, h := range components.Hooks {
+		if h.Type == "command" {
+			check(h.Source, h.Command)
+		}
+	}
+	for _, s := range components.MCPs {
+		check(s.Source, s.Command)
+		for _, arg := range s.Args {
+			check(s.Source, arg)
+		}
+	}
+}
+
+// HasErrors reports whether any issue has error severity.
+func HasErrors(issues []ValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package claude
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// validManifest is a minimal plugin.json that passes validation.
+var validManifest = &fstest.MapFile{Data: []byte(`{"name": "my-plugin", "version": "1.2.3", "description": "A plugin"}`)}
+
+// hasIssue reports whether issues contains one with the given severity, path, and message substring.
+func hasIssue(issues []ValidationIssue, sev Severity, p, substr string) bool {
+	for _, issue := range issues {
+		if issue.Severity == sev && issue.Path == p && strings.Contains(issue.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidatePluginFS(t *testing.T) {
+	t.Run("clean plugin", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": validManifest,
+			"skills/review/SKILL.md":     {Data: []byte("---\nname: review\ndescription: Review code\n---\n")},
+			"agents/helper.md":           {Data: []byte("---\nname: helper\ndescription: Helps\n---\n")},
+			"commands/deploy.md":         {Data: []byte("---\ndescription: Deploy\n---\n")},
+			"hooks/hooks.json": {Data: []byte(`{"hooks": {"PreToolUse": [{"matcher": "Bash",
+				"hooks": [{"type": "command", "command": "${CLAUDE_PLUGIN_ROOT}/scripts/check.sh"}]}]}}`)},
+			"scripts/check.sh": {Data: []byte("#!/bin/sh\n")},
+			".mcp.json":        {Data: []byte(`{"mcpServers": {"db": {"command": "${CLAUDE_PLUGIN_ROOT}/bin/db", "args": ["--port", "5432"]}}}`)},
+			"bin/db":           {Data: []byte("")},
+		}
+
+		issues := ValidatePluginFS(fsys)
+		if len(issues) != 0 {
+			t.Errorf("issues = %+v, want none", issues)
+		}
+	})
+
+	t.Run("missing manifest", func(t *testing.T) {
+		issues := ValidatePluginFS(fstest.MapFS{})
+		if !hasIssue(issues, SeverityError, manifestPath, "missing plugin manifest") {
+			t.Errorf("issues = %+v, want missing manifest error", issues)
+		}
+	})
+
+	t.Run("invalid manifest JSON", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": {Data: []byte(`{"name": `)},
+		}
+		issues := ValidatePluginFS(fsys)
+		if !hasIssue(issues, SeverityError, manifestPath, "invalid JSON") {
+			t.Errorf("issues = %+v, want invalid JSON error", issues)
+		}
+	})
+
+	t.Run("empty name and non-semver version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": {Data: []byte(`{"name": "", "version": "1.0"}`)},
+		}
+		issues := ValidatePluginFS(fsys)
+		if !hasIssue(issues, SeverityError, manifestPath, "name is empty") {
+			t.Errorf("issues = %+v, want empty name error", issues)
+		}
+		if !hasIssue(issues, SeverityError, manifestPath, `version "1.0"`) {
+			t.Errorf("issues = %+v, want semver error", issues)
+		}
+	})
+
+	t.Run("declared path does not exist", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": {Data: []byte(`{"name": "p", "commands": "./extra"}`)},
+		}
+		issues := ValidatePluginFS(fsys)
+		if !hasIssue(issues, SeverityError, manifestPath, `commands path "extra" does not exist`) {
+			t.Errorf("issues = %+v, want missing path error", issues)
+		}
+	})
+
+	t.Run("components without frontmatter", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": validManifest,
+			"agents/helper.md":           {Data: []byte("# Helper\n")},
+			"commands/deploy.md":         {Data: []byte("Deploy the app\n")},
+			"skills/empty/README.md":     {Data: []byte("no skill file")},
+		}
+		issues := ValidatePluginFS(fsys)
+		if !hasIssue(issues, SeverityError, "agents/helper.md", "has no frontmatter") {
+			t.Errorf("issues = %+v, want agent error", issues)
+		}
+		if !hasIssue(issues, SeverityWarning, "commands/deploy.md", "has no frontmatter") {
+			t.Errorf("issues = %+v, want command warning", issues)
+		}
+		if !hasIssue(issues, SeverityError, "skills/empty/SKILL.md", "has no SKILL.md") {
+			t.Errorf("issues = %+v, want missing SKILL.md error", issues)
+		}
+	})
+
+	t.Run("malformed frontmatter", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": validManifest,
+			"commands/deploy.md":         {Data: []byte("---\ndescription: Deploy\n")},
+		}
+		issues := ValidatePluginFS(fsys)
+		if !hasIssue(issues, SeverityError, "commands/deploy.md", "malformed frontmatter") {
+			t.Errorf("issues = %+v, want malformed frontmatter error", issues)
+		}
+	})
+
+	t.Run("malformed hooks and mcp configs", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": validManifest,
+			"hooks/hooks.json":           {Data: []byte(`{"hooks": [`)},
+			".mcp.json":                  {Data: []byte(`{"mcpServers": {"db": {}}}`)},
+		}
+		issues := ValidatePluginFS(fsys)
+		if !hasIssue(issues, SeverityError, "hooks/hooks.json", "malformed hooks config") {
+			t.Errorf("issues = %+v, want hooks error", issues)
+		}
+		if !hasIssue(issues, SeverityError, ".mcp.json", `"db" has neither command nor url`) {
+			t.Errorf("issues = %+v, want mcp error", issues)
+		}
+	})
+
+	t.Run("default config paths declared in manifest", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": {Data: []byte(`{"name": "my-plugin", "version": "1.2.3", "description": "A plugin",
+				"hooks": "./hooks/hooks.json", "mcpServers": [".mcp.json"]}`)},
+			"hooks/hooks.json": {Data: []byte(`{"hooks": [`)},
+			".mcp.json":        {Data: []byte(`{"mcpServers": {"db": {}}}`)},
+		}
+		issues := ValidatePluginFS(fsys)
+		if len(issues) != 2 {
+			t.Errorf("issues = %+v, want one hooks and one mcp error", issues)
+		}
+	})
+
+	t.Run("missing referenced scripts", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			".claude-plugin/plugin.json": validManifest,
+			"hooks/hooks.json": {Data: []byte(`{"hooks": {"Stop": [{"hooks": [
+				{"type": "command", "command": "bash ${CLAUDE_PLUGIN_ROOT}/scripts/missing.sh"},
+				{"type": "command", "command": "${CLAUDE_PLUGIN_ROOT}/../outside.sh"}]}]}}`)},
+			".mcp.json": {Data: []byte(`{"mcpServers": {"db": {"command": "node", "args": ["${CLAUDE_PLUGIN_ROOT}/server.js"]}}}`)},
+		}
+		issues := ValidatePluginFS(fsys)
+		if !hasIssue(issues, SeverityError, "hooks/hooks.json", "missing file scripts/missing.sh") {
+			t.Errorf("issues = %+v, want missing hook script error", issues)
+		}
+		if !hasIssue(issues, SeverityError, "hooks/hooks.json", "escapes the plugin directory") {
+			t.Errorf("issues = %+v, want escaping reference error", issues)
+		}
+		if !hasIssue(issues, SeverityError, ".mcp.json", "missing file server.js") {
+			t.Errorf("issues = %+v, want missing mcp script error", issues)
+		}
+	})
+}
+
+func TestSemverPattern(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"1.0.0", true},
+		{"0.1.2-beta.1", true},
+		{"2.0.0+build.5", true},
+		{"1.0", false},
+		{"v1.0.0", false},
+		{"01.0.0", false},
+		{"latest", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := semverPattern.MatchString(tt.version); got != tt.want {
+				t.Errorf("semverPattern.MatchString(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if HasErrors(nil) {
+		t.Error("HasErrors(nil) = true, want false")
+	}
+	if HasErrors([]ValidationIssue{{Severity: SeverityWarning}}) {
+		t.Error("HasErrors(warnings only) = true, want false")
+	}
+	if !HasErrors([]ValidationIssue{{Severity: SeverityWarning}, {Severity: SeverityError}}) {
+		t.Error("HasErrors(with error) = false, want true")
+	}
+}
@@ -0,0 +1,92 @@
+// Package config reads and writes cpm's own preferences, such as which plugin
+// list groups are collapsed and the saved filter views. Claude's settings live
+// in internal/claude; this file only holds state cpm keeps between sessions.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// fileName is the name of the config file within cpm's config directory.
+const fileName = "config.json"
+
+// Config holds cpm's preferences.
+type Config struct {
+	// ExtraMarketplaces lists the extraKnownMarketplaces entries added in cpm's
+	// editor, by settings file path. Syncing never removes them.
+	ExtraMarketplaces map[string][]string `json:"extraMarketplaces,omitempty"`
+	ActiveView        string              `json:"activeView,omitempty"`      // Name of the view the list shows; "" for all plugins
+	CollapsedGroups   []string            `json:"collapsedGroups,omitempty"` // Names of collapsed plugin list groups
+	Views             []View              `json:"views,omitempty"`
+}
+
+// View is a saved filter query shown as a tab of the plugin list.
+type View struct {
+	Name  string `json:"name"`
+	Query string `json:"query"` // Filter query, as typed after /
+}
+
+// Path returns the config file path: cpm/config.json in the user's config
+// directory, e.g. ~/.config/cpm/config.json on Linux.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cpm", fileName), nil
+}
+
+// Load reads the config file at path. A missing file is an empty config.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is cpm's own config file
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Save writes the config to path atomically, creating its directory if needed.
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".tmp."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpName := f.Name()
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
+}
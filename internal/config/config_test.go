@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadMissing(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "cpm", "config.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.CollapsedGroups) != 0 {
+		t.Errorf("CollapsedGroups = %v, want empty", cfg.CollapsedGroups)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cpm", "config.json")
+	cfg := &Config{
+		CollapsedGroups: []string{"alpha", "Not Installed"},
+		Views:           []View{{Name: "Updates", Query: "is:outdated"}},
+		ActiveView:      "Updates",
+	}
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !slices.Equal(got.CollapsedGroups, cfg.CollapsedGroups) {
+		t.Errorf("CollapsedGroups = %v, want %v", got.CollapsedGroups, cfg.CollapsedGroups)
+	}
+	if !slices.Equal(got.Views, cfg.Views) || got.ActiveView != "Updates" {
+		t.Errorf("Views = %v, ActiveView = %q, want %v, %q", got.Views, got.ActiveView, cfg.Views, "Updates")
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("config dir has %d entries, want only config.json", len(entries))
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "parse") {
+		t.Errorf("Load() error = %v, want parse error", err)
+	}
+}
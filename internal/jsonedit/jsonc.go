@@ -0,0 +1,111 @@
+package jsonedit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Standardize converts JSON with comments and trailing commas (JSONC) to plain
+// JSON by overwriting them with spaces. Newlines are kept, so byte offsets and
+// line numbers in the result match the input.
+func Standardize(data []byte) []byte {
+	out := stripComments(data)
+	lastComma := -1
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '"':
+			i = stringEnd(out, i) - 1
+			lastComma = -1
+		case ',':
+			lastComma = i
+		case '}', ']':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case ' ', '\t', '\r', '\n':
+		default:
+			lastComma = -1
+		}
+	}
+	return out
+}
+
+// stripComments returns a copy of data with comments overwritten by spaces.
+func stripComments(data []byte) []byte {
+	out := slices.Clone(data)
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '"':
+			i = stringEnd(out, i) - 1
+		case '/':
+			if end := commentEnd(out, i); end >= 0 {
+				blank(out, i, end)
+				i = end - 1
+			}
+		}
+	}
+	return out
+}
+
+// stringEnd returns the offset just past the string starting at pos.
+func stringEnd(data []byte, pos int) int {
+	s := &scanner{data: data, pos: pos}
+	_ = s.skipString() // Unterminated strings run to the end
+	return s.pos
+}
+
+// commentEnd returns the offset just past the comment starting at pos,
+// or -1 if there is no comment at pos. Unterminated comments run to the end.
+func commentEnd(data []byte, pos int) int {
+	if pos+1 >= len(data) || data[pos] != '/' {
+		return -1
+	}
+	switch data[pos+1] {
+	case '/':
+		if nl := bytes.IndexByte(data[pos:], '\n'); nl >= 0 {
+			return pos + nl
+		}
+		return len(data)
+	case '*':
+		if end := bytes.Index(data[pos+2:], []byte("*/")); end >= 0 {
+			return pos + 2 + end + 2
+		}
+		return len(data)
+	default:
+		return -1
+	}
+}
+
+// blank overwrites data[start:end] with spaces, keeping line breaks.
+func blank(data []byte, start, end int) {
+	for i := start; i < end; i++ {
+		if data[i] != '\n' && data[i] != '\r' {
+			data[i] = ' '
+		}
+	}
+}
+
+// Unmarshal parses JSONC data into v. Syntax errors report the line and column.
+func Unmarshal(data []byte, v any) error {
+	clean := Standardize(data)
+	err := json.Unmarshal(clean, v)
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, col := position(clean, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %w", line, col, err)
+	}
+	return err
+}
+
+// position converts a byte offset reported by encoding/json to a 1-based line and column.
+func position(data []byte, offset int64) (line, col int) {
+	// SyntaxError.Offset points just past the offending byte
+	pos := max(0, min(int(offset)-1, len(data)))
+	line = 1 + bytes.Count(data[:pos], []byte("\n"))
+	col = pos - bytes.LastIndexByte(data[:pos], '\n')
+	return line, col
+}
@@ -0,0 +1,114 @@
+package jsonedit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStandardize(t *testing.T) {
+	input := "{\n  // line comment\n  \"a\": \"http://x/*y*/\", /* block\n comment */\n  \"b\": [1, 2,],\n}\n"
+	got := Standardize([]byte(input))
+
+	if len(got) != len(input) {
+		t.Fatalf("len = %d, want %d (offsets must be preserved)", len(got), len(input))
+	}
+	if strings.Count(string(got), "\n") != strings.Count(input, "\n") {
+		t.Error("line breaks were not preserved")
+	}
+	if !json.Valid(got) {
+		t.Fatalf("result is not valid JSON:\n%s", got)
+	}
+
+	var v struct {
+		A string `json:"a"`
+		B []int  `json:"b"`
+	}
+	if err := json.Unmarshal(got, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != "http://x/*y*/" || len(v.B) != 2 {
+		t.Errorf("decoded = %+v", v)
+	}
+}
+
+func TestUnmarshalSyntaxErrorPosition(t *testing.T) {
+	var v map[string]any
+	err := Unmarshal([]byte("{\n  // ok\n  \"a\": 1\n  \"b\": 2\n}"), &v)
+	if err == nil {
+		t.Fatal("expected error for missing comma")
+	}
+	if !strings.HasPrefix(err.Error(), "line 4, column 3:") {
+		t.Errorf("err = %q, want line 4, column 3", err)
+	}
+}
+
+func TestEditPreservesComments(t *testing.T) {
+	input := `{
+  // Plugins for this repo
+  "enabledPlugins": {
+    "a@mp": true, // needed for CI
+    // formatter
+    "b@mp": true,
+  },
+  /* extra marketplaces */
+  "extraKnownMarketplaces": {}
+}
+`
+
+	t.Run("set appends after trailing comment", func(t *testing.T) {
+		got, err := Set([]byte(input), []string{"enabledPlugins", "c@mp"}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Replace(input, `"b@mp": true,`, `"b@mp": true,`+"\n    \"c@mp\": false", 1)
+		if string(got) != want {
+			t.Errorf("Set() =\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("set after line comment keeps it with its member", func(t *testing.T) {
+		doc := "{\n  \"a\": 1 // one\n}\n"
+		got, err := Set([]byte(doc), []string{"b"}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "{\n  \"a\": 1, // one\n  \"b\": 2\n}\n"; string(got) != want {
+			t.Errorf("Set() =\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("delete keeps neighbouring comments", func(t *testing.T) {
+		got, err := Delete([]byte(input), []string{"enabledPlugins", "a@mp"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Replace(input, `"a@mp": true, // needed for CI`, `// needed for CI`, 1)
+		if string(got) != want {
+			t.Errorf("Delete() =\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("delete member with trailing comma", func(t *testing.T) {
+		got, err := Delete([]byte(input), []string{"enabledPlugins", "b@mp"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Replace(input, "    \"b@mp\": true,\n", "", 1)
+		if string(got) != want {
+			t.Errorf("Delete() =\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("delete last member keeps preceding comment", func(t *testing.T) {
+		got, err := Delete([]byte(input), []string{"extraKnownMarketplaces"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Replace(input, "  },\n  /* extra marketplaces */\n  \"extraKnownMarketplaces\": {}\n",
+			"  }\n  /* extra marketplaces */\n", 1)
+		if string(got) != want {
+			t.Errorf("Delete() =\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
@@ -0,0 +1,386 @@
+// Package jsonedit edits JSON documents in place, changing only the bytes of the
+// members being set or removed. Key order, indentation, line endings, comments,
+// and the trailing newline of the rest of the document are left untouched, so
+// edits to committed settings files produce minimal diffs. Documents may use
+// comments and trailing commas (JSONC).
+package jsonedit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrNotObject is returned when a path traverses a value that isn't an object.
+var ErrNotObject = errors.New("not a JSON object")
+
+// defaultIndent is used when the document doesn't show an indentation style.
+const defaultIndent = "  "
+
+// Set sets the member at path to value, creating the member and any missing
+// intermediate objects. New members are appended after existing ones.
+// An empty or whitespace-only document is treated as an empty object.
+func Set(data []byte, path []string, value any) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, errors.New("empty path")
+	}
+	d, err := newDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := d.root
+	for i, key := range path {
+		m, ok := obj.member(key)
+		if !ok {
+			// Build the rest of the path as nested objects under this member
+			for j := len(path) - 1; j > i; j-- {
+				value = map[string]any{path[j]: value}
+			}
+			return d.insert(obj, key, value)
+		}
+		if i == len(path)-1 {
+			return d.replace(m, value)
+		}
+		if obj, err = d.objectAt(m.valueStart); err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.Join(path[:i+1], "."), err)
+		}
+	}
+	return d.data, nil
+}
+
+// Delete removes the member at path. A missing member is not an error.
+func Delete(data []byte, path []string) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, errors.New("empty path")
+	}
+	d, err := newDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := d.root
+	for i, key := range path {
+		m, ok := obj.member(key)
+		if !ok {
+			return d.data, nil
+		}
+		if i == len(path)-1 {
+			return d.remove(obj, key), nil
+		}
+		if obj, err = d.objectAt(m.valueStart); err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.Join(path[:i+1], "."), err)
+		}
+	}
+	return d.data, nil
+}
+
+// member is the location of an object member in a document.
+type member struct {
+	key        string
+	keyStart   int // Offset of the key's opening quote
+	keyEnd     int // Offset just past the key's closing quote
+	valueStart int
+	valueEnd   int // Offset just past the value
+}
+
+// object is the location of an object and its members in a document.
+type object struct {
+	members []member
+	start   int // Offset of '{'
+	end     int // Offset just past '}'
+}
+
+// index returns the index of the member with the given key, or -1.
+// Duplicate keys resolve to the last occurrence, as encoding/json does.
+func (o object) index(key string) int {
+	for i := len(o.members) - 1; i >= 0; i-- {
+		if o.members[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o object) member(key string) (member, bool) {
+	if i := o.index(key); i >= 0 {
+		return o.members[i], true
+	}
+	return member{}, false
+}
+
+// document is a JSON document with its detected formatting style.
+type document struct {
+	data      []byte
+	clean     []byte // data with comments blanked; same offsets
+	newline   string // "\n" or "\r\n"
+	indent    string // One level of indentation
+	colon     string // Key/value separator, e.g. ": "
+	comma     string // Member separator on a single line, e.g. ", "
+	root      object
+	multiline bool
+}
+
+func newDocument(data []byte) (*document, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("{}\n")
+	}
+	if !json.Valid(Standardize(data)) {
+		return nil, errors.New("invalid JSON")
+	}
+
+	d := &document{data: data, clean: stripComments(data), newline: "\n", indent: defaultIndent, colon: ": ", comma: ", ", multiline: true}
+	start := len(data) - len(bytes.TrimLeft(data, " \t\r\n"))
+	root, err := d.objectAt(start)
+	if err != nil {
+		return nil, err
+	}
+	d.root = root
+	d.detectStyle()
+	return d, nil
+}
+
+// detectStyle infers line endings, indentation, and separators from the root object.
+func (d *document) detectStyle() {
+	if bytes.Contains(d.data, []byte("\r\n")) {
+		d.newline = "\r\n"
+	}
+	if len(d.root.members) == 0 {
+		return
+	}
+
+	first := d.root.members[0]
+	d.colon = string(d.clean[first.keyEnd:first.valueStart])
+	if !strings.HasSuffix(d.colon, " ") {
+		d.comma = ","
+	}
+	indent, ownLine := d.lineIndent(first.keyStart)
+	d.multiline = ownLine
+	if ownLine && indent != "" {
+		d.indent = indent
+	}
+}
+
+// lineIndent returns the leading whitespace of the line containing pos, and
+// whether everything before pos on that line is whitespace.
+func (d *document) lineIndent(pos int) (string, bool) {
+	lineStart := bytes.LastIndexByte(d.data[:pos], '\n') + 1
+	prefix := d.data[lineStart:pos]
+	trimmed := bytes.TrimLeft(prefix, " \t")
+	return string(prefix[:len(prefix)-len(trimmed)]), len(trimmed) == 0
+}
+
+// format renders value for a member whose line is indented by indent.
+// Values of members that share a line with other members are kept on one line.
+func (d *document) format(value any, indent string, multiline bool) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	raw := bytes.TrimRight(buf.Bytes(), "\n")
+	if !multiline {
+		return raw, nil
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, indent, d.indent); err != nil {
+		return nil, err
+	}
+	if d.newline != "\n" {
+		return bytes.ReplaceAll(out.Bytes(), []byte("\n"), []byte(d.newline)), nil
+	}
+	return out.Bytes(), nil
+}
+
+// splice returns the document with data[start:end] replaced by text.
+func (d *document) splice(start, end int, text ...[]byte) []byte {
+	return d.apply(edit{start: start, end: end, text: slices.Concat(text...)})
+}
+
+// edit replaces data[start:end] with text.
+type edit struct {
+	text       []byte
+	start, end int
+}
+
+// apply returns the document with the given non-overlapping edits applied.
+func (d *document) apply(edits ...edit) []byte {
+	slices.SortFunc(edits, func(a, b edit) int { return a.start - b.start })
+	var out []byte
+	pos := 0
+	for _, e := range edits {
+		out = append(out, d.data[pos:e.start]...)
+		out = append(out, e.text...)
+		pos = e.end
+	}
+	return append(out, d.data[pos:]...)
+}
+
+// isBlank reports whether data[start:end] is only whitespace. Comments are not blank.
+func (d *document) isBlank(start, end int) bool {
+	return start >= end || len(bytes.TrimLeft(d.data[start:end], " \t\r\n")) == 0
+}
+
+// commaAfter returns the offset of the comma following the value ending at pos,
+// skipping whitespace and comments, or -1 if there is none.
+func (d *document) commaAfter(pos int) int {
+	i := pos + len(d.clean[pos:]) - len(bytes.TrimLeft(d.clean[pos:], " \t\r\n"))
+	if i < len(d.data) && d.data[i] == ',' {
+		return i
+	}
+	return -1
+}
+
+// ownLines returns the span of whole lines covering data[start:end] when
+// nothing else shares those lines, including the final line break.
+func (d *document) ownLines(start, end int) (lineStart, lineEnd int, ok bool) {
+	lineStart = bytes.LastIndexByte(d.data[:start], '\n') + 1
+	nl := bytes.IndexByte(d.data[end:], '\n')
+	if nl < 0 || !d.isBlank(lineStart, start) || !d.isBlank(end, end+nl) {
+		return 0, 0, false
+	}
+	return lineStart, end + nl + 1, true
+}
+
+func (d *document) replace(m member, value any) ([]byte, error) {
+	indent, ownLine := d.lineIndent(m.keyStart)
+	formatted, err := d.format(value, indent, ownLine)
+	if err != nil {
+		return nil, err
+	}
+	return d.splice(m.valueStart, m.valueEnd, formatted), nil
+}
+
+// insert appends a new member to obj.
+func (d *document) insert(obj object, key string, value any) ([]byte, error) {
+	parentIndent, _ := d.lineIndent(obj.start)
+	indent, multiline := parentIndent+d.indent, d.multiline
+	n := len(obj.members)
+	if n > 0 {
+		// Follow the layout of the existing members
+		indent, multiline = d.lineIndent(obj.members[n-1].keyStart)
+	}
+
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := d.format(value, indent, multiline)
+	if err != nil {
+		return nil, err
+	}
+	entry := slices.Concat(keyJSON, []byte(d.colon), formatted)
+
+	if n > 0 {
+		return d.append(obj, entry, indent, multiline), nil
+	}
+
+	// Empty object: replace whatever whitespace is between the braces
+	inner := edit{start: obj.start + 1, end: obj.end - 1}
+	hasComments := !d.isBlank(inner.start, inner.end)
+	if hasComments {
+		inner.end = inner.start
+	}
+	if !multiline {
+		inner.text = entry
+		return d.apply(inner), nil
+	}
+	inner.text = slices.Concat([]byte(d.newline+indent), entry)
+	if !hasComments {
+		inner.text = append(inner.text, d.newline+parentIndent...)
+	}
+	return d.apply(inner), nil
+}
+
+// append adds entry after the last member of a non-empty object. A comment
+// following the last member on its line stays with that member.
+func (d *document) append(obj object, entry []byte, indent string, multiline bool) []byte {
+	last := obj.members[len(obj.members)-1]
+	at := last.valueEnd
+	comma := edit{start: at, end: at, text: []byte(",")}
+	if c := d.commaAfter(last.valueEnd); c >= 0 {
+		// Trailing comma already present
+		at = c + 1
+		comma.start, comma.end, comma.text = at, at, nil
+	}
+
+	if !multiline {
+		sep := d.comma
+		if len(obj.members) > 1 {
+			sep = string(d.data[obj.members[0].valueEnd:obj.members[1].keyStart])
+		}
+		if comma.text == nil {
+			sep = strings.TrimPrefix(sep, ",")
+		} else {
+			comma.text = nil
+		}
+		return d.apply(edit{start: at, end: at, text: slices.Concat([]byte(sep), entry)})
+	}
+
+	// Insert at the end of the last member's line, before the closing brace's line
+	insertAt := at
+	if nl := bytes.IndexByte(d.data[at:obj.end-1], '\n'); nl >= 0 {
+		insertAt = at + nl
+		if insertAt > at && d.data[insertAt-1] == '\r' {
+			insertAt--
+		}
+	}
+	text := slices.Concat([]byte(d.newline+indent), entry)
+	if insertAt == comma.start {
+		return d.apply(edit{start: at, end: at, text: slices.Concat(comma.text, text)})
+	}
+	return d.apply(comma, edit{start: insertAt, end: insertAt, text: text})
+}
+
+// remove deletes the member with the given key from obj, along with the comma
+// and whitespace that separate it from its neighbours. Comments are kept.
+func (d *document) remove(obj object, key string) []byte {
+	i := obj.index(key)
+	m := obj.members[i]
+
+	end := m.valueEnd
+	comma := d.commaAfter(m.valueEnd)
+	if comma >= 0 && d.isBlank(m.valueEnd, comma) {
+		end = comma + 1
+	}
+	if len(obj.members) == 1 && d.isBlank(obj.start+1, m.keyStart) && d.isBlank(end, obj.end-1) {
+		return d.splice(obj.start+1, obj.end-1)
+	}
+
+	var edits []edit
+	switch {
+	case comma >= 0 && end <= comma:
+		// Comment between the value and its comma: remove the comma on its own
+		edits = append(edits, edit{start: comma, end: comma + 1})
+	case comma < 0 && i > 0:
+		// Last member: the previous member's comma goes instead
+		prev := obj.members[i-1]
+		prevComma := d.commaAfter(prev.valueEnd)
+		if d.isBlank(prev.valueEnd, m.keyStart) {
+			if _, _, ok := d.ownLines(m.keyStart, end); !ok {
+				return d.splice(prev.valueEnd, end)
+			}
+		}
+		edits = append(edits, edit{start: prevComma, end: prevComma + 1})
+	}
+
+	member := edit{start: m.keyStart, end: end}
+	if start, stop, ok := d.ownLines(m.keyStart, end); ok {
+		member.start, member.end = start, stop
+	} else if comma >= 0 {
+		// Same-line neighbours: also drop the space before the next member
+		member.end += len(d.data[end:]) - len(bytes.TrimLeft(d.data[end:], " \t"))
+	}
+	return d.apply(append(edits, member)...)
+}
+
+// objectAt scans the object starting at pos.
+func (d *document) objectAt(pos int) (object, error) {
+	s := &scanner{data: d.clean, pos: pos}
+	return s.object()
+}
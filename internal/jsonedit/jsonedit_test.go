@@ -0,0 +1,173 @@
+package jsonedit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		value any
+		want  string
+		path  []string
+	}{
+		{
+			name:  "replace top-level value keeps key order",
+			input: "{\n  \"model\": \"opus\",\n  \"enabledPlugins\": {},\n  \"alwaysThinkingEnabled\": true\n}\n",
+			path:  []string{"model"},
+			value: "sonnet",
+			want:  "{\n  \"model\": \"sonnet\",\n  \"enabledPlugins\": {},\n  \"alwaysThinkingEnabled\": true\n}\n",
+		},
+		{
+			name:  "insert into empty nested object",
+			input: "{\n  \"enabledPlugins\": {},\n  \"model\": \"opus\"\n}\n",
+			path:  []string{"enabledPlugins", "a@mp"},
+			value: true,
+			want:  "{\n  \"enabledPlugins\": {\n    \"a@mp\": true\n  },\n  \"model\": \"opus\"\n}\n",
+		},
+		{
+			name:  "append to nested object",
+			input: "{\n    \"enabledPlugins\": {\n        \"b@mp\": true\n    }\n}",
+			path:  []string{"enabledPlugins", "a@mp"},
+			value: false,
+			want:  "{\n    \"enabledPlugins\": {\n        \"b@mp\": true,\n        \"a@mp\": false\n    }\n}",
+		},
+		{
+			name:  "create intermediate objects",
+			input: "{\n\t\"model\": \"opus\"\n}\n",
+			path:  []string{"extraKnownMarketplaces", "mp", "source"},
+			value: map[string]string{"source": "github", "repo": "o/r"},
+			want: "{\n\t\"model\": \"opus\",\n\t\"extraKnownMarketplaces\": {\n\t\t\"mp\": {\n\t\t\t\"source\": {\n" +
+				"\t\t\t\t\"repo\": \"o/r\",\n\t\t\t\t\"source\": \"github\"\n\t\t\t}\n\t\t}\n\t}\n}\n",
+		},
+		{
+			name:  "compact document stays compact",
+			input: `{"model":"opus","enabledPlugins":{"b@mp":true}}`,
+			path:  []string{"enabledPlugins", "a@mp"},
+			value: true,
+			want:  `{"model":"opus","enabledPlugins":{"b@mp":true,"a@mp":true}}`,
+		},
+		{
+			name:  "single-line nested object in multiline document",
+			input: "{\n  \"enabledPlugins\": {\"b@mp\": true, \"c@mp\": true}\n}\n",
+			path:  []string{"enabledPlugins", "a@mp"},
+			value: true,
+			want:  "{\n  \"enabledPlugins\": {\"b@mp\": true, \"c@mp\": true, \"a@mp\": true}\n}\n",
+		},
+		{
+			name:  "CRLF line endings",
+			input: "{\r\n  \"model\": \"opus\"\r\n}\r\n",
+			path:  []string{"enabledPlugins", "a@mp"},
+			value: true,
+			want:  "{\r\n  \"model\": \"opus\",\r\n  \"enabledPlugins\": {\r\n    \"a@mp\": true\r\n  }\r\n}\r\n",
+		},
+		{
+			name:  "empty document",
+			input: "",
+			path:  []string{"enabledPlugins", "a@mp"},
+			value: true,
+			want:  "{\n  \"enabledPlugins\": {\n    \"a@mp\": true\n  }\n}\n",
+		},
+		{
+			name:  "no trailing newline is preserved",
+			input: "{\n  \"a\": 1\n}",
+			path:  []string{"b"},
+			value: 2,
+			want:  "{\n  \"a\": 1,\n  \"b\": 2\n}",
+		},
+		{
+			name:  "strings containing braces",
+			input: "{\n  \"a\": \"}{\\\"\",\n  \"b\": {\"x\": [1, {\"y\": \"]\"}]}\n}\n",
+			path:  []string{"b", "x"},
+			value: 2,
+			want:  "{\n  \"a\": \"}{\\\"\",\n  \"b\": {\"x\": 2}\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Set([]byte(tt.input), tt.path, tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Set() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	if _, err := Set([]byte(`{"a": `), []string{"a"}, 1); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+	if _, err := Set([]byte(`[]`), []string{"a"}, 1); !errors.Is(err, ErrNotObject) {
+		t.Errorf("root array: err = %v, want ErrNotObject", err)
+	}
+	if _, err := Set([]byte(`{"a": "x"}`), []string{"a", "b"}, 1); !errors.Is(err, ErrNotObject) {
+		t.Errorf("string parent: err = %v, want ErrNotObject", err)
+	}
+	if _, err := Set([]byte(`{}`), nil, 1); err == nil {
+		t.Error("expected error for empty path")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		path  []string
+	}{
+		{
+			name:  "first member",
+			input: "{\n  \"a\": 1,\n  \"b\": 2,\n  \"c\": 3\n}\n",
+			path:  []string{"a"},
+			want:  "{\n  \"b\": 2,\n  \"c\": 3\n}\n",
+		},
+		{
+			name:  "middle member",
+			input: "{\n  \"a\": 1,\n  \"b\": {\n    \"x\": true\n  },\n  \"c\": 3\n}\n",
+			path:  []string{"b"},
+			want:  "{\n  \"a\": 1,\n  \"c\": 3\n}\n",
+		},
+		{
+			name:  "last member",
+			input: "{\n  \"a\": 1,\n  \"b\": 2\n}\n",
+			path:  []string{"b"},
+			want:  "{\n  \"a\": 1\n}\n",
+		},
+		{
+			name:  "only member of nested object",
+			input: "{\n  \"enabledPlugins\": {\n    \"a@mp\": true\n  }\n}\n",
+			path:  []string{"enabledPlugins", "a@mp"},
+			want:  "{\n  \"enabledPlugins\": {}\n}\n",
+		},
+		{
+			name:  "compact",
+			input: `{"a":1,"b":2,"c":3}`,
+			path:  []string{"b"},
+			want:  `{"a":1,"c":3}`,
+		},
+		{
+			name:  "missing member is a no-op",
+			input: "{\n  \"a\": 1\n}\n",
+			path:  []string{"enabledPlugins", "x@mp"},
+			want:  "{\n  \"a\": 1\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Delete([]byte(tt.input), tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Delete() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
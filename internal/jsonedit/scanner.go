@@ -0,0 +1,157 @@
+package jsonedit
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// scanner records the offsets of object members. Documents are checked with
+// json.Valid before scanning, so it only needs to find value boundaries.
+type scanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *scanner) errorf(format string, args ...any) error {
+	return fmt.Errorf("offset %d: "+format, append([]any{s.pos}, args...)...)
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\r', '\n':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the byte at the current position, or 0 at the end of input.
+func (s *scanner) peek() byte {
+	if s.pos >= len(s.data) {
+		return 0
+	}
+	return s.data[s.pos]
+}
+
+// object scans an object starting at '{' and returns its members.
+func (s *scanner) object() (object, error) {
+	obj := object{start: s.pos}
+	if s.peek() != '{' {
+		return obj, s.errorf("%w", ErrNotObject)
+	}
+	s.pos++
+
+	for {
+		s.skipSpace()
+		switch s.peek() {
+		case '}':
+			s.pos++
+			obj.end = s.pos
+			return obj, nil
+		case ',':
+			s.pos++
+			continue
+		case '"':
+		default:
+			return obj, s.errorf("unexpected %q in object", s.peek())
+		}
+
+		m, err := s.member()
+		if err != nil {
+			return obj, err
+		}
+		obj.members = append(obj.members, m)
+	}
+}
+
+// member scans a "key": value pair starting at the key's opening quote.
+func (s *scanner) member() (member, error) {
+	m := member{keyStart: s.pos}
+	if err := s.skipString(); err != nil {
+		return m, err
+	}
+	m.keyEnd = s.pos
+	if err := json.Unmarshal(s.data[m.keyStart:m.keyEnd], &m.key); err != nil {
+		return m, s.errorf("invalid key: %w", err)
+	}
+
+	s.skipSpace()
+	if s.peek() != ':' {
+		return m, s.errorf("expected ':' after key %q", m.key)
+	}
+	s.pos++
+	s.skipSpace()
+
+	m.valueStart = s.pos
+	if err := s.skipValue(); err != nil {
+		return m, err
+	}
+	m.valueEnd = s.pos
+	return m, nil
+}
+
+// skipValue advances past the value at the current position.
+func (s *scanner) skipValue() error {
+	switch s.peek() {
+	case '"':
+		return s.skipString()
+	case '{', '[':
+		return s.skipComposite()
+	case 0:
+		return s.errorf("unexpected end of input")
+	}
+
+	// Number or literal: runs until a delimiter
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ',', '}', ']', ' ', '\t', '\r', '\n':
+			return nil
+		}
+		s.pos++
+	}
+	if s.pos == start {
+		return s.errorf("expected value")
+	}
+	return nil
+}
+
+// skipString advances past a string starting at its opening quote.
+func (s *scanner) skipString() error {
+	for s.pos++; s.pos < len(s.data); s.pos++ {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos++
+		case '"':
+			s.pos++
+			return nil
+		}
+	}
+	return s.errorf("unterminated string")
+}
+
+// skipComposite advances past an object or array, including nested values.
+func (s *scanner) skipComposite() error {
+	depth := 0
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '"':
+			if err := s.skipString(); err != nil {
+				return err
+			}
+			continue
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				s.pos++
+				return nil
+			}
+		}
+		s.pos++
+	}
+	return s.errorf("unterminated object or array")
+}
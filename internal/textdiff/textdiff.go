@@ -0,0 +1,170 @@
+// Package textdiff produces line-based unified diffs, as shown by diff -u and
+// git diff, for previewing edits to small text files such as settings files.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context is the number of unchanged lines shown around each change.
+const Context = 3
+
+// Unified returns a unified diff from before to after, labelling the files
+// oldName and newName. Empty contents are treated as a missing file, labelled
+// /dev/null. It returns "" when the contents are equal.
+func Unified(oldName, newName, before, after string) string {
+	if before == after {
+		return ""
+	}
+	if before == "" {
+		oldName = "/dev/null"
+	}
+	if after == "" {
+		newName = "/dev/null"
+	}
+
+	a, b := splitLines(before), splitLines(after)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(diffLines(a, b)) {
+		sb.WriteString(h)
+	}
+	return sb.String()
+}
+
+// splitLines splits text into lines without their line endings.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// edit is one line of an edit script: kept (' '), removed ('-'), or added ('+').
+type edit struct {
+	line string
+	op   byte
+}
+
+// diffLines returns an edit script turning a into b, using the longest common
+// subsequence of lines. Settings files are small, so the quadratic table is fine.
+func diffLines(a, b []string) []edit {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, edit{line: a[i], op: ' '})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{line: a[i], op: '-'})
+			i++
+		default:
+			edits = append(edits, edit{line: b[j], op: '+'})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, edit{line: a[i], op: '-'})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, edit{line: b[j], op: '+'})
+	}
+	return edits
+}
+
+// hunks groups an edit script into @@ hunks with Context lines of context,
+// merging changes whose context would overlap.
+func hunks(edits []edit) []string {
+	var result []string
+	oldLine, newLine := 1, 1 // Line numbers at edits[start]
+	for start := 0; start < len(edits); {
+		first := nextChange(edits, start)
+		if first == len(edits) {
+			break
+		}
+
+		// Advance line numbers over the unchanged lines before the hunk
+		from := max(first-Context, start)
+		oldLine += from - start
+		newLine += from - start
+
+		end := hunkEnd(edits, first)
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, e := range edits[from:end] {
+			body.WriteByte(e.op)
+			body.WriteString(e.line)
+			body.WriteByte('\n')
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+		result = append(result, fmt.Sprintf("@@ -%s +%s @@\n%s",
+			hunkRange(oldLine, oldCount), hunkRange(newLine, newCount), body.String()))
+
+		oldLine += oldCount
+		newLine += newCount
+		start = end
+	}
+	return result
+}
+
+// nextChange returns the index of the first added or removed line at or after i.
+func nextChange(edits []edit, i int) int {
+	for i < len(edits) && edits[i].op == ' ' {
+		i++
+	}
+	return i
+}
+
+// hunkEnd returns the end of the hunk containing the change at first: the
+// trailing context after the last change that is within 2*Context unchanged
+// lines of the one before it.
+func hunkEnd(edits []edit, first int) int {
+	last := first
+	for i := first + 1; i < len(edits); i++ {
+		if edits[i].op == ' ' {
+			continue
+		}
+		if i-last-1 > 2*Context {
+			break
+		}
+		last = i
+	}
+	return min(last+1+Context, len(edits))
+}
+
+// hunkRange formats a hunk's start line and length. An empty range starts at
+// the line before it, as in diff -u.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	default:
+		return fmt.Sprintf("%d,%d", start, count)
+	}
+}
@@ -0,0 +1,91 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "equal",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "changed line",
+			before: "{\n  \"a\": true\n}\n",
+			after:  "{\n  \"a\": false\n}\n",
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n" +
+				" {\n-  \"a\": true\n+  \"a\": false\n }\n",
+		},
+		{
+			name:   "new file",
+			before: "",
+			after:  "{}\n",
+			want:   "--- /dev/null\n+++ new\n@@ -0,0 +1 @@\n+{}\n",
+		},
+		{
+			name:   "deleted file",
+			before: "x\ny\n",
+			after:  "",
+			want:   "--- old\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-x\n-y\n",
+		},
+		{
+			name:   "context is trimmed",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			after:  "1\n2\n3\n4\n5\n6\n7\nX\n",
+			want:   "--- old\n+++ new\n@@ -5,4 +5,4 @@\n 5\n 6\n 7\n-8\n+X\n",
+		},
+		{
+			name:   "CRLF matches LF",
+			before: "a\r\nb\r\n",
+			after:  "a\nc\n",
+			want:   "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.before, tt.after); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedSeparateHunks(t *testing.T) {
+	var lines []string
+	for i := range 20 {
+		lines = append(lines, string(rune('a'+i)))
+	}
+	before := strings.Join(lines, "\n") + "\n"
+	lines[1] = "B"
+	lines[18] = "S"
+	after := strings.Join(lines, "\n") + "\n"
+
+	got := Unified("old", "new", before, after)
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Fatalf("got %d hunks, want 2:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n") {
+		t.Errorf("first hunk wrong:\n%s", got)
+	}
+	if !strings.Contains(got, "@@ -16,5 +16,5 @@\n p\n q\n r\n-s\n+S\n t\n") {
+		t.Errorf("second hunk wrong:\n%s", got)
+	}
+
+	// Changes within 2*Context lines of each other share a hunk
+	lines[18] = "s"
+	lines[7] = "H"
+	after = strings.Join(lines, "\n") + "\n"
+	if n := strings.Count(Unified("old", "new", before, after), "@@ -"); n != 1 {
+		t.Errorf("got %d hunks for nearby changes, want 1", n)
+	}
+}
@@ -0,0 +1,243 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+// addMarketplaceStep is the screen shown by the add-marketplace wizard.
+type addMarketplaceStep int
+
+const (
+	addPickType addMarketplaceStep = iota // Choosing a source type
+	addFields                             // Filling in the type's fields
+)
+
+// addNameKey is the form value holding the name of a marketplace declared in
+// extraKnownMarketplaces.
+const addNameKey = "name"
+
+// addScopes are the scopes a marketplace can be declared at, in cycling order.
+var addScopes = []claude.Scope{claude.ScopeUser, claude.ScopeProject, claude.ScopeLocal}
+
+// addMarketplaceForm holds the state of the add-marketplace wizard.
+type addMarketplaceForm struct {
+	values     map[string]string // Inputs by claude.SourceField key, plus addNameKey
+	message    string            // Validation error
+	scope      claude.Scope
+	typeCursor int
+	field      int // Row being edited: 0 is the scope, then formFields()
+	step       addMarketplaceStep
+}
+
+// sourceType returns the chosen source type.
+func (f *addMarketplaceForm) sourceType() string {
+	return claude.SourceTypes[f.typeCursor]
+}
+
+// formFields returns the text inputs for the chosen type. Sources the CLI
+// can't add are declared in settings, which needs a name.
+func (f *addMarketplaceForm) formFields() []claude.SourceField {
+	fields := claude.SourceFormFields(f.sourceType())
+	if !claude.CanAddWithCLI(f.sourceType(), f.values) {
+		fields = append(fields, claude.SourceField{Key: addNameKey, Label: "Name", Hint: "defaults to the repo or package name"})
+	}
+	return fields
+}
+
+// openAddMarketplace starts the add-marketplace wizard.
+func (m *Model) openAddMarketplace() {
+	m.marketplaces.adding = &addMarketplaceForm{values: map[string]string{}, scope: claude.ScopeUser}
+	m.marketplaces.message = ""
+}
+
+// handleAddMarketplaceKey handles keys in the add-marketplace wizard.
+func (m *Model) handleAddMarketplaceKey(msg tea.KeyMsg) tea.Cmd {
+	form := m.marketplaces.adding
+	if form.step == addPickType {
+		switch {
+		case matchesKey(msg, m.keys.Escape):
+			m.marketplaces.adding = nil
+		case matchesKey(msg, m.keys.Up):
+			form.typeCursor = max(form.typeCursor-1, 0)
+		case matchesKey(msg, m.keys.Down):
+			form.typeCursor = min(form.typeCursor+1, len(claude.SourceTypes)-1)
+		case matchesKey(msg, m.keys.Enter):
+			form.values = map[string]string{}
+			form.field = 1
+			form.step = addFields
+		}
+		return nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		form.message = ""
+		form.step = addPickType
+	case tea.KeyTab, tea.KeyDown:
+		form.field = (form.field + 1) % (len(form.formFields()) + 1)
+	case tea.KeyShiftTab, tea.KeyUp:
+		form.field = (form.field + len(form.formFields())) % (len(form.formFields()) + 1)
+	case tea.KeyEnter:
+		return m.submitAddMarketplace()
+	default:
+		form.editField(msg)
+	}
+	return nil
+}
+
+// editField applies a key to the focused row: cycling the scope, or typing
+// in a text input.
+func (f *addMarketplaceForm) editField(msg tea.KeyMsg) {
+	if f.field == 0 {
+		if msg.Type == tea.KeyLeft || msg.Type == tea.KeyRight || msg.Type == tea.KeySpace {
+			f.scope = nextAddScope(f.scope)
+		}
+		return
+	}
+
+	fields := f.formFields()
+	if f.field > len(fields) {
+		return
+	}
+	key := fields[f.field-1].Key
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if runes := []rune(f.values[key]); len(runes) > 0 {
+			f.values[key] = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		f.values[key] += string(msg.Runes)
+	}
+	// Adding a path or headers can add the name input; removing them drops it
+	f.field = min(f.field, len(f.formFields()))
+}
+
+// nextAddScope returns the scope after scope in addScopes.
+func nextAddScope(scope claude.Scope) claude.Scope {
+	for i, s := range addScopes {
+		if s == scope {
+			return addScopes[(i+1)%len(addScopes)]
+		}
+	}
+	return claude.ScopeUser
+}
+
+// addScopeSettingsPath returns the settings file a marketplace added at scope
+// is declared in.
+func (m *Model) addScopeSettingsPath(scope claude.Scope) string {
+	if scope == claude.ScopeUser {
+		return claude.UserSettingsPath()
+	}
+	return claude.SettingsPathForScope(m.workingDir, scope)
+}
+
+// submitAddMarketplace validates the form and adds the marketplace with the
+// CLI, or declares it in extraKnownMarketplaces when the CLI can't express
+// the source. Either way the plugin list is reloaded.
+func (m *Model) submitAddMarketplace() tea.Cmd {
+	form := m.marketplaces.adding
+	src, err := claude.NewSource(form.sourceType(), form.values)
+	if err != nil {
+		form.message = err.Error()
+		return nil
+	}
+
+	scope := form.scope
+	if arg, ok := claude.MarketplaceAddArg(src); ok {
+		m.marketplaces.adding = nil
+		return m.runMarketplaceAction(arg, "add", func(source string) error {
+			return m.client.AddMarketplace(source, scope)
+		})
+	}
+
+	name := cmp.Or(strings.TrimSpace(form.values[addNameKey]), claude.SuggestMarketplaceName(src))
+	if err := claude.ValidateMarketplaceName(name); err != nil {
+		form.message = err.Error()
+		return nil
+	}
+	path := m.addScopeSettingsPath(scope)
+	if err := claude.SetExtraMarketplace(path, name, &claude.MarketplaceEntry{Source: src}); err != nil {
+		form.message = fmt.Sprintf("Couldn't update %s: %v", displayPath(path, m.workingDir), err)
+		return nil
+	}
+	m.marketplaces.adding = nil
+	m.marketplaces.message = fmt.Sprintf("Declared %s in %s; Claude installs it on next start", name, displayPath(path, m.workingDir))
+	return m.reloadPlugins
+}
+
+// renderAddMarketplace renders the add-marketplace wizard and its help line.
+func (m *Model) renderAddMarketplace(styles Styles) ([]string, string) {
+	form := m.marketplaces.adding
+	if form.step == addPickType {
+		lines := []string{styles.DetailLabel.Render("Source type:"), ""}
+		for i, sourceType := range claude.SourceTypes {
+			var labels []string
+			for _, f := range claude.SourceFormFields(sourceType) {
+				labels = append(labels, f.Label)
+			}
+			line := fmt.Sprintf("%-12s %s", sourceType, styles.Help.Render(strings.Join(labels, ", ")))
+			if i == form.typeCursor {
+				lines = append(lines, styles.Selected.Render("> ")+line)
+			} else {
+				lines = append(lines, "  "+line)
+			}
+		}
+		return lines, "↑↓: navigate • Enter: choose • Esc: cancel"
+	}
+
+	lines := []string{styles.DetailLabel.Render(fmt.Sprintf("Add %s marketplace", form.sourceType())), ""}
+	lines = append(lines, form.renderRow(styles, 0, "Scope", string(form.scope), ""))
+	for i, f := range form.formFields() {
+		label := f.Label
+		if f.Required {
+			label += "*"
+		}
+		lines = append(lines, form.renderRow(styles, i+1, label, form.values[f.Key], f.Hint))
+	}
+	lines = append(lines, "", styles.Help.Render(m.addMarketplaceOutcome()))
+	if form.message != "" {
+		lines = append(lines, "", styles.Pending.Render(form.message))
+	}
+	return lines, "Type to edit • Tab/↑↓: field • ←→/Space: scope • Enter: add • Esc: back"
+}
+
+// renderRow renders one labelled row of the form, showing hint when value is empty.
+func (f *addMarketplaceForm) renderRow(styles Styles, row int, label, value, hint string) string {
+	prefix := "  "
+	if row == f.field {
+		prefix = styles.Selected.Render("> ")
+		if row > 0 {
+			value += "█"
+		}
+	}
+	text := styles.DetailValue.Render(value)
+	if value == "" || value == "█" {
+		text += styles.Help.Render(hint)
+	}
+	return prefix + styles.DetailLabel.Render(fmt.Sprintf("%-9s", label+":")) + " " + text
+}
+
+// addMarketplaceOutcome describes what submitting the form will do.
+func (m *Model) addMarketplaceOutcome() string {
+	form := m.marketplaces.adding
+	if !claude.CanAddWithCLI(form.sourceType(), form.values) {
+		outcome := fmt.Sprintf("Declared in extraKnownMarketplaces of %s; the CLI can't add this source directly",
+			displayPath(m.addScopeSettingsPath(form.scope), m.workingDir))
+		if form.scope != claude.ScopeUser {
+			outcome += ". Syncing removes it again unless a plugin from it is enabled there"
+		}
+		return outcome
+	}
+	src, err := claude.NewSource(form.sourceType(), form.values)
+	if err != nil {
+		return "Runs: claude plugin marketplace add"
+	}
+	arg, _ := claude.MarketplaceAddArg(src)
+	return fmt.Sprintf("Runs: claude plugin marketplace add --scope %s %s", form.scope, arg)
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+func TestAddMarketplaceWithCLI(t *testing.T) {
+	setupKnownMarketplaces(t, `{}`)
+	var gotSource string
+	var gotScope claude.Scope
+	client := &mockClient{addMpFn: func(source string, scope claude.Scope) error {
+		gotSource, gotScope = source, scope
+		return nil
+	}}
+	m := marketplacesTestModel(client)
+
+	sendKeys(m, "M", "a", "enter", "bad")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.marketplaces.adding == nil || !strings.Contains(m.marketplaces.adding.message, "not owner/repo") {
+		t.Fatalf("adding = %+v, want a validation error", m.marketplaces.adding)
+	}
+
+	sendKeys(m, "backspace", "backspace", "backspace", "owner/repo", "tab", "v1")
+	if got := m.addMarketplaceOutcome(); got != "Runs: claude plugin marketplace add --scope user owner/repo#v1" {
+		t.Errorf("outcome = %q", got)
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.marketplaces.adding != nil {
+		t.Fatal("submitting didn't close the form and start the add")
+	}
+	if _, cmd = m.Update(cmd()); cmd == nil {
+		t.Error("adding didn't reload plugins")
+	}
+	if gotSource != "owner/repo#v1" || gotScope != claude.ScopeUser {
+		t.Errorf("AddMarketplace(%q, %q), want (owner/repo#v1, user)", gotSource, gotScope)
+	}
+	if m.marketplaces.message != "Added owner/repo#v1" {
+		t.Errorf("message = %q", m.marketplaces.message)
+	}
+}
+
+func TestAddMarketplaceDeclaredInSettings(t *testing.T) {
+	setupKnownMarketplaces(t, `{}`)
+	client := &mockClient{addMpFn: func(string, claude.Scope) error {
+		t.Error("npm sources shouldn't be added with the CLI")
+		return nil
+	}}
+	m := marketplacesTestModel(client)
+	m.workingDir = t.TempDir()
+
+	// npm is the fourth source type
+	sendKeys(m, "M", "a", "j", "j", "j", "enter", "@acme/market")
+	form := m.marketplaces.adding
+	if form.sourceType() != "npm" || len(form.formFields()) != 2 {
+		t.Fatalf("type = %q, fields = %+v, want npm with a name input", form.sourceType(), form.formFields())
+	}
+
+	// Move up to the scope row and pick project
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if form.scope != claude.ScopeProject {
+		t.Fatalf("scope = %q, want project", form.scope)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.marketplaces.adding != nil {
+		t.Fatalf("submitting failed: %+v", form)
+	}
+	entries, err := claude.ReadExtraMarketplaces(filepath.Join(m.workingDir, ".claude", "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := entries["market"].Source
+	if src == nil || src.SourceType() != "npm" || src.String() != "@acme/market" {
+		t.Errorf("extraKnownMarketplaces = %+v, want market from npm @acme/market", entries)
+	}
+}
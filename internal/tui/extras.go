@@ -0,0 +1,410 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+	"github.com/open-cli-collective/cpm/internal/textdiff"
+)
+
+// extraScopes are the settings files whose extraKnownMarketplaces the editor manages.
+var extraScopes = []claude.Scope{claude.ScopeProject, claude.ScopeLocal}
+
+// extrasStep is the screen shown by the extraKnownMarketplaces editor.
+type extrasStep int
+
+const (
+	extrasList    extrasStep = iota // Entries of every scope
+	extrasAdd                       // Picking a known marketplace to add
+	extrasEdit                      // Editing an entry's ref and path
+	extrasPreview                   // Diff shown before writing
+)
+
+// Edit fields of an extraKnownMarketplaces entry.
+const (
+	extraFieldRef = iota
+	extraFieldPath
+)
+
+// extraRow is one extraKnownMarketplaces entry in a settings file.
+type extraRow struct {
+	entry claude.MarketplaceEntry
+	name  string
+	scope claude.Scope
+}
+
+// extraEdit is a change to one entry; a nil entry removes it.
+type extraEdit struct {
+	entry *claude.MarketplaceEntry
+	name  string
+	scope claude.Scope
+}
+
+// ExtrasState holds state for the extraKnownMarketplaces editor.
+type ExtrasState struct {
+	known     map[string]claude.KnownMarketplace // Marketplaces that can be added
+	pending   extraEdit                          // Edit shown in the preview
+	inputs    [2]string                          // Ref and path being edited
+	diff      string                             // Preview of pending
+	message   string                             // Validation error or result of the last write
+	addScope  claude.Scope
+	rows      []extraRow
+	cursor    int
+	addCursor int
+	field     int // Input being edited (extraFieldRef or extraFieldPath)
+	step      extrasStep
+}
+
+// openExtras opens the extraKnownMarketplaces editor.
+func (m *Model) openExtras() {
+	m.extras = ExtrasState{addScope: claude.ScopeProject}
+	m.loadExtras()
+	m.mode = ModeExtras
+}
+
+// loadExtras reads the entries of the project and local settings files and
+// the known marketplaces that can be added.
+func (m *Model) loadExtras() {
+	var rows []extraRow
+	var problems []string
+	for _, scope := range extraScopes {
+		path := claude.SettingsPathForScope(m.workingDir, scope)
+		entries, err := claude.ReadExtraMarketplaces(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Can't read %s: %v", displayPath(path, m.workingDir), err))
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(entries)) {
+			rows = append(rows, extraRow{entry: entries[name], name: name, scope: scope})
+		}
+	}
+	m.extras.rows = rows
+	m.extras.cursor = min(m.extras.cursor, max(len(rows)-1, 0))
+	if len(problems) > 0 {
+		m.extras.message = strings.Join(problems, " • ")
+	}
+
+	known, err := claude.ReadKnownMarketplaces()
+	if err != nil {
+		known = nil
+	}
+	m.extras.known = known
+}
+
+// addableMarketplaces returns the known marketplaces without an entry in the target scope.
+func (m *Model) addableMarketplaces() []string {
+	var names []string
+	for _, name := range slices.Sorted(maps.Keys(m.extras.known)) {
+		if !slices.ContainsFunc(m.extras.rows, func(r extraRow) bool { return r.name == name && r.scope == m.extras.addScope }) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// updateExtras handles input in the extraKnownMarketplaces editor.
+func (m *Model) updateExtras(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch m.extras.step {
+	case extrasList:
+		m.handleExtrasListKey(keyMsg)
+	case extrasAdd:
+		m.handleExtrasAddKey(keyMsg)
+	case extrasEdit:
+		m.handleExtrasEditKey(keyMsg)
+	case extrasPreview:
+		m.handleExtrasPreviewKey(keyMsg)
+	}
+	return m, nil
+}
+
+// handleExtrasListKey handles keys on the list of entries.
+func (m *Model) handleExtrasListKey(msg tea.KeyMsg) {
+	state := &m.extras
+	switch {
+	case matchesKey(msg, m.keys.Escape), matchesKey(msg, m.keys.Quit), matchesKey(msg, m.keys.Extras):
+		m.mode = ModeMain
+	case matchesKey(msg, m.keys.Up):
+		state.cursor = max(state.cursor-1, 0)
+	case matchesKey(msg, m.keys.Down):
+		state.cursor = max(min(state.cursor+1, len(state.rows)-1), 0)
+	case matchesKey(msg, m.keys.Add):
+		state.message = ""
+		state.addCursor = 0
+		state.step = extrasAdd
+	case matchesKey(msg, m.keys.Enter), matchesKey(msg, m.keys.Edit):
+		m.startExtraEdit()
+	case matchesKey(msg, m.keys.Delete):
+		if state.cursor < len(state.rows) {
+			row := state.rows[state.cursor]
+			m.previewExtra(extraEdit{name: row.name, scope: row.scope})
+		}
+	}
+}
+
+// startExtraEdit opens the ref and path inputs for the selected entry.
+func (m *Model) startExtraEdit() {
+	state := &m.extras
+	if state.cursor >= len(state.rows) {
+		return
+	}
+	src := state.rows[state.cursor].entry.Source
+	hasRef, hasPath := claude.SourceFields(src)
+	if !hasRef && !hasPath {
+		state.message = fmt.Sprintf("%s sources have no ref or path to edit", src.SourceType())
+		return
+	}
+
+	ref, path := claude.SourceRefAndPath(src)
+	state.inputs = [2]string{ref, path}
+	state.field = extraFieldRef
+	if !hasRef {
+		state.field = extraFieldPath
+	}
+	state.message = ""
+	state.step = extrasEdit
+}
+
+// handleExtrasAddKey handles keys while picking a known marketplace to add.
+func (m *Model) handleExtrasAddKey(msg tea.KeyMsg) {
+	state := &m.extras
+	names := m.addableMarketplaces()
+	switch {
+	case matchesKey(msg, m.keys.Escape):
+		state.step = extrasList
+	case matchesKey(msg, m.keys.Up):
+		state.addCursor = max(state.addCursor-1, 0)
+	case matchesKey(msg, m.keys.Down):
+		state.addCursor = max(min(state.addCursor+1, len(names)-1), 0)
+	case matchesKey(msg, m.keys.Toggle):
+		state.addScope = toggleExtraScope(state.addScope)
+		state.addCursor = 0
+	case matchesKey(msg, m.keys.Enter):
+		if state.addCursor < len(names) {
+			name := names[state.addCursor]
+			entry := claude.MarketplaceEntry{Source: state.known[name].Source}
+			m.previewExtra(extraEdit{entry: &entry, name: name, scope: state.addScope})
+		}
+	}
+}
+
+// toggleExtraScope switches between the project and local settings files.
+func toggleExtraScope(scope claude.Scope) claude.Scope {
+	if scope == claude.ScopeProject {
+		return claude.ScopeLocal
+	}
+	return claude.ScopeProject
+}
+
+// handleExtrasEditKey handles typing in the ref and path inputs.
+func (m *Model) handleExtrasEditKey(msg tea.KeyMsg) {
+	state := &m.extras
+	if state.cursor >= len(state.rows) {
+		state.step = extrasList
+		return
+	}
+	row := state.rows[state.cursor]
+	switch msg.Type {
+	case tea.KeyEsc:
+		state.message = ""
+		state.step = extrasList
+	case tea.KeyTab:
+		if hasRef, hasPath := claude.SourceFields(row.entry.Source); hasRef && hasPath {
+			state.field = 1 - state.field
+		}
+	case tea.KeyBackspace:
+		if input := state.inputs[state.field]; input != "" {
+			runes := []rune(input)
+			state.inputs[state.field] = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		state.inputs[state.field] += string(msg.Runes)
+	case tea.KeyEnter:
+		src, err := claude.EditSource(row.entry.Source, state.inputs[extraFieldRef], state.inputs[extraFieldPath])
+		if err != nil {
+			state.message = err.Error()
+			return
+		}
+		m.previewExtra(extraEdit{entry: &claude.MarketplaceEntry{Source: src}, name: row.name, scope: row.scope})
+	}
+}
+
+// handleExtrasPreviewKey writes the previewed edit on Enter.
+func (m *Model) handleExtrasPreviewKey(msg tea.KeyMsg) {
+	state := &m.extras
+	switch {
+	case matchesKey(msg, m.keys.Escape):
+		state.message = ""
+		state.step = extrasList
+	case matchesKey(msg, m.keys.Enter):
+		edit := state.pending
+		path := claude.SettingsPathForScope(m.workingDir, edit.scope)
+		if err := claude.SetExtraMarketplace(path, edit.name, edit.entry); err != nil {
+			state.message = fmt.Sprintf("Couldn't update %s: %v", displayPath(path, m.workingDir), err)
+		} else {
+			m.rememberExtra(path, edit)
+			state.message = fmt.Sprintf("Updated %s in %s", edit.name, displayPath(path, m.workingDir))
+		}
+		state.step = extrasList
+		m.loadExtras()
+	}
+}
+
+// rememberExtra records an entry the edit adds to the settings file at path,
+// so syncing keeps it while no plugin from the marketplace is enabled there,
+// and forgets an entry the edit removes.
+func (m *Model) rememberExtra(path string, edit extraEdit) {
+	added := !slices.ContainsFunc(m.extras.rows, func(r extraRow) bool { return r.name == edit.name && r.scope == edit.scope })
+	names := m.prefs.ExtraMarketplaces[path]
+	switch {
+	case edit.entry == nil:
+		names = slices.DeleteFunc(names, func(n string) bool { return n == edit.name })
+	case added && !slices.Contains(names, edit.name):
+		names = append(names, edit.name)
+	default:
+		return
+	}
+
+	if m.prefs.ExtraMarketplaces == nil {
+		m.prefs.ExtraMarketplaces = make(map[string][]string)
+	}
+	if len(names) == 0 {
+		delete(m.prefs.ExtraMarketplaces, path)
+	} else {
+		m.prefs.ExtraMarketplaces[path] = names
+	}
+	m.savePrefs()
+}
+
+// previewExtra shows the diff an edit makes to its settings file.
+func (m *Model) previewExtra(edit extraEdit) {
+	state := &m.extras
+	path := claude.SettingsPathForScope(m.workingDir, edit.scope)
+	name := displayPath(path, m.workingDir)
+	before, after, err := claude.PreviewExtraMarketplace(path, edit.name, edit.entry)
+	if err != nil {
+		state.message = fmt.Sprintf("Can't preview %s: %v", name, err)
+		return
+	}
+	state.diff = textdiff.Unified(name, name, string(before), string(after))
+	if state.diff == "" {
+		state.message = "No change to " + name
+		state.step = extrasList
+		return
+	}
+	state.pending = edit
+	state.message = ""
+	state.step = extrasPreview
+}
+
+// renderExtras renders the extraKnownMarketplaces editor.
+func (m *Model) renderExtras(styles Styles) string {
+	header := styles.Header.Render(" Extra Marketplaces ")
+
+	var lines []string
+	var help string
+	switch m.extras.step {
+	case extrasList:
+		lines = m.renderExtrasList(styles)
+		help = "↑↓: navigate • a: add • Enter/e: edit ref/path • d: remove • Esc: back"
+	case extrasAdd:
+		lines = m.renderExtrasAdd(styles)
+		help = "↑↓: navigate • Tab: project/local • Enter: preview • Esc: cancel"
+	case extrasEdit:
+		lines = m.renderExtrasEdit(styles)
+		help = "Type to edit • Tab: next field • Enter: preview • Esc: cancel"
+	case extrasPreview:
+		lines = renderSettingsDiffs([]settingsDiff{{diff: m.extras.diff}}, styles, max(m.height-8, 3), max(m.width-8, 20))
+		help = "Enter: write • Esc: cancel"
+	}
+	if m.extras.message != "" {
+		lines = append(lines, "", styles.Pending.Render(m.extras.message))
+	}
+
+	content := lipgloss.NewStyle().
+		Width(max(m.width-4, 0)).
+		Height(max(m.height-4, 1)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+	return lipgloss.JoinVertical(lipgloss.Left, header, content, styles.Help.Render(help))
+}
+
+// renderExtrasList renders the entries grouped by settings file.
+func (m *Model) renderExtrasList(styles Styles) []string {
+	var lines []string
+	for _, scope := range extraScopes {
+		path := displayPath(claude.SettingsPathForScope(m.workingDir, scope), m.workingDir)
+		lines = append(lines, styles.GroupHeader.Render(fmt.Sprintf("%s (%s)", scopeLabel(scope), path)))
+
+		empty := true
+		for i, row := range m.extras.rows {
+			if row.scope != scope {
+				continue
+			}
+			empty = false
+			line := fmt.Sprintf("%-24s %s %s", row.name, row.entry.Source.SourceType(), row.entry.Source.String())
+			if i == m.extras.cursor {
+				lines = append(lines, styles.Selected.Render("> "+line))
+			} else {
+				lines = append(lines, "  "+line)
+			}
+		}
+		if empty {
+			lines = append(lines, styles.Help.Render("  No extra marketplaces"))
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// renderExtrasAdd renders the known marketplaces that can be added.
+func (m *Model) renderExtrasAdd(styles Styles) []string {
+	path := displayPath(claude.SettingsPathForScope(m.workingDir, m.extras.addScope), m.workingDir)
+	lines := []string{styles.DetailLabel.Render(fmt.Sprintf("Add to %s (%s):", scopeLabel(m.extras.addScope), path)), ""}
+
+	names := m.addableMarketplaces()
+	if len(names) == 0 {
+		return append(lines, styles.Help.Render("  Every known marketplace already has an entry"))
+	}
+	for i, name := range names {
+		src := m.extras.known[name].Source
+		line := fmt.Sprintf("%-24s %s %s", name, src.SourceType(), src.String())
+		if i == m.extras.addCursor {
+			lines = append(lines, styles.Selected.Render("> "+line))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
+
+// renderExtrasEdit renders the ref and path inputs.
+func (m *Model) renderExtrasEdit(styles Styles) []string {
+	row := m.extras.rows[m.extras.cursor]
+	lines := []string{
+		styles.DetailLabel.Render(fmt.Sprintf("Edit %s (%s %s)", row.name, row.entry.Source.SourceType(), row.entry.Source.String())),
+		"",
+	}
+	hasRef, hasPath := claude.SourceFields(row.entry.Source)
+	for field, label := range []string{"Ref: ", "Path:"} {
+		if (field == extraFieldRef && !hasRef) || (field == extraFieldPath && !hasPath) {
+			continue
+		}
+		value := m.extras.inputs[field]
+		if field == m.extras.field {
+			value += "█"
+		}
+		lines = append(lines, styles.DetailLabel.Render(label)+" "+styles.DetailValue.Render(value))
+	}
+	return lines
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+// setupKnownMarketplaces points the Claude config directory at a temporary
+// home whose known_marketplaces.json has the given contents.
+func setupKnownMarketplaces(t *testing.T, known string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(claude.ConfigDirEnv, "")
+	pluginsDir := filepath.Join(home, ".claude", "plugins")
+	if err := os.MkdirAll(pluginsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginsDir, "known_marketplaces.json"), []byte(known), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+// sendKeys sends each key to the model as a key press.
+func sendKeys(m *Model, keys ...string) {
+	for _, k := range keys {
+		switch k {
+		case "enter":
+			m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		case "esc":
+			m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		case "tab":
+			m.Update(tea.KeyMsg{Type: tea.KeyTab})
+		case "backspace":
+			m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		default:
+			m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+	}
+}
+
+func TestExtrasAddEditRemove(t *testing.T) {
+	setupKnownMarketplaces(t, `{"mp": {"source": {"source": "github", "repo": "owner/mp"}}}`)
+	work := t.TempDir()
+	projectPath := filepath.Join(work, ".claude", "settings.json")
+
+	m := NewModel(&mockClient{}, work)
+	m.progress.loading = false
+	sendKeys(m, "X")
+	if m.mode != ModeExtras || len(m.extras.rows) != 0 {
+		t.Fatalf("mode = %v, rows = %+v, want an empty editor", m.mode, m.extras.rows)
+	}
+
+	// Add mp to the project settings
+	sendKeys(m, "a", "enter")
+	if m.extras.step != extrasPreview || !strings.Contains(m.extras.diff, `"repo": "owner/mp"`) {
+		t.Fatalf("step = %v, diff = %q, want a preview adding mp", m.extras.step, m.extras.diff)
+	}
+	if _, err := os.Stat(projectPath); !os.IsNotExist(err) {
+		t.Fatal("preview should not write")
+	}
+	sendKeys(m, "enter")
+	if len(m.extras.rows) != 1 || m.extras.rows[0].scope != claude.ScopeProject {
+		t.Fatalf("rows = %+v, want mp in project settings", m.extras.rows)
+	}
+	if got := m.prefs.ExtraMarketplaces[projectPath]; len(got) != 1 || got[0] != "mp" {
+		t.Fatalf("remembered entries = %v, want mp", got)
+	}
+
+	// Pin it to a ref
+	sendKeys(m, "e", "v", "1", "enter")
+	if m.extras.step != extrasPreview || !strings.Contains(m.extras.diff, `"ref": "v1"`) {
+		t.Fatalf("step = %v, diff = %q, want a preview adding the ref", m.extras.step, m.extras.diff)
+	}
+	sendKeys(m, "enter")
+	entries, err := claude.ReadExtraMarketplaces(projectPath)
+	if err != nil || entries["mp"].Source.String() != "owner/mp@v1" {
+		t.Fatalf("entries = %v (%v), want mp pinned to v1", entries, err)
+	}
+
+	// Remove it
+	sendKeys(m, "d", "enter")
+	if data, _ := os.ReadFile(projectPath); strings.Contains(string(data), "extraKnownMarketplaces") {
+		t.Errorf("settings = %s, want the entry removed", data)
+	}
+	if len(m.prefs.ExtraMarketplaces) != 0 {
+		t.Errorf("remembered entries = %v, want none after removing", m.prefs.ExtraMarketplaces)
+	}
+
+	sendKeys(m, "esc")
+	if m.mode != ModeMain {
+		t.Errorf("mode = %v, want ModeMain after Esc", m.mode)
+	}
+}
+
+func TestExtrasAddedEntrySurvivesSync(t *testing.T) {
+	setupKnownMarketplaces(t, `{"mp": {"source": {"source": "github", "repo": "owner/mp"}}}`)
+	work := t.TempDir()
+	projectPath := filepath.Join(work, ".claude", "settings.json")
+
+	m := NewModel(&mockClient{}, work)
+	m.progress.loading = false
+	sendKeys(m, "X", "a", "enter", "enter", "esc")
+
+	// Running an operation in the project scope syncs its settings
+	op := Operation{PluginID: "p@other", Type: OpUninstall, Scopes: []claude.Scope{claude.ScopeProject}}
+	m.main.pendingOps = map[string]Operation{op.PluginID: op}
+	_, cmd := m.startExecution()
+	if _, cmd = m.Update(cmd()); cmd == nil {
+		t.Fatal("no sync command after the last operation")
+	}
+	cmd()
+
+	entries, err := claude.ReadExtraMarketplaces(projectPath)
+	if _, ok := entries["mp"]; err != nil || !ok {
+		t.Errorf("entries = %v (%v), want mp kept after the sync", entries, err)
+	}
+}
+
+func TestExtrasEditValidation(t *testing.T) {
+	setupKnownMarketplaces(t, `{}`)
+	work := t.TempDir()
+	settings := `{"enabledPlugins": {"p@dir": true}, "extraKnownMarketplaces": {"dir": {"source": {"source": "directory", "path": "/src"}}}}`
+	if err := os.MkdirAll(filepath.Join(work, ".claude"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(work, ".claude", "settings.local.json"), []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel(&mockClient{}, work)
+	m.progress.loading = false
+	m.openExtras()
+	if len(m.extras.rows) != 1 {
+		t.Fatalf("rows = %+v, want the local entry", m.extras.rows)
+	}
+
+	sendKeys(m, "enter")
+	if m.extras.field != extraFieldPath || m.extras.inputs[extraFieldPath] != "/src" {
+		t.Fatalf("edit = field %d, inputs %q, want the path prefilled", m.extras.field, m.extras.inputs)
+	}
+	sendKeys(m, "backspace", "backspace", "backspace", "backspace", "enter")
+	if m.extras.step != extrasEdit || !strings.Contains(m.extras.message, "need a path") {
+		t.Errorf("step = %v, message = %q, want a validation error", m.extras.step, m.extras.message)
+	}
+
+	view := m.renderExtras(m.styles)
+	if !strings.Contains(view, "need a path") || !strings.Contains(view, "Path:") {
+		t.Errorf("view should show the input and error:\n%s", view)
+	}
+}
@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// groupStats counts the plugins in a list group by state.
+type groupStats struct {
+	installed int
+	available int // Not installed
+	updates   int
+	disabled  int
+}
+
+// listRows returns the indices into m.plugins of the rows the list shows:
+// the filter matches while filtering, otherwise the plugins of the active
+// view in expanded groups, under the headers of groups with any in view.
+func (m *Model) listRows() []int {
+	if m.filter.active && m.filter.text != "" {
+		return m.filteredIdx
+	}
+	rows := make([]int, 0, len(m.plugins))
+	collapsed := false
+	header := -1 // Header of the current group, until a plugin of it is in view
+	for i := range m.plugins {
+		p := &m.plugins[i]
+		switch {
+		case p.IsGroupHeader:
+			collapsed = m.main.collapsed[p.Name]
+			header = i
+			if m.main.viewIDs == nil {
+				rows = append(rows, i)
+				header = -1
+			}
+			continue
+		case !m.inView(p):
+			continue
+		case header >= 0:
+			rows = append(rows, header)
+			header = -1
+		}
+		if !collapsed {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// isSelectable reports whether the cursor can rest on plugin i. Headers of
+// expanded groups are skipped; a collapsed group is selected by its header.
+func (m *Model) isSelectable(i int) bool {
+	p := m.plugins[i]
+	return !p.IsGroupHeader || m.main.collapsed[p.Name]
+}
+
+// groupHeaderOf returns the index of the header of the group containing
+// plugin i, or -1 if the list has no headers above it.
+func (m *Model) groupHeaderOf(i int) int {
+	for i = min(i, len(m.plugins)-1); i >= 0; i-- {
+		if m.plugins[i].IsGroupHeader {
+			return i
+		}
+	}
+	return -1
+}
+
+// groupMembers returns the indices of the plugins under the header at h.
+func (m *Model) groupMembers(h int) []int {
+	var members []int
+	for i := h + 1; i < len(m.plugins) && !m.plugins[i].IsGroupHeader; i++ {
+		members = append(members, i)
+	}
+	return members
+}
+
+// groupStatsFor counts the plugins of the group named name.
+func (m *Model) groupStatsFor(name string) groupStats {
+	var stats groupStats
+	h := slices.IndexFunc(m.plugins, func(p PluginState) bool { return p.IsGroupHeader && p.Name == name })
+	if h < 0 {
+		return stats
+	}
+	for _, i := range m.groupMembers(h) {
+		p := &m.plugins[i]
+		if !p.IsInstalled() {
+			stats.available++
+			continue
+		}
+		stats.installed++
+		if p.HasUpdate {
+			stats.updates++
+		}
+		if !p.Enabled {
+			stats.disabled++
+		}
+	}
+	return stats
+}
+
+// String summarizes the counts for a group header, leaving out zero update
+// and disabled counts.
+func (s groupStats) String() string {
+	parts := []string{fmt.Sprintf("%d installed", s.installed), fmt.Sprintf("%d available", s.available)}
+	if s.updates > 0 {
+		parts = append(parts, fmt.Sprintf("%d ↑", s.updates))
+	}
+	if s.disabled > 0 {
+		parts = append(parts, fmt.Sprintf("%d disabled", s.disabled))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// toggleGroup collapses or expands the group with its header at h. Collapsing
+// the group holding the selection moves the selection to its header.
+func (m *Model) toggleGroup(h int) {
+	if h < 0 {
+		return
+	}
+	name := m.plugins[h].Name
+	if m.main.collapsed == nil {
+		m.main.collapsed = make(map[string]bool)
+	}
+	if m.main.collapsed[name] {
+		delete(m.main.collapsed, name)
+	} else {
+		m.main.collapsed[name] = true
+		if m.groupHeaderOf(m.selectedIdx) == h {
+			m.selectedIdx = h
+		}
+	}
+	m.ensureVisible()
+	m.savePrefs()
+}
+
+// toggleAllGroups collapses every group, or expands them all when they are
+// already collapsed.
+func (m *Model) toggleAllGroups() {
+	var headers []int
+	allCollapsed := true
+	for i, p := range m.plugins {
+		if p.IsGroupHeader {
+			headers = append(headers, i)
+			allCollapsed = allCollapsed && m.main.collapsed[p.Name]
+		}
+	}
+	if len(headers) == 0 {
+		return
+	}
+	if m.main.collapsed == nil {
+		m.main.collapsed = make(map[string]bool)
+	}
+	for _, h := range headers {
+		if allCollapsed {
+			delete(m.main.collapsed, m.plugins[h].Name)
+		} else {
+			m.main.collapsed[m.plugins[h].Name] = true
+		}
+	}
+	if !allCollapsed {
+		m.selectedIdx = max(m.groupHeaderOf(m.selectedIdx), 0)
+	}
+	m.ensureVisible()
+	m.savePrefs()
+}
+
+// revealSelection expands the group holding the selected plugin if it is
+// collapsed, so the selection is shown.
+func (m *Model) revealSelection() {
+	if m.selectedIdx < 0 || m.selectedIdx >= len(m.plugins) || m.plugins[m.selectedIdx].IsGroupHeader {
+		return
+	}
+	if h := m.groupHeaderOf(m.selectedIdx); h >= 0 && m.main.collapsed[m.plugins[h].Name] {
+		m.toggleGroup(h)
+	}
+}
+
+// toggleGroupSelection bulk-selects every plugin of the active view in the
+// selected plugin's group, or deselects them all when they are already selected.
+func (m *Model) toggleGroupSelection() {
+	h := m.groupHeaderOf(m.selectedIdx)
+	if h < 0 {
+		return
+	}
+	members := slices.DeleteFunc(m.groupMembers(h), func(i int) bool { return !m.inView(&m.plugins[i]) })
+	allSelected := true
+	for _, i := range members {
+		allSelected = allSelected && m.main.bulkSelected[m.plugins[i].ID]
+	}
+	for _, i := range members {
+		if allSelected {
+			delete(m.main.bulkSelected, m.plugins[i].ID)
+		} else {
+			m.main.bulkSelected[m.plugins[i].ID] = true
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFoldGroupNavigation(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+
+	// Folding alpha from inside it moves the selection to its header
+	sendKeys(m, "z")
+	if m.selectedIdx != 0 {
+		t.Fatalf("selectedIdx = %d, want 0 (alpha header)", m.selectedIdx)
+	}
+	if got := len(m.getVisiblePlugins()); got != 3 {
+		t.Errorf("visible rows = %d, want 3 (alpha, beta, b1)", got)
+	}
+
+	// Navigation skips the folded plugins and the expanded beta header
+	sendKeys(m, "j")
+	if m.selectedIdx != 4 {
+		t.Errorf("selectedIdx after down = %d, want 4 (b1@beta)", m.selectedIdx)
+	}
+	sendKeys(m, "k")
+	if m.selectedIdx != 0 {
+		t.Errorf("selectedIdx after up = %d, want 0 (alpha header)", m.selectedIdx)
+	}
+
+	sendKeys(m, "z")
+	if m.main.collapsed["alpha"] || len(m.getVisiblePlugins()) != 5 {
+		t.Errorf("alpha still folded after z on its header")
+	}
+}
+
+func TestFoldAllGroups(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+
+	sendKeys(m, "Z")
+	if !m.main.collapsed["alpha"] || !m.main.collapsed["beta"] {
+		t.Fatalf("collapsed = %v, want alpha and beta", m.main.collapsed)
+	}
+	if got := m.getActualIndex(1); got != 3 {
+		t.Errorf("row 1 = plugin %d, want 3 (beta header)", got)
+	}
+	sendKeys(m, "G")
+	if m.selectedIdx != 3 {
+		t.Errorf("selectedIdx after End = %d, want 3 (beta header)", m.selectedIdx)
+	}
+
+	sendKeys(m, "Z")
+	if len(m.main.collapsed) != 0 {
+		t.Errorf("collapsed = %v after expanding all", m.main.collapsed)
+	}
+}
+
+func TestFoldGroupPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cpm", "config.json")
+	m := marketplacesTestModel(&mockClient{})
+	m.UseConfig(path)
+	sendKeys(m, "z")
+
+	next := marketplacesTestModel(&mockClient{})
+	next.UseConfig(path)
+	if !next.main.collapsed["alpha"] || len(next.main.collapsed) != 1 {
+		t.Errorf("collapsed after reload = %v, want alpha", next.main.collapsed)
+	}
+
+	// A refresh keeps a plugin of a folded group hidden behind its header
+	next.restoreSelection("a2@alpha")
+	if next.selectedIdx != 0 {
+		t.Errorf("selectedIdx = %d, want 0 (alpha header)", next.selectedIdx)
+	}
+}
+
+func TestFoldGroupMouse(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+	m.width = 100
+
+	// Row 3 is the beta header: Y = row + 1 for the pane border
+	click := tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonLeft, X: 5, Y: 4}
+	m.handleMouse(click)
+	if !m.main.collapsed["beta"] {
+		t.Fatal("clicking the beta header didn't fold it")
+	}
+	if m.selectedIdx != 1 {
+		t.Errorf("selectedIdx = %d, want 1 (unchanged)", m.selectedIdx)
+	}
+	m.handleMouse(click)
+	if m.main.collapsed["beta"] {
+		t.Error("clicking the folded beta header didn't expand it")
+	}
+}
+
+func TestGroupBulkSelection(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+
+	sendKeys(m, "v")
+	if got := slices.Sorted(maps.Keys(m.main.bulkSelected)); !slices.Equal(got, []string{"a1@alpha", "a2@alpha"}) {
+		t.Errorf("bulkSelected = %v, want the alpha plugins", got)
+	}
+	sendKeys(m, "v")
+	if len(m.main.bulkSelected) != 0 {
+		t.Errorf("bulkSelected = %v after deselecting the group", m.main.bulkSelected)
+	}
+
+	// Space on a folded group's header selects the group
+	sendKeys(m, "z", " ")
+	if len(m.main.bulkSelected) != 2 {
+		t.Errorf("bulkSelected = %v, want both alpha plugins", m.main.bulkSelected)
+	}
+}
+
+func TestGroupHeaderStats(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+	m.plugins[1].HasUpdate = true // a1@alpha: installed, disabled
+
+	stats := m.groupStatsFor("alpha")
+	want := groupStats{installed: 1, available: 1, updates: 1, disabled: 1}
+	if stats != want {
+		t.Errorf("groupStatsFor(alpha) = %+v, want %+v", stats, want)
+	}
+
+	header := m.renderListItem(m.plugins[0], false, m.styles)
+	for _, part := range []string{"▾ alpha", "1 installed · 1 available · 1 ↑ · 1 disabled"} {
+		if !strings.Contains(header, part) {
+			t.Errorf("header = %q, want containing %q", header, part)
+		}
+	}
+	if got := m.groupStatsFor("beta").String(); got != "0 installed · 1 available" {
+		t.Errorf("beta stats = %q", got)
+	}
+}
@@ -8,63 +8,91 @@ import (
 
 // KeyBindings defines all keyboard shortcuts.
 type KeyBindings struct {
-	Up         []string
-	Down       []string
-	PageUp     []string
-	PageDown   []string
-	Home       []string
-	End        []string
-	Enter      []string
-	Quit       []string
-	Local      []string
-	Project    []string
-	Toggle     []string
-	Uninstall  []string
-	Update     []string // Update plugin to latest version
-	Enable     []string // Toggle plugin enabled/disabled state
-	Escape     []string
-	Filter     []string
-	Refresh    []string
-	Mouse      []string
-	Sort       []string // Cycle through sort options
-	Readme     []string // View plugin README
-	Changelog  []string // View plugin CHANGELOG
-	Config     []string // Open plugin config viewer
-	BulkToggle []string // Toggle bulk selection on current plugin
-	BulkAll    []string // Select all plugins
-	BulkNone   []string // Deselect all plugins
-	Scope      []string // Open multi-scope dialog
+	Up                 []string
+	Down               []string
+	PageUp             []string
+	PageDown           []string
+	Home               []string
+	End                []string
+	Enter              []string
+	Quit               []string
+	Local              []string
+	Project            []string
+	Toggle             []string
+	Uninstall          []string
+	Update             []string // Update plugin to latest version
+	Enable             []string // Toggle plugin enabled/disabled state
+	Escape             []string
+	Filter             []string
+	Refresh            []string
+	RefreshMarketplace []string // Refresh the selected plugin's marketplace
+	Mouse              []string
+	Sort               []string // Cycle through sort options
+	Readme             []string // View plugin README
+	Changelog          []string // View plugin CHANGELOG
+	Config             []string // Open plugin config viewer
+	BulkToggle         []string // Toggle bulk selection on current plugin
+	BulkAll            []string // Select all plugins
+	BulkNone           []string // Deselect all plugins
+	Scope              []string // Open multi-scope dialog
+	Extras             []string // Open the extraKnownMarketplaces editor
+	Marketplaces       []string // Open the marketplaces view
+	Fold               []string // Collapse or expand the selected group
+	FoldAll            []string // Collapse or expand every group
+	BulkGroup          []string // Toggle bulk selection of the selected group
+	Views              []string // Open the saved views picker
+	QuickView          []string // Show saved view 1-9 from the tab bar, or all plugins with 0
+	Add                []string // Add an entry in the marketplaces, views, and extras screens
+	Delete             []string // Remove the highlighted entry in those screens
+	Edit               []string // Edit the highlighted extras entry's ref and path
+	AutoUpdate         []string // Toggle the highlighted marketplace's auto-update
+	Confirm            []string // Answer yes to a confirmation prompt
+	Cancel             []string // Answer no to a confirmation prompt
 }
 
 // DefaultKeyBindings returns the default key bindings.
 func DefaultKeyBindings() KeyBindings {
 	return KeyBindings{
-		Up:         []string{"up", "k"},
-		Down:       []string{"down", "j"},
-		PageUp:     []string{"pgup", "ctrl+u"},
-		PageDown:   []string{"pgdown", "ctrl+d"},
-		Home:       []string{"home", "g"},
-		End:        []string{"end", "G"},
-		Enter:      []string{"enter"},
-		Quit:       []string{"q", "ctrl+c"},
-		Local:      []string{"l"},
-		Project:    []string{"p"},
-		Toggle:     []string{"tab"},
-		Uninstall:  []string{"u"},
-		Update:     []string{"U"}, // Shift+u for update
-		Enable:     []string{"e"},
-		Escape:     []string{"esc"},
-		Filter:     []string{"/"},
-		Refresh:    []string{"r"},
-		Mouse:      []string{"m"},
-		Sort:       []string{"s"},
-		Readme:     []string{"?"},
-		Changelog:  []string{"C"},
-		Config:     []string{"c"},
-		BulkToggle: []string{" "}, // Space to toggle selection
-		BulkAll:    []string{"a"}, // Select all
-		BulkNone:   []string{"A"}, // Deselect all (shift+a)
-		Scope:      []string{"S"}, // Shift+s for scope dialog
+		Up:                 []string{"up", "k"},
+		Down:               []string{"down", "j"},
+		PageUp:             []string{"pgup", "ctrl+u"},
+		PageDown:           []string{"pgdown", "ctrl+d"},
+		Home:               []string{"home", "g"},
+		End:                []string{"end", "G"},
+		Enter:              []string{"enter"},
+		Quit:               []string{"q", "ctrl+c"},
+		Local:              []string{"l"},
+		Project:            []string{"p"},
+		Toggle:             []string{"tab"},
+		Uninstall:          []string{"u"},
+		Update:             []string{"U"}, // Shift+u for update
+		Enable:             []string{"e"},
+		Escape:             []string{"esc"},
+		Filter:             []string{"/"},
+		Refresh:            []string{"r"},
+		RefreshMarketplace: []string{"R"}, // Shift+r
+		Mouse:              []string{"m"},
+		Sort:               []string{"s"},
+		Readme:             []string{"?"},
+		Changelog:          []string{"C"},
+		Config:             []string{"c"},
+		BulkToggle:         []string{" "}, // Space to toggle selection
+		BulkAll:            []string{"a"}, // Select all
+		BulkNone:           []string{"A"}, // Deselect all (shift+a)
+		Scope:              []string{"S"}, // Shift+s for scope dialog
+		Extras:             []string{"X"}, // Shift+x for extra marketplaces
+		Marketplaces:       []string{"M"}, // Shift+m for marketplaces
+		Fold:               []string{"z"},
+		FoldAll:            []string{"Z"}, // Shift+z
+		BulkGroup:          []string{"v"},
+		Views:              []string{"F"}, // Shift+f for saved filters
+		QuickView:          []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"},
+		Add:                []string{"a"},
+		Delete:             []string{"d", "delete"},
+		Edit:               []string{"e"},
+		AutoUpdate:         []string{"u"},
+		Confirm:            []string{"y", "Y"},
+		Cancel:             []string{"n", "N"},
 	}
 }
 
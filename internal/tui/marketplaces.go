@@ -0,0 +1,314 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+// MarketplacesState holds state for the marketplaces view.
+type MarketplacesState struct {
+	adding   *addMarketplaceForm // Add-marketplace wizard; nil when closed
+	known    map[string]claude.KnownMarketplace
+	message  string   // Result of the last action or why known couldn't be read
+	removing string   // Marketplace awaiting confirmation of removal
+	names    []string // Sorted names of known
+	cursor   int
+	busy     bool // An add, refresh, removal, or auto-update change is running
+}
+
+// marketplaceDoneMsg is sent when adding, refreshing, removing, or changing
+// auto-update for a marketplace finishes.
+type marketplaceDoneMsg struct {
+	err  error
+	name string
+	verb string // Key of marketplaceVerbs, for errors
+	done string // Past tense of verb, for success
+}
+
+// openMarketplaces opens the marketplaces view.
+func (m *Model) openMarketplaces() {
+	m.marketplaces = MarketplacesState{}
+	m.loadMarketplaces()
+	m.mode = ModeMarketplaces
+}
+
+// loadMarketplaces reads known_marketplaces.json.
+func (m *Model) loadMarketplaces() {
+	state := &m.marketplaces
+	known, err := claude.ReadKnownMarketplaces()
+	if err != nil {
+		state.message = fmt.Sprintf("Can't read known marketplaces: %v", err)
+	}
+	state.known = known
+	state.names = slices.Sorted(maps.Keys(known))
+	state.cursor = min(state.cursor, max(len(state.names)-1, 0))
+}
+
+// selectedMarketplace returns the name of the highlighted marketplace, or "".
+func (m *Model) selectedMarketplace() string {
+	if m.marketplaces.cursor < len(m.marketplaces.names) {
+		return m.marketplaces.names[m.marketplaces.cursor]
+	}
+	return ""
+}
+
+// updateMarketplaces handles input in the marketplaces view.
+func (m *Model) updateMarketplaces(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.marketplaces.adding != nil {
+		return m, m.handleAddMarketplaceKey(keyMsg)
+	}
+	if m.marketplaces.removing != "" {
+		return m, m.handleRemoveConfirmKey(keyMsg)
+	}
+
+	state := &m.marketplaces
+	switch {
+	case matchesKey(keyMsg, m.keys.Escape), matchesKey(keyMsg, m.keys.Quit), matchesKey(keyMsg, m.keys.Marketplaces):
+		m.mode = ModeMain
+	case matchesKey(keyMsg, m.keys.Up):
+		state.cursor = max(state.cursor-1, 0)
+	case matchesKey(keyMsg, m.keys.Down):
+		state.cursor = max(min(state.cursor+1, len(state.names)-1), 0)
+	case matchesKey(keyMsg, m.keys.Enter):
+		m.jumpToMarketplace(m.selectedMarketplace())
+	case !state.busy:
+		return m, m.handleMarketplaceActionKey(keyMsg)
+	}
+	return m, nil
+}
+
+// handleMarketplaceActionKey handles the keys that start an add, refresh,
+// removal, or auto-update change, which run one at a time.
+func (m *Model) handleMarketplaceActionKey(msg tea.KeyMsg) tea.Cmd {
+	name := m.selectedMarketplace()
+	switch {
+	case matchesKey(msg, m.keys.Refresh):
+		if name != "" {
+			return m.runMarketplaceAction(name, "refresh", m.client.UpdateMarketplace)
+		}
+	case matchesKey(msg, m.keys.AutoUpdate):
+		return m.toggleAutoUpdate(name)
+	case matchesKey(msg, m.keys.Add):
+		m.openAddMarketplace()
+	case matchesKey(msg, m.keys.Delete):
+		m.marketplaces.removing = name
+	}
+	return nil
+}
+
+// toggleAutoUpdate returns a command flipping the named marketplace's
+// autoUpdate setting, which waits on the lock of known_marketplaces.json.
+func (m *Model) toggleAutoUpdate(name string) tea.Cmd {
+	mp, ok := m.marketplaces.known[name]
+	if !ok {
+		return nil
+	}
+	on := !mp.AutoUpdate
+	verb := "turn off auto-update for"
+	if on {
+		verb = "turn on auto-update for"
+	}
+	return m.runMarketplaceAction(name, verb, func(name string) error {
+		return claude.SetMarketplaceAutoUpdate(name, on)
+	})
+}
+
+// onOff formats a setting as "on" or "off".
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+// handleRemoveConfirmKey removes the marketplace on y and cancels on n or Esc.
+func (m *Model) handleRemoveConfirmKey(msg tea.KeyMsg) tea.Cmd {
+	state := &m.marketplaces
+	name := state.removing
+	switch {
+	case matchesKey(msg, m.keys.Confirm):
+		state.removing = ""
+		return m.runMarketplaceAction(name, "remove", m.client.RemoveMarketplace)
+	case matchesKey(msg, m.keys.Cancel), matchesKey(msg, m.keys.Escape):
+		state.removing = ""
+	}
+	return nil
+}
+
+// marketplaceVerbs maps each marketplace action to its progress and done messages.
+var marketplaceVerbs = map[string][2]string{
+	"add":     {"Adding", "Added"},
+	"refresh": {"Refreshing", "Refreshed"},
+	"remove":  {"Removing", "Removed"},
+
+	"turn on auto-update for":  {"Turning on auto-update for", "Turned on auto-update for"},
+	"turn off auto-update for": {"Turning off auto-update for", "Turned off auto-update for"},
+}
+
+// runMarketplaceAction returns a command running fn on the named marketplace
+// and reporting the result as a marketplaceDoneMsg.
+func (m *Model) runMarketplaceAction(name, verb string, fn func(string) error) tea.Cmd {
+	m.marketplaces.busy = true
+	m.marketplaces.message = fmt.Sprintf("%s %s…", marketplaceVerbs[verb][0], name)
+	return func() tea.Msg {
+		return marketplaceDoneMsg{err: fn(name), name: name, verb: verb, done: marketplaceVerbs[verb][1]}
+	}
+}
+
+// refreshSelectedMarketplace refreshes the marketplace of the selected
+// plugin from the main view, reporting progress in the status line.
+func (m *Model) refreshSelectedMarketplace() tea.Cmd {
+	plugin := m.getSelectedPlugin()
+	if plugin == nil || plugin.Marketplace == "" || m.marketplaces.busy {
+		return nil
+	}
+	cmd := m.runMarketplaceAction(plugin.Marketplace, "refresh", m.client.UpdateMarketplace)
+	m.main.status = m.marketplaces.message
+	return cmd
+}
+
+// handleMarketplaceDone reports a finished marketplace action and reloads
+// the marketplaces and plugins it changed. Outside the marketplaces view the
+// result is shown in the main view's status line.
+func (m *Model) handleMarketplaceDone(msg marketplaceDoneMsg) tea.Cmd {
+	m.marketplaces.busy = false
+	if msg.err != nil {
+		m.marketplaces.message = fmt.Sprintf("Couldn't %s %s: %v", msg.verb, msg.name, msg.err)
+	} else {
+		m.marketplaces.message = fmt.Sprintf("%s %s", msg.done, msg.name)
+	}
+	if m.mode != ModeMarketplaces {
+		m.main.status = m.marketplaces.message
+	}
+	if msg.err != nil {
+		return nil
+	}
+	m.loadMarketplaces()
+	return m.reloadPlugins
+}
+
+// jumpToMarketplace returns to the main view with the first plugin of the
+// named marketplace in the active view selected.
+func (m *Model) jumpToMarketplace(name string) {
+	if name == "" {
+		return
+	}
+	fromMarketplace := func(p PluginState) bool { return !p.IsGroupHeader && p.Marketplace == name }
+	idx := slices.IndexFunc(m.plugins, func(p PluginState) bool { return fromMarketplace(p) && m.inView(&p) })
+	if idx < 0 {
+		m.marketplaces.message = "No plugins listed from " + name
+		if slices.ContainsFunc(m.plugins, fromMarketplace) {
+			m.marketplaces.message = "No plugins from " + name + " in the active view"
+		}
+		return
+	}
+	m.selectedIdx = idx
+	m.revealSelection()
+	m.ensureVisible()
+	m.mode = ModeMain
+}
+
+// marketplacePluginCounts returns how many plugins a marketplace's catalogue
+// offers and how many listed plugins from it are installed.
+func (m *Model) marketplacePluginCounts(name string) (offered, installed int) {
+	if catalog, ok := m.catalogs[name]; ok {
+		offered = len(catalog.Plugins)
+	}
+	for i := range m.plugins {
+		if !m.plugins[i].IsGroupHeader && m.plugins[i].Marketplace == name && m.plugins[i].IsInstalled() {
+			installed++
+		}
+	}
+	return offered, installed
+}
+
+// renderMarketplaces renders the marketplaces view.
+func (m *Model) renderMarketplaces(styles Styles) string {
+	header := styles.Header.Render(" Marketplaces ")
+
+	var lines []string
+	var help string
+	if m.marketplaces.adding != nil {
+		lines, help = m.renderAddMarketplace(styles)
+	} else {
+		lines = m.renderMarketplaceList(styles, time.Now())
+		help = "↑↓: navigate • Enter: show plugins • a: add • r: refresh • u: auto-update • d: remove • Esc: back"
+	}
+	if name := m.marketplaces.removing; name != "" {
+		lines = append(lines, "", styles.Pending.Render(fmt.Sprintf("Remove marketplace %s?", name)))
+		help = "y: remove • n/Esc: cancel"
+	}
+	if m.marketplaces.message != "" {
+		lines = append(lines, "", styles.Pending.Render(m.marketplaces.message))
+	}
+
+	content := lipgloss.NewStyle().
+		Width(max(m.width-4, 0)).
+		Height(max(m.height-4, 1)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+	return lipgloss.JoinVertical(lipgloss.Left, header, content, styles.Help.Render(help))
+}
+
+// marketplaceBlockLines is the number of lines each marketplace takes in the list.
+const marketplaceBlockLines = 7
+
+// renderMarketplaceList renders one block per known marketplace, with ages
+// relative to now, scrolled to keep the cursor visible.
+func (m *Model) renderMarketplaceList(styles Styles, now time.Time) []string {
+	names := m.marketplaces.names
+	if len(names) == 0 {
+		return []string{styles.Help.Render("No known marketplaces")}
+	}
+
+	perPage := max((m.height-8)/marketplaceBlockLines, 1)
+	start := max(m.marketplaces.cursor-perPage+1, 0)
+	end := min(start+perPage, len(names))
+
+	label := func(s string) string { return styles.DetailLabel.Render(fmt.Sprintf("    %-10s", s)) }
+	var lines []string
+	for i := start; i < end; i++ {
+		name := names[i]
+		mp := m.marketplaces.known[name]
+		if i == m.marketplaces.cursor {
+			lines = append(lines, styles.Selected.Render("> "+name))
+		} else {
+			lines = append(lines, "  "+styles.GroupHeader.Render(name))
+		}
+
+		source := "unknown"
+		if mp.Source != nil {
+			source = mp.Source.SourceType() + " " + mp.Source.String()
+		}
+		offered, installed := m.marketplacePluginCounts(name)
+		ageStyle := styles.DetailValue
+		if m.isStale(mp.LastUpdated, now) {
+			ageStyle = styles.Pending
+		}
+
+		lines = append(lines,
+			label("Source:")+styles.DetailValue.Render(source),
+			label("Location:")+styles.DetailValue.Render(displayPath(mp.InstallLocation, m.workingDir)),
+			label("Updated:")+ageStyle.Render(formatAge(mp.LastUpdated, now)),
+			label("Auto:")+styles.DetailValue.Render(onOff(mp.AutoUpdate)),
+			label("Plugins:")+styles.DetailValue.Render(fmt.Sprintf("%d offered, %d installed", offered, installed)),
+			"",
+		)
+	}
+	if hidden := len(names) - (end - start); hidden > 0 {
+		lines = append(lines, styles.Help.Render(fmt.Sprintf("%d of %d marketplaces shown", end-start, len(names))))
+	}
+	return lines
+}
@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+const testKnownMarketplaces = `{
+  "alpha": {"source": {"source": "github", "repo": "owner/alpha", "ref": "v2"}, "installLocation": "/mp/alpha", "lastUpdated": "2026-01-01T00:00:00Z", "autoUpdate": true},
+  "beta": {"source": {"source": "directory", "path": "/src/beta"}, "installLocation": "/mp/beta"}
+}`
+
+// marketplacesTestModel returns a loaded model listing plugins from alpha and beta.
+func marketplacesTestModel(client *mockClient) *Model {
+	m := NewModel(client, "/test/project")
+	m.progress.loading = false
+	m.height = 40
+	m.plugins = []PluginState{
+		{Name: "alpha", IsGroupHeader: true, Marketplace: "alpha"},
+		{ID: "a1@alpha", Name: "a1", Marketplace: "alpha", InstalledScopes: map[claude.Scope]bool{claude.ScopeUser: true}},
+		{ID: "a2@alpha", Name: "a2", Marketplace: "alpha", InstalledScopes: map[claude.Scope]bool{}},
+		{Name: "beta", IsGroupHeader: true, Marketplace: "beta"},
+		{ID: "b1@beta", Name: "b1", Marketplace: "beta", InstalledScopes: map[claude.Scope]bool{}},
+	}
+	m.catalogs = map[string]*claude.Marketplace{
+		"alpha": {Plugins: []claude.MarketplacePlugin{{Name: "a1"}, {Name: "a2"}, {Name: "a3"}}},
+	}
+	m.selectedIdx = 1
+	return m
+}
+
+func TestMarketplacesView(t *testing.T) {
+	setupKnownMarketplaces(t, testKnownMarketplaces)
+	m := marketplacesTestModel(&mockClient{})
+
+	sendKeys(m, "M")
+	if m.mode != ModeMarketplaces {
+		t.Fatalf("mode = %v, want ModeMarketplaces", m.mode)
+	}
+	if got := strings.Join(m.marketplaces.names, ","); got != "alpha,beta" {
+		t.Errorf("names = %q, want %q", got, "alpha,beta")
+	}
+
+	now := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+	out := strings.Join(m.renderMarketplaceList(m.styles, now), "\n")
+	for _, want := range []string{
+		"github owner/alpha@v2", "/mp/alpha", "3d ago", "on",
+		"3 offered, 1 installed", "directory /src/beta", "never", "0 offered, 0 installed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("list missing %q:\n%s", want, out)
+		}
+	}
+
+	sendKeys(m, "esc")
+	if m.mode != ModeMain {
+		t.Errorf("mode after Esc = %v, want ModeMain", m.mode)
+	}
+}
+
+func TestMarketplacesJump(t *testing.T) {
+	setupKnownMarketplaces(t, testKnownMarketplaces)
+	m := marketplacesTestModel(&mockClient{})
+
+	sendKeys(m, "M", "j", "enter")
+	if m.mode != ModeMain {
+		t.Fatalf("mode = %v, want ModeMain", m.mode)
+	}
+	if m.selectedIdx != 4 {
+		t.Errorf("selectedIdx = %d, want 4 (b1@beta)", m.selectedIdx)
+	}
+}
+
+func TestMarketplacesRefreshAndRemove(t *testing.T) {
+	setupKnownMarketplaces(t, testKnownMarketplaces)
+	var updated, removed []string
+	client := &mockClient{
+		updateMpFn: func(name string) error { updated = append(updated, name); return nil },
+		removeMpFn: func(name string) error { removed = append(removed, name); return errors.New("boom") },
+	}
+	m := marketplacesTestModel(client)
+	sendKeys(m, "M")
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil || !m.marketplaces.busy {
+		t.Fatal("refresh returned no command")
+	}
+	msg := cmd()
+	if _, cmd = m.Update(msg); cmd == nil {
+		t.Error("successful refresh didn't reload plugins")
+	}
+	if len(updated) != 1 || updated[0] != "alpha" {
+		t.Errorf("updated = %v, want [alpha]", updated)
+	}
+	if m.marketplaces.message != "Refreshed alpha" {
+		t.Errorf("message = %q, want %q", m.marketplaces.message, "Refreshed alpha")
+	}
+
+	// Removal asks first; n cancels
+	sendKeys(m, "d", "n")
+	if m.marketplaces.removing != "" || len(removed) != 0 {
+		t.Fatalf("removing = %q, removed = %v after cancelling", m.marketplaces.removing, removed)
+	}
+
+	sendKeys(m, "d")
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if cmd == nil {
+		t.Fatal("confirming removal returned no command")
+	}
+	if _, cmd = m.Update(cmd()); cmd != nil {
+		t.Error("failed removal reloaded plugins")
+	}
+	if len(removed) != 1 || !strings.Contains(m.marketplaces.message, "Couldn't remove alpha: boom") {
+		t.Errorf("removed = %v, message = %q", removed, m.marketplaces.message)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		timestamp string
+		want      string
+	}{
+		{"", "never"},
+		{"not a time", "not a time"},
+		{"2026-03-10T11:59:30Z", "just now"},
+		{"2026-03-10T11:15:00Z", "45m ago"},
+		{"2026-03-10T02:00:00Z", "10h ago"},
+		{"2026-02-28T12:00:00Z", "10d ago"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.timestamp, now); got != tt.want {
+			t.Errorf("formatAge(%q) = %q, want %q", tt.timestamp, got, tt.want)
+		}
+	}
+}
+
+func TestSortKeepsGroupHeaderCatalog(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+	m.catalogs["alpha"].Description = "Alpha tools"
+	m.main.sortMode = SortByNameDesc
+	m.sortPlugins()
+
+	for _, p := range m.plugins {
+		if p.IsGroupHeader && p.Marketplace == "alpha" && p.Description != "Alpha tools" {
+			t.Errorf("alpha header description = %q after sorting, want %q", p.Description, "Alpha tools")
+		}
+	}
+}
+
+func TestRenderGroupHeaderAge(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+	m.known = map[string]claude.KnownMarketplace{
+		"alpha": {LastUpdated: "2026-01-01T00:00:00Z"},
+		"beta":  {},
+	}
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		want   string
+		header PluginState
+	}{
+		{"updated 9d ago", PluginState{Name: "alpha", Marketplace: "alpha", IsGroupHeader: true}},
+		{"", PluginState{Name: "beta", Marketplace: "beta", IsGroupHeader: true}},
+		{"", PluginState{Name: "User", IsGroupHeader: true}},
+	}
+	for _, tt := range tests {
+		got := m.renderGroupHeader(tt.header, false, m.styles, now)
+		if tt.want != "" && !strings.Contains(got, tt.want) {
+			t.Errorf("header %q = %q, want containing %q", tt.header.Name, got, tt.want)
+		}
+		if tt.want == "" && strings.Contains(got, "updated") {
+			t.Errorf("header %q = %q, want no age", tt.header.Name, got)
+		}
+	}
+
+	if !m.isStale("2026-01-01T00:00:00Z", now) {
+		t.Error("9-day-old marketplace not stale with the default threshold")
+	}
+	m.SetStaleAfter(10 * 24 * time.Hour)
+	if m.isStale("2026-01-01T00:00:00Z", now) {
+		t.Error("9-day-old marketplace stale with a 10-day threshold")
+	}
+	m.SetStaleAfter(0)
+	if m.isStale("2020-01-01T00:00:00Z", now) {
+		t.Error("marketplace stale with highlighting off")
+	}
+}
+
+func TestRefreshSelectedMarketplace(t *testing.T) {
+	var updated []string
+	client := &mockClient{updateMpFn: func(name string) error {
+		updated = append(updated, name)
+		return errors.New("offline")
+	}}
+	m := marketplacesTestModel(client)
+	m.selectedIdx = 4 // b1@beta
+	m.main.notice = "Warning: project settings ignored"
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	if cmd == nil || m.main.status != "Refreshing beta…" {
+		t.Fatalf("status = %q, want refreshing beta", m.main.status)
+	}
+	if _, cmd = m.Update(cmd()); cmd != nil {
+		t.Error("failed refresh reloaded plugins")
+	}
+	if len(updated) != 1 || updated[0] != "beta" {
+		t.Errorf("updated = %v, want [beta]", updated)
+	}
+	if m.main.status != "Couldn't refresh beta: offline" {
+		t.Errorf("status = %q", m.main.status)
+	}
+	if m.main.notice != "Warning: project settings ignored" {
+		t.Errorf("notice = %q, want the settings warning kept", m.main.notice)
+	}
+}
+
+func TestMarketplacesToggleAutoUpdate(t *testing.T) {
+	home := setupKnownMarketplaces(t, testKnownMarketplaces)
+	m := marketplacesTestModel(&mockClient{})
+
+	sendKeys(m, "M")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if !m.marketplaces.busy || m.marketplaces.message != "Turning off auto-update for alpha…" {
+		t.Fatalf("busy = %v, message = %q, want the change running", m.marketplaces.busy, m.marketplaces.message)
+	}
+	if _, second := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")}); second != nil {
+		t.Error("u while busy started another change")
+	}
+	m.Update(cmd())
+	if m.marketplaces.busy || m.marketplaces.message != "Turned off auto-update for alpha" {
+		t.Errorf("busy = %v, message = %q", m.marketplaces.busy, m.marketplaces.message)
+	}
+
+	sendKeys(m, "j")
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	m.Update(cmd())
+	if m.marketplaces.message != "Turned on auto-update for beta" {
+		t.Errorf("message = %q", m.marketplaces.message)
+	}
+
+	known, err := claude.ReadKnownMarketplacesFrom(filepath.Join(home, ".claude", "plugins"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if known["alpha"].AutoUpdate || !known["beta"].AutoUpdate {
+		t.Errorf("AutoUpdate = %v, %v, want false, true", known["alpha"].AutoUpdate, known["beta"].AutoUpdate)
+	}
+	if known["alpha"].InstallLocation != "/mp/alpha" {
+		t.Errorf("InstallLocation = %q, want it kept", known["alpha"].InstallLocation)
+	}
+}
@@ -3,12 +3,17 @@ package tui
 
 import (
 	"cmp"
+	"fmt"
 	"maps"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/open-cli-collective/cpm/internal/claude"
+	"github.com/open-cli-collective/cpm/internal/config"
 )
 
 // OperationType represents the type of operation to perform.
@@ -70,6 +75,12 @@ const (
 	ModeConfig
 	// ModeScopeDialog shows the scope selection dialog.
 	ModeScopeDialog
+	// ModeExtras shows the extraKnownMarketplaces editor.
+	ModeExtras
+	// ModeMarketplaces shows the known marketplaces.
+	ModeMarketplaces
+	// ModeViews shows the saved views picker.
+	ModeViews
 )
 
 // DocType represents the type of document being viewed.
@@ -93,6 +104,8 @@ const (
 	SortByScope
 	// SortByMarketplace sorts plugins by marketplace name.
 	SortByMarketplace
+	// SortBySize sorts installed plugins by disk usage (largest first).
+	SortBySize
 )
 
 // String returns the display name for the sort mode.
@@ -106,6 +119,8 @@ func (s SortMode) String() string {
 		return "Scope"
 	case SortByMarketplace:
 		return "Marketplace"
+	case SortBySize:
+		return "Size"
 	default:
 		return "Unknown"
 	}
@@ -115,18 +130,27 @@ func (s SortMode) String() string {
 // Fields are ordered for optimal memory alignment (strings/pointers first, bools last).
 type PluginState struct {
 	Components       *claude.PluginComponents
-	Version          string // Installed version (or available version if not installed)
-	AvailableVersion string // Latest available version from marketplace
+	DiskUsage        *claude.DirUsage // Size of InstallPath; nil until measured
+	Managed          *bool            // Managed policy: nil if unset, true forces enabled, false blocks
+	Version          string           // Installed version (or available version if not installed)
+	AvailableVersion string           // Latest available version from marketplace
 	Description      string
 	AuthorName       string
 	AuthorEmail      string
+	Homepage         string
+	Repository       string
+	License          string
+	Category         string // Category from the marketplace catalogue
 	Marketplace      string
 	ID               string
 	InstallPath      string
-	ExternalURL      string
+	Source           claude.MarketplaceSource // Typed source for plugins hosted outside the marketplace clone
 	LastUpdated      string
 	InstalledScopes  map[claude.Scope]bool // Scopes where this plugin is installed; value = enabled state
 	Name             string
+	Keywords         []string
+	Tags             []string          // Tags from the marketplace catalogue
+	Conflicts        []claude.Conflict // Component names shared with other enabled plugins
 	InstallCount     int
 	Enabled          bool
 	IsGroupHeader    bool
@@ -155,8 +179,7 @@ func PluginStateFromInstalled(p claude.InstalledPlugin) PluginState {
 	if p.InstallPath != "" {
 		if manifest, err := claude.ReadPluginManifest(p.InstallPath); err == nil {
 			state.Description = manifest.Description
-			state.AuthorName = manifest.AuthorName
-			state.AuthorEmail = manifest.AuthorEmail
+			state.applyManifest(manifest)
 		}
 		// Scan for components
 		state.Components = claude.ScanPluginComponents(p.InstallPath)
@@ -183,13 +206,18 @@ func PluginStateFromAvailable(p claude.AvailablePlugin) PluginState {
 		InstalledScopes: map[claude.Scope]bool{},
 	}
 
-	// Try to resolve the marketplace source path to get additional info
-	sourcePath := claude.ResolveMarketplaceSourcePath(p.MarketplaceName, p.Source)
+	// Object sources (github, git, url, npm) are hosted outside the marketplace clone
+	if source := claude.ParsePluginSource(p.Source); source != nil {
+		state.IsExternal = true
+		state.Source = source
+	}
+
+	// Try to resolve a local copy of the plugin to get additional info
+	sourcePath := claude.ResolvePluginSourcePath(p.MarketplaceName, name, p.Source)
 	if sourcePath != "" {
 		// Read manifest for author info
 		if manifest, err := claude.ReadPluginManifest(sourcePath); err == nil {
-			state.AuthorName = manifest.AuthorName
-			state.AuthorEmail = manifest.AuthorEmail
+			state.applyManifest(manifest)
 			// Use manifest description if available and CLI description is empty
 			if state.Description == "" && manifest.Description != "" {
 				state.Description = manifest.Description
@@ -197,21 +225,40 @@ func PluginStateFromAvailable(p claude.AvailablePlugin) PluginState {
 		}
 		// Scan for components
 		state.Components = claude.ScanPluginComponents(sourcePath)
-	} else {
-		// Check if this is an external URL-based plugin
-		if sourceObj, ok := p.Source.(map[string]any); ok {
-			if sourceType, ok := sourceObj["source"].(string); ok && sourceType == "url" {
-				state.IsExternal = true
-				if url, ok := sourceObj["url"].(string); ok {
-					state.ExternalURL = url
-				}
-			}
-		}
 	}
 
 	return state
 }
 
+// applyManifest copies author and project metadata from a plugin manifest.
+func (ps *PluginState) applyManifest(manifest *claude.PluginManifest) {
+	ps.AuthorName = manifest.AuthorName
+	ps.AuthorEmail = manifest.AuthorEmail
+	ps.Homepage = manifest.Homepage
+	ps.Repository = manifest.Repository
+	ps.License = manifest.License
+	ps.Keywords = manifest.Keywords
+}
+
+// applyCatalog fills in metadata from the plugin's marketplace catalogue entry.
+// Values already read from the CLI or plugin.json take precedence.
+func (ps *PluginState) applyCatalog(entry *claude.MarketplacePlugin) {
+	ps.Category = entry.Category
+	ps.Tags = entry.Tags
+	ps.Description = cmp.Or(ps.Description, entry.Description)
+	ps.Version = cmp.Or(ps.Version, entry.Version)
+	ps.Homepage = cmp.Or(ps.Homepage, entry.Homepage)
+	ps.Repository = cmp.Or(ps.Repository, entry.Repository)
+	ps.License = cmp.Or(ps.License, entry.License)
+	if ps.AuthorName == "" {
+		ps.AuthorName = entry.AuthorName
+		ps.AuthorEmail = entry.AuthorEmail
+	}
+	if len(ps.Keywords) == 0 {
+		ps.Keywords = entry.Keywords
+	}
+}
+
 // parsePluginID splits "name@marketplace" into (name, marketplace).
 func parsePluginID(id string) (name, marketplace string) {
 	mp := claude.MarketplaceNameFromPluginID(id)
@@ -246,6 +293,33 @@ func (ps *PluginState) SingleScope() claude.Scope {
 	return claude.ScopeNone
 }
 
+// applyManagedScope moves a managed policy entry out of InstalledScopes into
+// Managed. Managed settings are read-only, so operations never target them.
+func (ps *PluginState) applyManagedScope() {
+	enabled, ok := ps.InstalledScopes[claude.ScopeManaged]
+	if !ok {
+		return
+	}
+	ps.Managed = &enabled
+	ps.InstalledScopes = maps.Clone(ps.InstalledScopes)
+	delete(ps.InstalledScopes, claude.ScopeManaged)
+}
+
+// policyBlocks returns why managed policy would override an operation on this
+// plugin, or "" if the operation is allowed.
+func (ps *PluginState) policyBlocks(opType OperationType) string {
+	switch {
+	case ps.Managed == nil:
+		return ""
+	case *ps.Managed && (opType == OpUninstall || opType == OpDisable):
+		return ps.ID + " is enabled by managed settings; removing or disabling it would have no effect"
+	case !*ps.Managed && opType != OpUninstall && opType != OpDisable:
+		return ps.ID + " is blocked by managed settings and can't be installed or enabled"
+	default:
+		return ""
+	}
+}
+
 // firstScope returns the first key from a scope map. Used where exactly one scope is expected.
 func firstScope(m map[claude.Scope]bool) claude.Scope {
 	for s := range m {
@@ -258,6 +332,11 @@ func firstScope(m map[claude.Scope]bool) claude.Scope {
 type MainState struct {
 	pendingOps      map[string]Operation
 	bulkSelected    map[string]bool // Tracks plugins selected for bulk operations
+	collapsed       map[string]bool // Names of group headers whose plugins are hidden
+	viewIDs         map[string]bool // IDs of the plugins in the active view; nil lists all
+	notice          string          // Persistent notice shown above the help bar (e.g., offline mode)
+	status          string          // Result of the last action, shown over the notice until the next key
+	settingsDiffs   []settingsDiff  // Settings file previews shown in the confirmation dialog
 	scopeDialog     scopeDialogState
 	sortMode        SortMode
 	showConfirm     bool
@@ -267,7 +346,8 @@ type MainState struct {
 
 // FilterState holds state for filter mode.
 type FilterState struct {
-	text   string
+	text   string // Query text (see parseQuery)
+	err    string // Why text doesn't parse; the previous matches stay shown
 	active bool
 }
 
@@ -305,23 +385,35 @@ type ProgressState struct {
 // Model is the main application model.
 // Fields ordered for optimal memory alignment (pointers/slices first, bools last).
 type Model struct {
-	client      claude.Client
-	err         error
-	styles      Styles
-	workingDir  string
-	keys        KeyBindings
-	config      ConfigState
-	plugins     []PluginState
-	filteredIdx []int
-	filter      FilterState
-	doc         DocState
-	progress    ProgressState
-	main        MainState
-	mode        Mode
-	height      int
-	width       int
-	selectedIdx int
-	listOffset  int
+	client       claude.Client
+	err          error
+	styles       Styles
+	workingDir   string
+	keys         KeyBindings
+	config       ConfigState
+	plugins      []PluginState
+	filteredIdx  []int
+	catalogs     map[string]*claude.Marketplace     // Marketplace catalogues as of the last load
+	known        map[string]claude.KnownMarketplace // Known marketplaces as of the last load
+	diskUsage    map[string]claude.DirUsage         // Measured usage by install path, kept across refreshes
+	cacheUsage   *claude.PluginsUsage               // Usage of ~/.claude/plugins; nil until measured
+	watched      fileStamps                         // Watched file stamps as of the last load
+	prefs        config.Config                      // cpm's own preferences
+	prefsPath    string                             // Where prefs are saved; "" keeps them in memory
+	prefsWarning string                             // Why the config file couldn't be loaded; "" if it was
+	filter       FilterState
+	doc          DocState
+	progress     ProgressState
+	main         MainState
+	marketplaces MarketplacesState
+	views        ViewsState
+	extras       ExtrasState
+	mode         Mode
+	staleAfter   time.Duration // Marketplaces not updated for this long are highlighted; 0 disables
+	height       int
+	width        int
+	selectedIdx  int
+	listOffset   int
 }
 
 // NewModel creates a new Model with the given client and working directory.
@@ -345,17 +437,31 @@ func NewModelWithTheme(client claude.Client, workingDir string, theme Theme) *Mo
 		progress: ProgressState{
 			loading: true,
 		},
+		staleAfter: DefaultStaleAfter,
 	}
 }
 
+// DefaultStaleAfter is how old a marketplace clone gets before it is highlighted as stale.
+const DefaultStaleAfter = 7 * 24 * time.Hour
+
+// SetStaleAfter sets how old a marketplace clone gets before it is
+// highlighted as stale. Zero turns highlighting off.
+func (m *Model) SetStaleAfter(d time.Duration) {
+	m.staleAfter = d
+}
+
 // Init implements tea.Model.
 func (m *Model) Init() tea.Cmd {
-	return m.loadPlugins
+	return tea.Batch(m.loadPlugins, m.watchFiles())
 }
 
 // pluginsLoadedMsg is sent when plugins are loaded.
 type pluginsLoadedMsg struct {
-	plugins []PluginState
+	stamps   fileStamps                         // Watched files as of just before loading
+	catalogs map[string]*claude.Marketplace     // Catalogues the plugins were enriched from
+	known    map[string]claude.KnownMarketplace // Known marketplaces, for their ages
+	notice   string                             // Set when the list was built without the CLI
+	plugins  []PluginState
 }
 
 // pluginsErrorMsg is sent when loading fails.
@@ -363,9 +469,17 @@ type pluginsErrorMsg struct {
 	err error
 }
 
+// diskUsageMsg is sent when plugin disk usage has been measured.
+type diskUsageMsg struct {
+	byPath map[string]claude.DirUsage
+	total  *claude.PluginsUsage
+}
+
 // Operation represents a pending change to execute.
 type Operation struct {
 	PluginID        string
+	Obsolete        string                // Why a change on disk made this operation unnecessary; "" if it still applies
+	NeedsCLI        string                // Why this operation can't run without the claude CLI; "" if it can
 	Scopes          []claude.Scope        // Target scopes for this operation
 	OriginalScopes  map[claude.Scope]bool // Scopes plugin was at before this operation
 	UninstallScopes []claude.Scope        // For OpScopeChange: scopes to remove
@@ -378,15 +492,150 @@ type operationDoneMsg struct {
 	op  Operation
 }
 
-// loadPlugins fetches plugin data from the Claude CLI.
+// loadPlugins fetches plugin data from the Claude CLI. If the CLI call fails,
+// plugins are listed from the cloned marketplace catalogues instead.
 func (m *Model) loadPlugins() tea.Msg {
+	stamps := statFiles(claude.WatchedFiles(m.workingDir))
+	catalogs := claude.ReadMarketplaceCatalogs()
+
+	var notices []string
+	if _, ok := m.client.(claude.OfflineClient); ok {
+		notices = append(notices, "Offline: claude CLI unavailable, changes edit enabledPlugins directly; installs of uncached plugins and updates need the CLI")
+	}
 	list, err := m.client.ListPlugins(true)
 	if err != nil {
-		return pluginsErrorMsg{err: err}
+		if len(catalogs) == 0 {
+			return pluginsErrorMsg{err: err}
+		}
+		list = offlinePluginList(catalogs, claude.GetAllEnabledPlugins(m.workingDir), m.workingDir)
+		notices = append(notices, "Offline: listing plugins from marketplace catalogues (claude plugin list failed)")
+	}
+	for _, settingsErr := range claude.CheckSettings(m.workingDir) {
+		notices = append(notices, settingsWarning(settingsErr, m.workingDir))
+	}
+	if m.prefsWarning != "" {
+		notices = append(notices, m.prefsWarning)
 	}
 
 	plugins := mergePlugins(list, m.workingDir)
-	return pluginsLoadedMsg{plugins: plugins}
+	applyCatalogs(plugins, catalogs)
+	markConflicts(plugins, claude.GetAllEnabledPlugins(m.workingDir))
+	known, _ := claude.ReadKnownMarketplaces() // Ages are left out if it can't be read
+	return pluginsLoadedMsg{plugins: plugins, catalogs: catalogs, known: known, notice: strings.Join(notices, " • "), stamps: stamps}
+}
+
+// settingsWarning describes an unparseable settings file whose plugins are being ignored.
+func settingsWarning(e claude.SettingsError, workingDir string) string {
+	return fmt.Sprintf("Warning: %s settings ignored, can't parse %s: %v", e.Scope, displayPath(e.Path, workingDir), e.Err)
+}
+
+// displayPath shortens a path for display: relative to workingDir when inside
+// it, otherwise with the home directory written as ~.
+func displayPath(path, workingDir string) string {
+	if rel, err := filepath.Rel(workingDir, path); err == nil && filepath.IsLocal(rel) {
+		return rel
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, path); err == nil && filepath.IsLocal(rel) {
+			return filepath.Join("~", rel)
+		}
+	}
+	return path
+}
+
+// measureDiskUsage returns a command that measures each installed plugin's
+// directory and the plugins directory as a whole. Walking node_modules-heavy
+// plugins can take a while, so this runs off the UI thread.
+func measureDiskUsage(plugins []PluginState) tea.Cmd {
+	var paths []string
+	for _, p := range plugins {
+		if p.InstallPath != "" && !slices.Contains(paths, p.InstallPath) {
+			paths = append(paths, p.InstallPath)
+		}
+	}
+
+	return func() tea.Msg {
+		byPath := make(map[string]claude.DirUsage, len(paths))
+		for _, path := range paths {
+			if usage, err := claude.MeasureDir(path); err == nil {
+				byPath[path] = usage
+			}
+		}
+		msg := diskUsageMsg{byPath: byPath}
+		if total, err := claude.MeasurePluginsDir(); err == nil {
+			msg.total = &total
+		}
+		return msg
+	}
+}
+
+// applyDiskUsage sets each plugin's disk usage from the measured install paths.
+func (m *Model) applyDiskUsage() {
+	for i := range m.plugins {
+		if usage, ok := m.diskUsage[m.plugins[i].InstallPath]; ok {
+			m.plugins[i].DiskUsage = &usage
+		}
+	}
+}
+
+// offlinePluginList builds a plugin list from marketplace catalogues and the
+// settings files, for use when the CLI is unavailable. Install paths are unknown,
+// so installed plugins carry only their scope and enabled state.
+func offlinePluginList(catalogs map[string]*claude.Marketplace, scopes claude.ScopeState, workingDir string) *claude.PluginList {
+	list := &claude.PluginList{}
+	for _, name := range slices.Sorted(maps.Keys(catalogs)) {
+		list.Available = append(list.Available, catalogs[name].AvailablePlugins(name)...)
+	}
+	for _, id := range slices.Sorted(maps.Keys(scopes)) {
+		for _, scope := range scopeOrder {
+			enabled, ok := scopes[id][scope]
+			if !ok {
+				continue
+			}
+			installed := claude.InstalledPlugin{ID: id, Scope: scope, Enabled: enabled}
+			if scope != claude.ScopeUser {
+				installed.ProjectPath = workingDir
+			}
+			list.Installed = append(list.Installed, installed)
+		}
+	}
+	return list
+}
+
+// markConflicts records, on each plugin, the skill, agent, command, and MCP
+// server names it shares with other installed plugins that are enabled in the
+// effective scope set, matching what "cpm conflicts" reports.
+func markConflicts(plugins []PluginState, scopes claude.ScopeState) {
+	components := make(map[string]*claude.PluginComponents)
+	for _, p := range plugins {
+		if !p.IsGroupHeader && p.IsInstalled() && scopes.Enabled(p.ID) {
+			components[p.ID] = p.Components
+		}
+	}
+
+	byPlugin := claude.ConflictsByPlugin(claude.FindConflicts(components))
+	for i := range plugins {
+		plugins[i].Conflicts = byPlugin[plugins[i].ID]
+	}
+}
+
+// applyCatalogs enriches plugins and group headers with marketplace catalogue metadata.
+func applyCatalogs(plugins []PluginState, catalogs map[string]*claude.Marketplace) {
+	for i := range plugins {
+		catalog, ok := catalogs[plugins[i].Marketplace]
+		if !ok {
+			continue
+		}
+		if plugins[i].IsGroupHeader {
+			plugins[i].Description = catalog.Description
+			plugins[i].AuthorName = catalog.Owner.Name
+			plugins[i].AuthorEmail = catalog.Owner.Email
+			continue
+		}
+		if entry, ok := catalog.Plugin(plugins[i].Name); ok {
+			plugins[i].applyCatalog(entry)
+		}
+	}
 }
 
 // mergePlugins combines installed and available plugins, grouped by marketplace.
@@ -454,6 +703,7 @@ func processAvailablePlugin(p claude.AvailablePlugin, installedByID map[string]c
 	// Apply scope data from settings files
 	if scopes, ok := allScopes[p.PluginID]; ok {
 		state.InstalledScopes = scopes
+		state.applyManagedScope()
 	}
 
 	return state
@@ -473,8 +723,7 @@ func mergeInstalledInfo(state *PluginState, installed claude.InstalledPlugin, av
 	// Read manifest and scan components
 	if installed.InstallPath != "" {
 		if manifest, err := claude.ReadPluginManifest(installed.InstallPath); err == nil {
-			state.AuthorName = manifest.AuthorName
-			state.AuthorEmail = manifest.AuthorEmail
+			state.applyManifest(manifest)
 		}
 		state.Components = claude.ScanPluginComponents(installed.InstallPath)
 	}
@@ -499,6 +748,7 @@ func processInstalledPlugin(p claude.InstalledPlugin, allScopes claude.ScopeStat
 		} else if p.Scope == claude.ScopeUser {
 			state.InstalledScopes = map[claude.Scope]bool{claude.ScopeUser: true}
 		}
+		state.applyManagedScope()
 		return state, true
 	}
 
@@ -514,8 +764,7 @@ func processInstalledPlugin(p claude.InstalledPlugin, allScopes claude.ScopeStat
 	if p.InstallPath != "" {
 		if manifest, err := claude.ReadPluginManifest(p.InstallPath); err == nil {
 			state.Description = manifest.Description
-			state.AuthorName = manifest.AuthorName
-			state.AuthorEmail = manifest.AuthorEmail
+			state.applyManifest(manifest)
 		}
 		state.Components = claude.ScanPluginComponents(p.InstallPath)
 	}
@@ -589,11 +838,11 @@ func (m *Model) toggleEnablement() {
 	}
 
 	// Create enable/disable operation
-	m.main.pendingOps[plugin.ID] = Operation{
+	m.queueOp(Operation{
 		PluginID: plugin.ID,
 		Scopes:   []claude.Scope{plugin.SingleScope()},
 		Type:     opType,
-	}
+	})
 }
 
 // openScopeDialog transitions to the scope dialog for the given plugin.
@@ -633,14 +882,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case pluginsLoadedMsg:
 		m.progress.loading = false
 		m.plugins = msg.plugins
-		// Skip to first non-header item
-		for i, p := range m.plugins {
-			if !p.IsGroupHeader {
-				m.selectedIdx = i
-				break
-			}
-		}
-		return m, nil
+		m.catalogs = msg.catalogs
+		m.known = msg.known
+		m.main.notice = msg.notice
+		m.watched = msg.stamps
+		m.applyDiskUsage()
+		_ = m.computeView() // A saved query that doesn't parse lists all plugins
+		m.selectFirstNonHeader()
+		return m, measureDiskUsage(m.plugins)
 
 	case pluginsErrorMsg:
 		m.progress.loading = false
@@ -648,6 +897,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if cmd, ok := m.updateBackground(msg); ok {
+		return m, cmd
+	}
+
 	// Handle confirmation dialog
 	if m.main.showConfirm {
 		return m.updateConfirmation(msg)
@@ -670,11 +923,42 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateDoc(msg)
 	case ModeConfig:
 		return m.updateConfig(msg)
+	case ModeExtras:
+		return m.updateExtras(msg)
+	case ModeMarketplaces:
+		return m.updateMarketplaces(msg)
+	case ModeViews:
+		return m.updateViews(msg)
 	}
 
 	return m, nil
 }
 
+// updateBackground handles results of background work, which arrive in any
+// mode. It reports whether msg was one of them.
+func (m *Model) updateBackground(msg tea.Msg) (tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case diskUsageMsg:
+		m.diskUsage = msg.byPath
+		m.cacheUsage = msg.total
+		m.applyDiskUsage()
+		if m.main.sortMode == SortBySize {
+			m.sortPlugins()
+		}
+		return nil, true
+
+	case watchTickMsg:
+		return m.handleWatchTick(msg), true
+
+	case pluginsRefreshedMsg:
+		return m.applyRefresh(msg), true
+
+	case marketplaceDoneMsg:
+		return m.handleMarketplaceDone(msg), true
+	}
+	return nil, false
+}
+
 // View implements tea.Model.
 func (m *Model) View() string {
 	if m.progress.loading {
@@ -708,6 +992,12 @@ func (m *Model) View() string {
 		return m.renderDoc(m.styles)
 	case ModeConfig:
 		return m.renderConfig(m.styles)
+	case ModeExtras:
+		return m.renderExtras(m.styles)
+	case ModeMarketplaces:
+		return m.renderMarketplaces(m.styles)
+	case ModeViews:
+		return m.renderViews(m.styles)
 	}
 
 	return ""
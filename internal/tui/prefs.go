@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/open-cli-collective/cpm/internal/config"
+)
+
+// UseConfig loads cpm's preferences from path and saves changes to them
+// there. Without it, preferences last only for the session. A config file that
+// can't be read is left untouched and reported in the notice, and the session
+// starts from empty preferences.
+func (m *Model) UseConfig(path string) {
+	prefs, err := config.Load(path)
+	if err != nil {
+		m.prefsWarning = fmt.Sprintf("Ignoring %s, preferences won't be saved: %v", displayPath(path, m.workingDir), err)
+		return
+	}
+	m.prefs = *prefs
+	m.prefsPath = path
+	m.main.collapsed = make(map[string]bool, len(prefs.CollapsedGroups))
+	for _, name := range prefs.CollapsedGroups {
+		m.main.collapsed[name] = true
+	}
+}
+
+// savePrefs writes the preferences to the config file, reporting failures in
+// the notice.
+func (m *Model) savePrefs() {
+	m.prefs.CollapsedGroups = slices.Sorted(maps.Keys(m.main.collapsed))
+	if m.prefsPath == "" {
+		return
+	}
+	if err := m.prefs.Save(m.prefsPath); err != nil {
+		m.main.notice = fmt.Sprintf("Couldn't save %s: %v", displayPath(m.prefsPath, m.workingDir), err)
+	}
+}
@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMalformedConfigIgnored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"views": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := marketplacesTestModel(&mockClient{})
+	m.UseConfig(path)
+
+	loaded, ok := m.loadPlugins().(pluginsLoadedMsg)
+	if !ok || !strings.Contains(loaded.notice, "Ignoring "+path) {
+		t.Errorf("loaded = %+v, want the config warning in the notice", loaded)
+	}
+
+	// Preferences still work for the session but don't overwrite the file
+	sendKeys(m, "z")
+	if !m.main.collapsed["alpha"] {
+		t.Error("z didn't fold alpha")
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"views": [` {
+		t.Errorf("config = %s, want it untouched", data)
+	}
+}
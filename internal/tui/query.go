@@ -0,0 +1,256 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+// query is a parsed filter: qualifiers a plugin must satisfy, plus free text
+// fuzzy-matched against its name, description, and ID.
+//
+// Words of the form key:value are qualifiers, such as scope:project or
+// has:hooks; a leading - negates one, and -word excludes plugins mentioning
+// word. Values with spaces go in double quotes: author:"Jane Doe".
+type query struct {
+	text  string // Fuzzy text; "" matches every plugin the terms allow
+	terms []queryTerm
+}
+
+// queryTerm is one qualifier or excluded word of a query.
+type queryTerm struct {
+	match  func(p *PluginState) bool
+	negate bool
+}
+
+// queryQualifier parses the value of one kind of qualifier into a matcher.
+type queryQualifier func(value string) (func(p *PluginState) bool, error)
+
+// queryQualifiers maps qualifier keys to their parsers.
+var queryQualifiers = map[string]queryQualifier{
+	"scope":       parseScopeQualifier,
+	"mkt":         parseMarketplaceQualifier,
+	"marketplace": parseMarketplaceQualifier,
+	"has":         parseHasQualifier,
+	"status":      parseStatusQualifier,
+	"is":          parseIsQualifier,
+	"author":      parseAuthorQualifier,
+}
+
+// queryKeys lists the qualifier keys for error messages.
+const queryKeys = "scope, mkt, has, status, is, author"
+
+// parseQuery parses filter text into a query.
+func parseQuery(s string) (query, error) {
+	words, err := splitQuery(s)
+	if err != nil {
+		return query{}, err
+	}
+
+	var q query
+	var text []string
+	for _, word := range words {
+		negate := false
+		if rest, ok := strings.CutPrefix(word, "-"); ok && rest != "" {
+			word, negate = rest, true
+		}
+		key, value, ok := strings.Cut(word, ":")
+		if !ok {
+			if negate {
+				q.terms = append(q.terms, queryTerm{match: mentions(strings.ToLower(word)), negate: true})
+			} else {
+				text = append(text, word)
+			}
+			continue
+		}
+
+		parse, known := queryQualifiers[strings.ToLower(key)]
+		if !known {
+			return query{}, fmt.Errorf("unknown qualifier %q (use %s)", key+":", queryKeys)
+		}
+		if value == "" {
+			return query{}, fmt.Errorf("%s: needs a value", key)
+		}
+		match, err := parse(value)
+		if err != nil {
+			return query{}, fmt.Errorf("%s: %w", key, err)
+		}
+		q.terms = append(q.terms, queryTerm{match: match, negate: negate})
+	}
+	q.text = strings.Join(text, " ")
+	return q, nil
+}
+
+// splitQuery splits filter text into words at spaces outside double quotes,
+// removing the quotes.
+func splitQuery(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inQuotes, inWord := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes, inWord = !inQuotes, true
+		case r == ' ' && !inQuotes:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			inWord = false
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inQuotes {
+		return nil, errors.New("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// matches reports whether p satisfies every term of the query. The fuzzy
+// text is matched separately, since it ranks the results.
+func (q query) matches(p *PluginState) bool {
+	for _, term := range q.terms {
+		if term.match(p) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// searchText returns the lowercase text the fuzzy filter matches against.
+func searchText(p *PluginState) string {
+	return strings.ToLower(p.Name + " " + p.Description + " " + p.ID)
+}
+
+// mentions returns a matcher for plugins whose search text contains word,
+// which must be lowercase.
+func mentions(word string) func(p *PluginState) bool {
+	return func(p *PluginState) bool { return strings.Contains(searchText(p), word) }
+}
+
+// containsFold returns a matcher for plugins where field contains value,
+// ignoring case.
+func containsFold(value string, field func(p *PluginState) string) func(p *PluginState) bool {
+	value = strings.ToLower(value)
+	return func(p *PluginState) bool { return strings.Contains(strings.ToLower(field(p)), value) }
+}
+
+// pickOption returns the option value names or abbreviates, so qualifiers
+// can be used while still being typed.
+func pickOption(value string, options []string) (string, error) {
+	value = strings.ToLower(value)
+	var found []string
+	for _, option := range options {
+		if option == value {
+			return option, nil
+		}
+		if strings.HasPrefix(option, value) {
+			found = append(found, option)
+		}
+	}
+	if len(found) == 1 {
+		return found[0], nil
+	}
+	return "", fmt.Errorf("%q isn't one of %s", value, strings.Join(options, ", "))
+}
+
+// parseScopeQualifier matches plugins installed at a scope, managed by
+// policy, or, for none, not installed anywhere.
+func parseScopeQualifier(value string) (func(p *PluginState) bool, error) {
+	option, err := pickOption(value, []string{"user", "project", "local", "managed", "none"})
+	if err != nil {
+		return nil, err
+	}
+	switch option {
+	case "managed":
+		return func(p *PluginState) bool { return p.Managed != nil }, nil
+	case "none":
+		return func(p *PluginState) bool { return !p.IsInstalled() }, nil
+	default:
+		scope := claude.Scope(option)
+		return func(p *PluginState) bool { return p.HasScope(scope) }, nil
+	}
+}
+
+// parseMarketplaceQualifier matches plugins from marketplaces whose name
+// contains value.
+func parseMarketplaceQualifier(value string) (func(p *PluginState) bool, error) {
+	return containsFold(value, func(p *PluginState) string { return p.Marketplace }), nil
+}
+
+// parseAuthorQualifier matches plugins whose author name or email contains value.
+func parseAuthorQualifier(value string) (func(p *PluginState) bool, error) {
+	return containsFold(value, func(p *PluginState) string { return p.AuthorName + " " + p.AuthorEmail }), nil
+}
+
+// parseHasQualifier matches plugins that come with a kind of component, or
+// that have name conflicts.
+func parseHasQualifier(value string) (func(p *PluginState) bool, error) {
+	option, err := pickOption(value, []string{"mcp", "hooks", "skills", "agents", "commands", "conflicts"})
+	if err != nil {
+		return nil, err
+	}
+	if option == "conflicts" {
+		return func(p *PluginState) bool { return len(p.Conflicts) > 0 }, nil
+	}
+	return func(p *PluginState) bool {
+		c := p.Components
+		if c == nil {
+			return false
+		}
+		switch option {
+		case "mcp":
+			return len(c.MCPs) > 0
+		case "hooks":
+			return len(c.Hooks) > 0
+		case "skills":
+			return len(c.Skills) > 0
+		case "agents":
+			return len(c.Agents) > 0
+		default:
+			return len(c.Commands) > 0
+		}
+	}, nil
+}
+
+// parseStatusQualifier matches plugins by whether they are installed and enabled.
+func parseStatusQualifier(value string) (func(p *PluginState) bool, error) {
+	option, err := pickOption(value, []string{"enabled", "disabled", "installed", "available"})
+	if err != nil {
+		return nil, err
+	}
+	switch option {
+	case "enabled":
+		return func(p *PluginState) bool { return p.IsInstalled() && p.Enabled }, nil
+	case "disabled":
+		return func(p *PluginState) bool { return p.IsInstalled() && !p.Enabled }, nil
+	case "installed":
+		return func(p *PluginState) bool { return p.IsInstalled() }, nil
+	default:
+		return func(p *PluginState) bool { return !p.IsInstalled() }, nil
+	}
+}
+
+// parseIsQualifier matches plugins by a property: outdated (an update is
+// available), external (hosted outside its marketplace), or blocked by policy.
+func parseIsQualifier(value string) (func(p *PluginState) bool, error) {
+	option, err := pickOption(value, []string{"outdated", "external", "blocked"})
+	if err != nil {
+		return nil, err
+	}
+	switch option {
+	case "outdated":
+		return func(p *PluginState) bool { return p.HasUpdate }, nil
+	case "external":
+		return func(p *PluginState) bool { return p.IsExternal }, nil
+	default:
+		return func(p *PluginState) bool { return p.Managed != nil && !*p.Managed }, nil
+	}
+}
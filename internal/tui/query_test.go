@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+// queryTestPlugins returns plugins covering the states qualifiers test.
+func queryTestPlugins() []PluginState {
+	blocked := false
+	return []PluginState{
+		{
+			ID: "lint@acme", Name: "lint", Marketplace: "acme", AuthorName: "Jane Doe", Enabled: true,
+			InstalledScopes: map[claude.Scope]bool{claude.ScopeProject: true},
+			Components:      &claude.PluginComponents{Hooks: []claude.Hook{{Event: "PreToolUse"}}},
+		},
+		{
+			ID: "db@acme", Name: "db", Marketplace: "acme", Description: "Database tools", HasUpdate: true,
+			InstalledScopes: map[claude.Scope]bool{claude.ScopeUser: false},
+			Components:      &claude.PluginComponents{MCPs: []claude.MCPServer{{Name: "postgres"}}},
+		},
+		{
+			ID: "docs@tools", Name: "docs", Marketplace: "tools", AuthorEmail: "bob@example.com",
+			InstalledScopes: map[claude.Scope]bool{}, IsExternal: true,
+		},
+		{
+			ID: "evil@tools", Name: "evil", Marketplace: "tools", Managed: &blocked,
+			InstalledScopes: map[claude.Scope]bool{},
+		},
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	plugins := queryTestPlugins()
+	tests := []struct {
+		query    string
+		wantText string
+		want     []string
+	}{
+		{"scope:project", "", []string{"lint"}},
+		{"scope:none", "", []string{"docs", "evil"}},
+		{"scope:u", "", []string{"db"}},
+		{"scope:managed", "", []string{"evil"}},
+		{"mkt:ACM", "", []string{"lint", "db"}},
+		{"has:mcp", "", []string{"db"}},
+		{"scope:project has:hooks", "", []string{"lint"}},
+		{"status:disabled", "", []string{"db"}},
+		{"status:available", "", []string{"docs", "evil"}},
+		{"is:outdated", "", []string{"db"}},
+		{"is:external", "", []string{"docs"}},
+		{"is:blocked", "", []string{"evil"}},
+		{`author:"jane doe"`, "", []string{"lint"}},
+		{"author:example", "", []string{"docs"}},
+		{"-mkt:acme", "", []string{"docs", "evil"}},
+		{"-database", "", []string{"lint", "docs", "evil"}},
+		{"mkt:acme data base", "data base", []string{"lint", "db"}},
+		{"  ", "", []string{"lint", "db", "docs", "evil"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := parseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parseQuery() error = %v", err)
+			}
+			if q.text != tt.wantText {
+				t.Errorf("text = %q, want %q", q.text, tt.wantText)
+			}
+			var got []string
+			for i := range plugins {
+				if q.matches(&plugins[i]) {
+					got = append(got, plugins[i].Name)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{"color:red", `unknown qualifier "color:"`},
+		{"scope:", "scope: needs a value"},
+		{"scope:global", `scope: "global" isn't one of user, project, local, managed, none`},
+		{"status:", "status: needs a value"},
+		{"has:c", `has: "c" isn't one of`}, // Ambiguous: commands or conflicts
+		{`author:"jane`, "unterminated quote"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseQuery(tt.query)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseQuery(%q) error = %v, want containing %q", tt.query, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFilterQueryError(t *testing.T) {
+	m := NewModel(&mockClient{}, "/test/project")
+	m.progress.loading = false
+	m.plugins = queryTestPlugins()
+
+	sendKeys(m, "/", "m", "k", "t", ":", "t")
+	if len(m.filteredIdx) != 2 || m.filter.err != "" {
+		t.Fatalf("filteredIdx = %v, err = %q, want the tools plugins", m.filteredIdx, m.filter.err)
+	}
+
+	// Typing on into an unknown qualifier keeps the last matches and shows why
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	sendKeys(m, "o", ":")
+	if len(m.filteredIdx) != 2 {
+		t.Errorf("filteredIdx = %v, want the previous matches kept", m.filteredIdx)
+	}
+	if !strings.Contains(m.renderFilterInput(m.styles), `unknown qualifier "o:"`) {
+		t.Errorf("filter bar = %q, want the parse error", m.renderFilterInput(m.styles))
+	}
+
+	sendKeys(m, "backspace", "backspace")
+	if m.filter.err != "" || m.filter.text != "mkt:t " {
+		t.Errorf("after backspace err = %q, text = %q", m.filter.err, m.filter.text)
+	}
+}
@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/open-cli-collective/cpm/internal/claude"
+	"github.com/open-cli-collective/cpm/internal/textdiff"
+)
+
+// settingsDiff previews the edits pending operations make to one settings file.
+type settingsDiff struct {
+	path string // Path for display
+	diff string // Unified diff of the file's contents
+	err  string // Why the file couldn't be previewed
+}
+
+// openConfirmation shows the confirmation dialog with a preview of the
+// settings file edits the pending operations will make.
+func (m *Model) openConfirmation() {
+	m.main.showConfirm = true
+	m.main.settingsDiffs = m.previewSettings()
+}
+
+// previewSettings diffs each settings file the pending operations touch
+// against its contents after they run. Project and local files include the
+// extraKnownMarketplaces changes synced once the operations finish.
+func (m *Model) previewSettings() []settingsDiff {
+	ops := slices.Collect(maps.Values(m.main.pendingOps))
+	sortForExecution(ops)
+	changes := settingsChanges(ops, claude.GetAllEnabledPlugins(m.workingDir))
+	synced := affectedScopes(ops)
+
+	known, err := claude.ReadKnownMarketplaces()
+	if err != nil {
+		known = nil // Syncing is skipped when known marketplaces can't be read
+	} else if known == nil {
+		known = map[string]claude.KnownMarketplace{}
+	}
+
+	var diffs []settingsDiff
+	for _, scope := range []claude.Scope{claude.ScopeUser, claude.ScopeProject, claude.ScopeLocal} {
+		if len(changes[scope]) == 0 && !synced[scope] {
+			continue
+		}
+		path := claude.SettingsPathForScope(m.workingDir, scope)
+		sync := known
+		if scope == claude.ScopeUser {
+			path = claude.UserSettingsPath()
+			sync = nil // Only project and local settings are synced
+		}
+		if path == "" {
+			continue
+		}
+
+		name := displayPath(path, m.workingDir)
+		before, after, err := claude.PreviewSettings(path, changes[scope], sync, m.prefs.ExtraMarketplaces[path])
+		if err != nil {
+			diffs = append(diffs, settingsDiff{path: name, err: err.Error()})
+			continue
+		}
+		if diff := textdiff.Unified(name, name, string(before), string(after)); diff != "" {
+			diffs = append(diffs, settingsDiff{path: name, diff: diff})
+		}
+	}
+	return diffs
+}
+
+// settingsChanges returns the enabledPlugins edits each scope's settings file
+// receives when ops run in order, mirroring runOperation. allScopes is the
+// current settings state, which decides between uninstalling and disabling.
+// Updates don't change settings.
+func settingsChanges(ops []Operation, allScopes claude.ScopeState) map[claude.Scope][]claude.SettingsChange {
+	changes := make(map[claude.Scope][]claude.SettingsChange)
+	set := func(id string, scope claude.Scope, enabled bool) {
+		changes[scope] = append(changes[scope], claude.SettingsChange{PluginID: id, Enabled: enabled})
+	}
+	remove := func(id string, scope claude.Scope) {
+		changes[scope] = append(changes[scope], claude.SettingsChange{PluginID: id, Remove: true})
+	}
+
+	for _, op := range ops {
+		exists := func(scope claude.Scope) bool {
+			_, ok := allScopes[op.PluginID][scope]
+			return ok
+		}
+		switch op.Type {
+		case OpInstall, OpEnable, OpDisable:
+			for _, scope := range op.Scopes {
+				set(op.PluginID, scope, op.Type != OpDisable)
+			}
+		case OpUninstall:
+			for _, scope := range op.Scopes {
+				if exists(scope) {
+					remove(op.PluginID, scope)
+				} else {
+					set(op.PluginID, scope, false)
+				}
+			}
+		case OpMigrate:
+			remove(op.PluginID, firstScope(op.OriginalScopes))
+			if len(op.Scopes) > 0 {
+				set(op.PluginID, op.Scopes[0], true)
+			}
+		case OpScopeChange:
+			for _, scope := range op.UninstallScopes {
+				if exists(scope) {
+					remove(op.PluginID, scope)
+				}
+			}
+			for _, scope := range op.Scopes {
+				set(op.PluginID, scope, true)
+			}
+		}
+	}
+	return changes
+}
+
+// renderSettingsDiffs renders the settings file previews for the confirmation
+// dialog, showing at most limit lines (no limit when limit <= 0) and lines at
+// most width wide (no limit when width <= 0).
+func renderSettingsDiffs(diffs []settingsDiff, styles Styles, limit, width int) []string {
+	if len(diffs) == 0 {
+		return []string{styles.Help.Render("No settings files change")}
+	}
+
+	var raw []string
+	for _, d := range diffs {
+		if d.err != "" {
+			raw = append(raw, fmt.Sprintf("%s: can't preview: %s", d.path, d.err))
+			continue
+		}
+		raw = append(raw, strings.Split(strings.TrimSuffix(d.diff, "\n"), "\n")...)
+	}
+
+	shown := raw
+	if limit > 0 && len(raw) > limit {
+		shown = raw[:max(limit-1, 1)]
+	}
+	lines := make([]string, 0, len(shown)+1)
+	for _, line := range shown {
+		style := diffLineStyle(line, styles)
+		if width > 0 && len([]rune(line)) > width {
+			line = string([]rune(line)[:width-1]) + "…"
+		}
+		lines = append(lines, style.Render(line))
+	}
+	if hidden := len(raw) - len(shown); hidden > 0 {
+		lines = append(lines, styles.Help.Render(fmt.Sprintf("… %d more lines", hidden)))
+	}
+	return lines
+}
+
+// diffLineStyle picks the style for a line of a unified diff. Other lines
+// are preview errors.
+func diffLineStyle(line string, styles Styles) lipgloss.Style {
+	switch {
+	case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+		return styles.DetailLabel
+	case strings.HasPrefix(line, "@@"):
+		return styles.DiffHunk
+	case strings.HasPrefix(line, "+"):
+		return styles.DiffAdded
+	case strings.HasPrefix(line, "-"):
+		return styles.DiffRemoved
+	case strings.HasPrefix(line, " "):
+		return styles.Normal
+	default:
+		return styles.Pending
+	}
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+func TestSettingsChanges(t *testing.T) {
+	allScopes := claude.ScopeState{
+		"listed@mp": {claude.ScopeProject: true},
+	}
+	ops := []Operation{
+		{PluginID: "listed@mp", Type: OpUninstall, Scopes: []claude.Scope{claude.ScopeProject}},
+		{PluginID: "unlisted@mp", Type: OpUninstall, Scopes: []claude.Scope{claude.ScopeLocal}},
+		{PluginID: "moved@mp", Type: OpMigrate, Scopes: []claude.Scope{claude.ScopeUser}, OriginalScopes: map[claude.Scope]bool{claude.ScopeLocal: true}},
+		{PluginID: "updated@mp", Type: OpUpdate, Scopes: []claude.Scope{claude.ScopeProject}},
+		{PluginID: "new@mp", Type: OpInstall, Scopes: []claude.Scope{claude.ScopeProject}},
+		{PluginID: "off@mp", Type: OpDisable, Scopes: []claude.Scope{claude.ScopeUser}},
+	}
+
+	got := settingsChanges(ops, allScopes)
+	want := map[claude.Scope][]claude.SettingsChange{
+		claude.ScopeProject: {
+			{PluginID: "listed@mp", Remove: true},
+			{PluginID: "new@mp", Enabled: true},
+		},
+		claude.ScopeLocal: {
+			{PluginID: "unlisted@mp", Enabled: false},
+			{PluginID: "moved@mp", Remove: true},
+		},
+		claude.ScopeUser: {
+			{PluginID: "moved@mp", Enabled: true},
+			{PluginID: "off@mp", Enabled: false},
+		},
+	}
+	for scope, changes := range want {
+		if len(got[scope]) != len(changes) {
+			t.Errorf("%s changes = %+v, want %+v", scope, got[scope], changes)
+			continue
+		}
+		for i := range changes {
+			if got[scope][i] != changes[i] {
+				t.Errorf("%s changes[%d] = %+v, want %+v", scope, i, got[scope][i], changes[i])
+			}
+		}
+	}
+}
+
+func TestOpenConfirmationPreviewsSettings(t *testing.T) {
+	setupKnownMarketplaces(t, `{"mp": {"source": {"source": "github", "repo": "owner/mp"}}}`)
+
+	work := t.TempDir()
+	projectPath := filepath.Join(work, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(projectPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	original := "{\n  \"enabledPlugins\": {}\n}\n"
+	if err := os.WriteFile(projectPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel(&mockClient{}, work)
+	m.progress.loading = false
+	m.main.pendingOps["new@mp"] = Operation{PluginID: "new@mp", Type: OpInstall, Scopes: []claude.Scope{claude.ScopeProject}}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.main.showConfirm {
+		t.Fatal("Enter should open the confirmation dialog")
+	}
+	if len(m.main.settingsDiffs) != 1 {
+		t.Fatalf("settingsDiffs = %+v, want one file", m.main.settingsDiffs)
+	}
+	d := m.main.settingsDiffs[0]
+	if d.path != filepath.Join(".claude", "settings.json") {
+		t.Errorf("path = %q, want .claude/settings.json", d.path)
+	}
+	for _, want := range []string{`+    "new@mp": true`, `+  "extraKnownMarketplaces": {`, `"owner/mp"`} {
+		if !strings.Contains(d.diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, d.diff)
+		}
+	}
+
+	if got, _ := os.ReadFile(projectPath); string(got) != original {
+		t.Errorf("settings = %s, preview should not write", got)
+	}
+	if view := m.renderConfirmation(m.styles); !strings.Contains(view, "Intended settings changes") || !strings.Contains(view, "new@mp") {
+		t.Errorf("confirmation should show the intended settings changes:\n%s", view)
+	}
+
+	// The offline client writes the files exactly as previewed
+	m.client = &mockOfflineClient{}
+	if view := m.renderConfirmation(m.styles); !strings.Contains(view, "Settings changes:") || strings.Contains(view, "Intended") {
+		t.Errorf("offline confirmation should show the settings diff as is:\n%s", view)
+	}
+}
+
+func TestRenderSettingsDiffs(t *testing.T) {
+	styles := DefaultStyles()
+
+	if got := renderSettingsDiffs(nil, styles, 0, 0); len(got) != 1 || !strings.Contains(got[0], "No settings files change") {
+		t.Errorf("no diffs = %q, want a note", got)
+	}
+
+	diffs := []settingsDiff{
+		{path: "a.json", diff: "--- a.json\n+++ a.json\n@@ -1 +1 @@\n-x\n+y\n"},
+		{path: "b.json", err: "parse settings: invalid JSON"},
+	}
+	if got := renderSettingsDiffs(diffs, styles, 0, 0); len(got) != 6 || !strings.Contains(got[5], "can't preview") {
+		t.Errorf("renderSettingsDiffs() = %q, want 5 diff lines and the error", got)
+	}
+
+	got := renderSettingsDiffs(diffs, styles, 3, 0)
+	if len(got) != 3 || !strings.Contains(got[2], "4 more lines") {
+		t.Errorf("limited = %q, want 2 lines and a count of the rest", got)
+	}
+
+	got = renderSettingsDiffs(diffs[:1], styles, 0, 6)
+	if !strings.Contains(got[0], "--- a…") {
+		t.Errorf("narrow = %q, want long lines truncated", got)
+	}
+}
@@ -90,6 +90,7 @@ type Styles struct {
 	ScopeLocal   lipgloss.Style
 	ScopeProject lipgloss.Style
 	ScopeUser    lipgloss.Style
+	ScopeManaged lipgloss.Style
 	Pending      lipgloss.Style
 
 	// Detail pane styles
@@ -102,6 +103,11 @@ type Styles struct {
 	ComponentCategory lipgloss.Style
 	ComponentItem     lipgloss.Style
 
+	// Settings diff styles
+	DiffAdded   lipgloss.Style
+	DiffRemoved lipgloss.Style
+	DiffHunk    lipgloss.Style
+
 	// Footer/help
 	Help lipgloss.Style
 
@@ -165,6 +171,10 @@ func DefaultStylesWithTheme(theme Theme) Styles {
 			Bold(true).
 			Foreground(p.User),
 
+		ScopeManaged: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(p.Primary),
+
 		Pending: lipgloss.NewStyle().
 			Foreground(p.Pending),
 
@@ -193,6 +203,15 @@ func DefaultStylesWithTheme(theme Theme) Styles {
 			Foreground(p.Text).
 			PaddingLeft(4),
 
+		DiffAdded: lipgloss.NewStyle().
+			Foreground(p.Project),
+
+		DiffRemoved: lipgloss.NewStyle().
+			Foreground(p.User),
+
+		DiffHunk: lipgloss.NewStyle().
+			Foreground(p.Secondary),
+
 		Help: lipgloss.NewStyle().
 			Foreground(p.Muted),
 	}
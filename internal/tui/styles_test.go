@@ -16,6 +16,7 @@ func TestStylesInitialized(_ *testing.T) {
 	_ = s.GroupHeader.Render("test")
 	_ = s.ScopeLocal.Render("LOCAL")
 	_ = s.ScopeProject.Render("PROJECT")
+	_ = s.ScopeManaged.Render("MANAGED")
 }
 
 func TestStylesDimensions(t *testing.T) {
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
 	"io/fs"
 	"maps"
@@ -22,6 +23,8 @@ const wheelScrollSpeed = 3
 func (m *Model) updateMain(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.main.status = ""
+
 		// Handle quit confirmation
 		if m.main.showQuitConfirm {
 			switch {
@@ -56,6 +59,9 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if matchesKey(msg, keys.Refresh) {
 		return m.handleRefreshKey()
 	}
+	if matchesKey(msg, keys.RefreshMarketplace) {
+		return m, m.refreshSelectedMarketplace()
+	}
 	if matchesKey(msg, keys.Mouse) {
 		return m.handleMouseToggle()
 	}
@@ -89,27 +95,59 @@ func (m *Model) handleRegularKeyPress(msg tea.KeyMsg, keys KeyBindings) {
 		m.handleOperationKeys(msg, keys)
 	case matchesKey(msg, keys.Sort):
 		m.cycleSortMode()
-	case matchesKey(msg, keys.Config):
-		m.openConfig()
+	case matchesKey(msg, keys.Config), matchesKey(msg, keys.Extras), matchesKey(msg, keys.Marketplaces),
+		matchesKey(msg, keys.Readme), matchesKey(msg, keys.Changelog),
+		matchesKey(msg, keys.Views), matchesKey(msg, keys.QuickView):
+		m.handleViewKeys(msg, keys)
 	case matchesKey(msg, keys.Enter):
 		if len(m.main.pendingOps) > 0 {
-			m.main.showConfirm = true
+			m.openConfirmation()
 		}
 	case matchesKey(msg, keys.Escape):
 		plugin := m.getSelectedPlugin()
 		if plugin != nil {
 			m.clearPending(plugin.ID)
 		}
-	case matchesKey(msg, keys.Readme):
-		m.openDoc(DocReadme)
-	case matchesKey(msg, keys.Changelog):
-		m.openDoc(DocChangelog)
 	case matchesKey(msg, keys.BulkToggle):
 		m.toggleBulkSelection()
 	case matchesKey(msg, keys.BulkAll):
 		m.selectAllPlugins()
 	case matchesKey(msg, keys.BulkNone):
 		m.deselectAllPlugins()
+	case matchesKey(msg, keys.Fold), matchesKey(msg, keys.FoldAll), matchesKey(msg, keys.BulkGroup):
+		m.handleGroupKeys(msg, keys)
+	}
+}
+
+// handleGroupKeys handles keys that act on a whole group of the list.
+func (m *Model) handleGroupKeys(msg tea.KeyMsg, keys KeyBindings) {
+	switch {
+	case matchesKey(msg, keys.Fold):
+		m.toggleGroup(m.groupHeaderOf(m.selectedIdx))
+	case matchesKey(msg, keys.FoldAll):
+		m.toggleAllGroups()
+	case matchesKey(msg, keys.BulkGroup):
+		m.toggleGroupSelection()
+	}
+}
+
+// handleViewKeys handles keys that open another view or switch saved views.
+func (m *Model) handleViewKeys(msg tea.KeyMsg, keys KeyBindings) {
+	switch {
+	case matchesKey(msg, keys.Config):
+		m.openConfig()
+	case matchesKey(msg, keys.Extras):
+		m.openExtras()
+	case matchesKey(msg, keys.Marketplaces):
+		m.openMarketplaces()
+	case matchesKey(msg, keys.Readme):
+		m.openDoc(DocReadme)
+	case matchesKey(msg, keys.Changelog):
+		m.openDoc(DocChangelog)
+	case matchesKey(msg, keys.Views):
+		m.openViews()
+	case matchesKey(msg, keys.QuickView):
+		m.activateViewNumber(int(msg.Runes[0] - '0'))
 	}
 }
 
@@ -149,6 +187,23 @@ func (m *Model) handleOperationKeys(msg tea.KeyMsg, keys KeyBindings) {
 	case matchesKey(msg, keys.Scope):
 		m.openScopeDialogForSelected()
 	}
+	m.dropPolicyOverriddenOps()
+}
+
+// dropPolicyOverriddenOps removes pending operations that managed policy would
+// override and explains why in the status line, leaving the notice intact.
+func (m *Model) dropPolicyOverriddenOps() {
+	for i := range m.plugins {
+		plugin := &m.plugins[i]
+		op, ok := m.main.pendingOps[plugin.ID]
+		if !ok {
+			continue
+		}
+		if reason := plugin.policyBlocks(op.Type); reason != "" {
+			m.clearPending(plugin.ID)
+			m.main.status = reason
+		}
+	}
 }
 
 // openScopeDialogForSelected opens the scope dialog for the currently selected plugin.
@@ -183,6 +238,7 @@ func (m *Model) updateScopeDialog(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.main.scopeDialog.scopes[m.main.scopeDialog.cursor] = !m.main.scopeDialog.scopes[m.main.scopeDialog.cursor]
 	case matchesKey(keyMsg, m.keys.Enter):
 		m.applyScopeDialogDelta()
+		m.dropPolicyOverriddenOps()
 		m.mode = ModeMain
 	case matchesKey(keyMsg, m.keys.Escape):
 		m.mode = ModeMain
@@ -224,31 +280,31 @@ func (m *Model) applyScopeDialogDelta() {
 	switch {
 	case len(uninstallScopes) > 0 && len(installScopes) == 0:
 		// Pure uninstall (partial or full)
-		m.main.pendingOps[dialog.pluginID] = Operation{
+		m.queueOp(Operation{
 			PluginID:       dialog.pluginID,
 			Scopes:         uninstallScopes,
 			OriginalScopes: maps.Clone(original),
 			Type:           OpUninstall,
-		}
+		})
 	case len(installScopes) > 0 && len(uninstallScopes) == 0:
 		// Pure install (adding scopes)
-		m.main.pendingOps[dialog.pluginID] = Operation{
+		m.queueOp(Operation{
 			PluginID:       dialog.pluginID,
 			Scopes:         installScopes,
 			OriginalScopes: maps.Clone(original),
 			Type:           OpInstall,
-		}
+		})
 	default:
 		// Mixed: both install and uninstall — use OpScopeChange
 		// This carries both install and uninstall scope lists.
 		// Phase 7 execution handles uninstalls first, then installs.
-		m.main.pendingOps[dialog.pluginID] = Operation{
+		m.queueOp(Operation{
 			PluginID:        dialog.pluginID,
 			Scopes:          installScopes,
 			UninstallScopes: uninstallScopes,
 			OriginalScopes:  maps.Clone(original),
 			Type:            OpScopeChange,
-		}
+		})
 	}
 }
 
@@ -271,25 +327,30 @@ func (m *Model) handleRefreshKey() (tea.Model, tea.Cmd) {
 func (m *Model) handleFilterKey() {
 	m.filter.active = true
 	m.filter.text = ""
+	m.filter.err = ""
 	m.filteredIdx = nil
 }
 
-// moveUp moves selection up, skipping group headers.
+// moveUp moves selection up, skipping expanded group headers and the
+// plugins of collapsed groups.
 func (m *Model) moveUp() {
-	for i := m.selectedIdx - 1; i >= 0; i-- {
-		if !m.plugins[i].IsGroupHeader {
-			m.selectedIdx = i
+	rows := m.listRows()
+	for pos := slices.Index(rows, m.selectedIdx) - 1; pos >= 0; pos-- {
+		if m.isSelectable(rows[pos]) {
+			m.selectedIdx = rows[pos]
 			m.ensureVisible()
 			return
 		}
 	}
 }
 
-// moveDown moves selection down, skipping group headers.
+// moveDown moves selection down, skipping expanded group headers and the
+// plugins of collapsed groups.
 func (m *Model) moveDown() {
-	for i := m.selectedIdx + 1; i < len(m.plugins); i++ {
-		if !m.plugins[i].IsGroupHeader {
-			m.selectedIdx = i
+	rows := m.listRows()
+	for pos := slices.Index(rows, m.selectedIdx) + 1; pos < len(rows); pos++ {
+		if m.isSelectable(rows[pos]) {
+			m.selectedIdx = rows[pos]
 			m.ensureVisible()
 			return
 		}
@@ -314,8 +375,8 @@ func (m *Model) pageDown() {
 
 // moveToStart moves to the first selectable item.
 func (m *Model) moveToStart() {
-	for i := 0; i < len(m.plugins); i++ {
-		if !m.plugins[i].IsGroupHeader {
+	for _, i := range m.listRows() {
+		if m.isSelectable(i) {
 			m.selectedIdx = i
 			m.listOffset = 0
 			return
@@ -325,9 +386,10 @@ func (m *Model) moveToStart() {
 
 // moveToEnd moves to the last selectable item.
 func (m *Model) moveToEnd() {
-	for i := len(m.plugins) - 1; i >= 0; i-- {
-		if !m.plugins[i].IsGroupHeader {
-			m.selectedIdx = i
+	rows := m.listRows()
+	for pos := len(rows) - 1; pos >= 0; pos-- {
+		if m.isSelectable(rows[pos]) {
+			m.selectedIdx = rows[pos]
 			m.ensureVisible()
 			return
 		}
@@ -339,28 +401,31 @@ func (m *Model) getPageSize() int {
 	if m.height == 0 {
 		return 10
 	}
-	return m.height - 6 // Account for borders and help
+	return m.height - 6 - m.tabsHeight() // Account for borders, help, and view tabs
 }
 
-// ensureVisible adjusts listOffset to keep selectedIdx visible.
+// ensureVisible adjusts listOffset, which counts list rows, to keep the
+// selected row visible.
 func (m *Model) ensureVisible() {
 	pageSize := m.getPageSize()
+	rows := m.listRows()
+	pos := max(slices.Index(rows, m.selectedIdx), 0)
 
 	// If selection is above visible area, scroll up
-	if m.selectedIdx < m.listOffset {
-		m.listOffset = m.selectedIdx
+	if pos < m.listOffset {
+		m.listOffset = pos
 	}
 
 	// If selection is below visible area, scroll down
-	if m.selectedIdx >= m.listOffset+pageSize {
-		m.listOffset = m.selectedIdx - pageSize + 1
+	if pos >= m.listOffset+pageSize {
+		m.listOffset = pos - pageSize + 1
 	}
 
 	// Clamp offset
 	if m.listOffset < 0 {
 		m.listOffset = 0
 	}
-	maxOffset := max(len(m.plugins)-pageSize, 0)
+	maxOffset := max(len(rows)-pageSize, 0)
 	if m.listOffset > maxOffset {
 		m.listOffset = maxOffset
 	}
@@ -396,21 +461,21 @@ func (m *Model) selectForInstall(scope claude.Scope) {
 		// Single-scope or not installed: existing behavior
 		if plugin.IsInstalled() && !plugin.HasScope(scope) {
 			// Migrate from current scope to new scope
-			m.main.pendingOps[plugin.ID] = Operation{
+			m.queueOp(Operation{
 				PluginID:       plugin.ID,
 				Scopes:         []claude.Scope{scope},
 				OriginalScopes: maps.Clone(plugin.InstalledScopes),
 				Type:           OpMigrate,
-			}
+			})
 			continue
 		}
 
 		// Install
-		m.main.pendingOps[plugin.ID] = Operation{
+		m.queueOp(Operation{
 			PluginID: plugin.ID,
 			Scopes:   []claude.Scope{scope},
 			Type:     OpInstall,
-		}
+		})
 	}
 }
 
@@ -433,7 +498,7 @@ func (m *Model) toggleScope() {
 		return
 	}
 
-	m.main.pendingOps[plugin.ID] = *nextOp
+	m.queueOp(*nextOp)
 }
 
 // computeNextToggleOp determines the next operation in the toggle cycle.
@@ -510,12 +575,12 @@ func (m *Model) selectForUninstall() {
 		}
 
 		// Single-scope: uninstall from that scope
-		m.main.pendingOps[plugin.ID] = Operation{
+		m.queueOp(Operation{
 			PluginID:       plugin.ID,
 			Scopes:         []claude.Scope{plugin.SingleScope()},
 			OriginalScopes: maps.Clone(plugin.InstalledScopes),
 			Type:           OpUninstall,
-		}
+		})
 	}
 }
 
@@ -540,12 +605,37 @@ func (m *Model) selectForUpdate() {
 	}
 
 	// Create update operation (will reinstall at same scope)
-	m.main.pendingOps[plugin.ID] = Operation{
+	m.queueOp(Operation{
 		PluginID:       plugin.ID,
 		Scopes:         []claude.Scope{plugin.SingleScope()},
 		OriginalScopes: map[claude.Scope]bool{plugin.SingleScope(): true},
 		Type:           OpUpdate,
+	})
+}
+
+// needsCLI returns why an operation can't run without the claude CLI, or ""
+// if it can. Only the offline client has such limits.
+func (m *Model) needsCLI(op Operation) string {
+	offline, ok := m.client.(claude.OfflineClient)
+	if !ok {
+		return ""
+	}
+	switch op.Type {
+	case OpUpdate:
+		return "updating needs the claude CLI"
+	case OpInstall, OpMigrate, OpScopeChange:
+		if len(op.Scopes) > 0 && !offline.IsCached(op.PluginID) {
+			return "not in the plugin cache; installing needs the claude CLI"
+		}
 	}
+	return ""
+}
+
+// queueOp records op as its plugin's pending change, noting whether it needs
+// the claude CLI so views don't look up the plugin cache on every frame.
+func (m *Model) queueOp(op Operation) {
+	op.NeedsCLI = m.needsCLI(op)
+	m.main.pendingOps[op.PluginID] = op
 }
 
 // clearPending clears the pending change for the selected plugin.
@@ -577,6 +667,27 @@ func (m *Model) updateConfirmation(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// sortForExecution sorts operations into the order they run: uninstalls
+// first, then migrations, then scope changes, then updates, then installs,
+// then enable/disable. Operations of the same type are ordered by plugin ID.
+func sortForExecution(ops []Operation) {
+	typeOrder := map[OperationType]int{
+		OpUninstall:   0,
+		OpMigrate:     1,
+		OpScopeChange: 2,
+		OpUpdate:      3,
+		OpInstall:     4,
+		OpEnable:      5,
+		OpDisable:     6,
+	}
+	slices.SortFunc(ops, func(a, b Operation) int {
+		if c := cmp.Compare(typeOrder[a.Type], typeOrder[b.Type]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.PluginID, b.PluginID)
+	})
+}
+
 // startExecution begins executing pending operations.
 func (m *Model) startExecution() (tea.Model, tea.Cmd) {
 	// Build operation list from pendingOps
@@ -585,19 +696,7 @@ func (m *Model) startExecution() (tea.Model, tea.Cmd) {
 		m.progress.operations = append(m.progress.operations, op)
 	}
 
-	// Sort operations: uninstalls first, then migrations, then scope changes, then updates, then installs, then enable/disable
-	slices.SortFunc(m.progress.operations, func(a, b Operation) int {
-		typeOrder := map[OperationType]int{
-			OpUninstall:   0,
-			OpMigrate:     1,
-			OpScopeChange: 2,
-			OpUpdate:      3,
-			OpInstall:     4,
-			OpEnable:      5,
-			OpDisable:     6,
-		}
-		return cmp.Compare(typeOrder[a.Type], typeOrder[b.Type])
-	})
+	sortForExecution(m.progress.operations)
 
 	m.progress.currentIdx = 0
 	m.mode = ModeProgress
@@ -621,9 +720,18 @@ func execForScopes(scopes []claude.Scope, fn func(claude.Scope) error) error {
 }
 
 // executeOperation returns a command that executes a single operation.
+// Operations the offline client can't perform fail without running.
+func (m *Model) executeOperation(op Operation) tea.Cmd {
+	if reason := m.needsCLI(op); reason != "" {
+		return func() tea.Msg { return operationDoneMsg{op: op, err: errors.New(reason)} }
+	}
+	return m.runOperation(op)
+}
+
+// runOperation returns a command that runs a single operation through the client.
 // For multi-scope operations, it loops over all target scopes, stopping on first error.
 // Settings are read once at the start to determine install vs enable, uninstall vs disable.
-func (m *Model) executeOperation(op Operation) tea.Cmd {
+func (m *Model) runOperation(op Operation) tea.Cmd {
 	return func() tea.Msg {
 		// Read settings once to determine which command to use per scope
 		allScopes := claude.GetAllEnabledPlugins(m.workingDir)
@@ -709,53 +817,79 @@ func (m *Model) updateProgress(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.executeOperation(m.progress.operations[m.progress.currentIdx])
 		}
 
-		// All done - refresh and show summary
+		// All done - sync marketplaces, refresh, and show summary
 		m.mode = ModeSummary
 		m.main.pendingOps = make(map[string]Operation)
-		return m, tea.Batch(m.loadPlugins, m.syncMarketplacesCmd())
+		return m, m.syncMarketplacesCmd()
 	}
 	return m, nil
 }
 
 // syncMarketplacesCmd returns a tea.Cmd that reconciles extraKnownMarketplaces
-// in project/local settings files after all operations complete.
+// in project/local settings files after all operations complete, then reloads
+// the plugins. Entries added in the extras editor are kept. Files that couldn't
+// be reconciled (e.g., because another process kept editing them) are reported
+// in the loaded list's notice.
 func (m *Model) syncMarketplacesCmd() tea.Cmd {
-	// Collect affected paths before returning the command closure
-	affectedPaths := make(map[string]bool)
-	for _, op := range m.progress.operations {
-		for _, scope := range op.Scopes {
-			if p := claude.SettingsPathForScope(m.workingDir, scope); p != "" {
-				affectedPaths[p] = true
+	// Collect affected paths and their kept entries before returning the command closure
+	affectedPaths := make(map[string][]string)
+	for scope := range affectedScopes(m.progress.operations) {
+		if p := claude.SettingsPathForScope(m.workingDir, scope); p != "" {
+			affectedPaths[p] = slices.Clone(m.prefs.ExtraMarketplaces[p])
+		}
+	}
+
+	return func() tea.Msg {
+		failures := syncMarketplaces(affectedPaths, m.workingDir)
+		msg := m.loadPlugins()
+		if loaded, ok := msg.(pluginsLoadedMsg); ok && len(failures) > 0 {
+			if loaded.notice != "" {
+				failures = append(failures, loaded.notice)
 			}
+			loaded.notice = strings.Join(failures, " • ")
+			return loaded
+		}
+		return msg
+	}
+}
+
+// affectedScopes returns the scopes whose settings files ops touch.
+func affectedScopes(ops []Operation) map[claude.Scope]bool {
+	scopes := make(map[claude.Scope]bool)
+	for _, op := range ops {
+		for _, scope := range op.Scopes {
+			scopes[scope] = true
 		}
 		for _, scope := range op.UninstallScopes {
-			if p := claude.SettingsPathForScope(m.workingDir, scope); p != "" {
-				affectedPaths[p] = true
-			}
+			scopes[scope] = true
 		}
 		if op.Type == OpMigrate {
 			for scope := range op.OriginalScopes {
-				if p := claude.SettingsPathForScope(m.workingDir, scope); p != "" {
-					affectedPaths[p] = true
-				}
+				scopes[scope] = true
 			}
 		}
 	}
+	return scopes
+}
 
-	if len(affectedPaths) == 0 {
+// syncMarketplaces reconciles extraKnownMarketplaces in each settings file,
+// keeping the entries listed for it, and returns a notice for each file that
+// couldn't be updated.
+func syncMarketplaces(paths map[string][]string, workingDir string) []string {
+	if len(paths) == 0 {
 		return nil
 	}
-
-	return func() tea.Msg {
-		known, err := claude.ReadKnownMarketplaces()
-		if err != nil {
-			return nil // Non-fatal
-		}
-		for path := range affectedPaths {
-			_ = claude.SyncExtraMarketplaces(path, known)
+	known, err := claude.ReadKnownMarketplaces()
+	if err != nil {
+		return nil // Non-fatal
+	}
+	var failures []string
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		if err := claude.SyncExtraMarketplaces(path, known, paths[path]); err != nil {
+			failures = append(failures, "Couldn't update marketplaces in "+displayPath(path, workingDir)+": "+err.Error())
 		}
-		return nil
 	}
+	return failures
 }
 
 // updateError handles messages in error mode.
@@ -773,6 +907,7 @@ func (m *Model) updateError(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case pluginsLoadedMsg:
 		m.plugins = msg.plugins
+		m.watched = msg.stamps
 		// Re-select first non-header
 		for i, p := range m.plugins {
 			if !p.IsGroupHeader {
@@ -793,13 +928,15 @@ func (m *Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.selectFilterMatch()
 	case tea.KeyBackspace:
 		m.backspaceFilter()
-	case tea.KeyRunes:
+	case tea.KeyRunes, tea.KeySpace:
 		m.filter.text += string(msg.Runes)
 		m.applyFilter()
 	case tea.KeyUp:
 		m.navigateFilterUp()
 	case tea.KeyDown:
 		m.navigateFilterDown()
+	case tea.KeyCtrlS:
+		m.saveFilterAsView()
 	}
 
 	return m, nil
@@ -809,6 +946,7 @@ func (m *Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *Model) exitFilter() {
 	m.filter.active = false
 	m.filter.text = ""
+	m.filter.err = ""
 	m.filteredIdx = nil
 	m.listOffset = 0
 }
@@ -821,7 +959,10 @@ func (m *Model) selectFilterMatch() {
 		m.selectedIdx = m.filteredIdx[0]
 	}
 	m.filter.text = ""
+	m.filter.err = ""
 	m.filteredIdx = nil
+	m.revealSelection()
+	m.ensureVisible()
 }
 
 // backspaceFilter removes the last character from filter text.
@@ -881,48 +1022,63 @@ type pluginSearchData struct {
 }
 
 func (d pluginSearchData) String(i int) string {
-	p := d.plugins[d.indices[i]]
 	// Combine name, description, and ID for matching
-	return strings.ToLower(p.Name + " " + p.Description + " " + p.ID)
+	return searchText(&d.plugins[d.indices[i]])
 }
 
 func (d pluginSearchData) Len() int {
 	return len(d.indices)
 }
 
-// applyFilter updates filteredIdx based on filterText using fuzzy matching.
+// applyFilter updates filteredIdx from the filter query, within the active
+// view. A query that doesn't parse leaves the previous matches and sets the
+// filter error.
 func (m *Model) applyFilter() {
+	m.filter.err = ""
 	if m.filter.text == "" {
 		m.filteredIdx = nil
 		return
 	}
 
-	// Build search data (non-header plugins only)
+	q, err := parseQuery(m.filter.text)
+	if err != nil {
+		m.filter.err = err.Error()
+		return
+	}
+	m.filteredIdx = m.matchQuery(q, true)
+
+	m.listOffset = 0
+	if len(m.filteredIdx) > 0 {
+		m.selectedIdx = m.filteredIdx[0]
+	}
+}
+
+// matchQuery returns the indices of the plugins satisfying the qualifiers of
+// q, ranked by fuzzy matching when it has free text and in list order
+// otherwise. onlyView limits the matches to the active view.
+func (m *Model) matchQuery(q query, onlyView bool) []int {
+	// Build search data (non-header plugins the qualifiers allow)
 	data := pluginSearchData{plugins: m.plugins}
-	for i, p := range m.plugins {
-		if !p.IsGroupHeader {
+	for i := range m.plugins {
+		p := &m.plugins[i]
+		if !p.IsGroupHeader && q.matches(p) && (!onlyView || m.inView(p)) {
 			data.indices = append(data.indices, i)
 		}
 	}
 
-	if len(data.indices) == 0 {
-		m.filteredIdx = nil
-		return
+	if len(data.indices) == 0 || q.text == "" {
+		return data.indices
 	}
 
 	// Perform fuzzy search
-	matches := fuzzy.FindFrom(strings.ToLower(m.filter.text), data)
+	matches := fuzzy.FindFrom(strings.ToLower(q.text), data)
 
 	// Convert matches to original plugin indices (already sorted by score)
-	m.filteredIdx = make([]int, len(matches))
+	indices := make([]int, len(matches))
 	for i, match := range matches {
-		m.filteredIdx[i] = data.indices[match.Index]
-	}
-
-	m.listOffset = 0
-	if len(m.filteredIdx) > 0 {
-		m.selectedIdx = m.filteredIdx[0]
+		indices[i] = data.indices[match.Index]
 	}
+	return indices
 }
 
 // handleMouse processes mouse input.
@@ -957,7 +1113,7 @@ func (m *Model) handleMouseClick(msg tea.MouseMsg) {
 	}
 
 	// Calculate vertical offset: account for filter bar (1 line if active) + pane border (1 line)
-	verticalOffset := 1 // Default: 1 for top border
+	verticalOffset := 1 + m.tabsHeight() // Default: 1 for top border
 	if m.filter.active {
 		verticalOffset++ // Add 1 for filter input bar
 	}
@@ -967,7 +1123,11 @@ func (m *Model) handleMouseClick(msg tea.MouseMsg) {
 	plugins := m.getVisiblePlugins()
 	if row >= 0 && row < len(plugins)-m.listOffset {
 		actualIdx := m.getActualIndex(row)
-		if actualIdx >= 0 && !m.plugins[actualIdx].IsGroupHeader {
+		switch {
+		case actualIdx < 0:
+		case m.plugins[actualIdx].IsGroupHeader:
+			m.toggleGroup(actualIdx)
+		default:
 			m.selectedIdx = actualIdx
 		}
 	}
@@ -975,7 +1135,7 @@ func (m *Model) handleMouseClick(msg tea.MouseMsg) {
 
 // cycleSortMode cycles through the available sort modes and applies sorting.
 func (m *Model) cycleSortMode() {
-	// Cycle: NameAsc -> NameDesc -> Scope -> Marketplace -> NameAsc
+	// Cycle: NameAsc -> NameDesc -> Scope -> Marketplace -> Size -> NameAsc
 	switch m.main.sortMode {
 	case SortByNameAsc:
 		m.main.sortMode = SortByNameDesc
@@ -984,6 +1144,8 @@ func (m *Model) cycleSortMode() {
 	case SortByScope:
 		m.main.sortMode = SortByMarketplace
 	case SortByMarketplace:
+		m.main.sortMode = SortBySize
+	case SortBySize:
 		m.main.sortMode = SortByNameAsc
 	default:
 		m.main.sortMode = SortByNameAsc
@@ -997,6 +1159,7 @@ func (m *Model) sortPlugins() {
 	plugins := m.extractNonHeaderPlugins()
 	applySortMode(plugins, m.main.sortMode)
 	m.plugins = rebuildWithGroupHeaders(plugins, m.main.sortMode)
+	applyCatalogs(m.plugins, m.catalogs) // New group headers need their catalogue metadata
 	m.restoreSelection(selectedID)
 }
 
@@ -1034,6 +1197,8 @@ func applySortMode(plugins []PluginState, sortMode SortMode) {
 		sortByScope(plugins)
 	case SortByMarketplace:
 		sortByMarketplace(plugins)
+	case SortBySize:
+		sortBySize(plugins)
 	}
 }
 
@@ -1063,12 +1228,56 @@ func sortByMarketplace(plugins []PluginState) {
 	})
 }
 
-// restoreSelection restores the selection to the plugin with the given ID.
+// sortBySize sorts plugins by disk usage, largest first. Unmeasured plugins sort last by name.
+func sortBySize(plugins []PluginState) {
+	size := func(p PluginState) int64 {
+		if p.DiskUsage == nil {
+			return -1
+		}
+		return p.DiskUsage.Bytes
+	}
+	slices.SortFunc(plugins, func(a, b PluginState) int {
+		if c := cmp.Compare(size(b), size(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+}
+
+// groupByInstalled groups plugins under "Installed" and "Not Installed"
+// headers, keeping their order within each group.
+func groupByInstalled(plugins []PluginState) []PluginState {
+	var result, installed, notInstalled []PluginState
+	for _, p := range plugins {
+		if p.IsInstalled() {
+			installed = append(installed, p)
+		} else {
+			notInstalled = append(notInstalled, p)
+		}
+	}
+	if len(installed) > 0 {
+		result = append(result, PluginState{Name: "Installed", IsGroupHeader: true})
+		result = append(result, installed...)
+	}
+	if len(notInstalled) > 0 {
+		result = append(result, PluginState{Name: "Not Installed", IsGroupHeader: true})
+		result = append(result, notInstalled...)
+	}
+	return result
+}
+
+// restoreSelection restores the selection to the plugin with the given ID,
+// or to the header of its group if the group is collapsed. If the plugin is
+// gone or outside the active view, the first row is selected.
 func (m *Model) restoreSelection(selectedID string) {
 	if selectedID != "" {
-		for i, p := range m.plugins {
-			if p.ID == selectedID {
-				m.selectedIdx = i
+		if i := slices.IndexFunc(m.plugins, func(p PluginState) bool { return p.ID == selectedID }); i >= 0 {
+			m.selectedIdx = i
+			if h := m.groupHeaderOf(i); h >= 0 && m.main.collapsed[m.plugins[h].Name] {
+				m.selectedIdx = h
+			}
+			// The plugin may have left the active view
+			if slices.Contains(m.listRows(), m.selectedIdx) {
 				m.ensureVisible()
 				return
 			}
@@ -1077,15 +1286,17 @@ func (m *Model) restoreSelection(selectedID string) {
 	m.selectFirstNonHeader()
 }
 
-// selectFirstNonHeader selects the first non-header plugin.
+// selectFirstNonHeader selects the first plugin, or the header of the first
+// group if it is collapsed. Nothing is selected when the list has no rows.
 func (m *Model) selectFirstNonHeader() {
-	for i, p := range m.plugins {
-		if !p.IsGroupHeader {
+	m.listOffset = 0
+	for _, i := range m.listRows() {
+		if m.isSelectable(i) {
 			m.selectedIdx = i
-			break
+			return
 		}
 	}
-	m.listOffset = 0
+	m.selectedIdx = -1
 }
 
 // rebuildWithGroupHeaders rebuilds the plugin list with group headers based on sort mode.
@@ -1113,6 +1324,9 @@ func rebuildWithGroupHeaders(plugins []PluginState, sortMode SortMode) []PluginS
 			result = append(result, byGroup[group]...)
 		}
 
+	case SortBySize:
+		result = groupByInstalled(plugins)
+
 	case SortByScope:
 		// Group by scope
 		scopeOrder := []claude.Scope{claude.ScopeLocal, claude.ScopeProject, claude.ScopeUser, claude.ScopeNone}
@@ -1444,10 +1658,15 @@ func (m *Model) getConfigMaxScroll() int {
 	return maxScroll
 }
 
-// toggleBulkSelection toggles the selection state of the current plugin.
+// toggleBulkSelection toggles the selection state of the current plugin, or
+// of every plugin in the group when a collapsed group's header is selected.
 func (m *Model) toggleBulkSelection() {
 	plugin := m.getSelectedPlugin()
-	if plugin == nil || plugin.IsGroupHeader {
+	if plugin == nil {
+		return
+	}
+	if plugin.IsGroupHeader {
+		m.toggleGroupSelection()
 		return
 	}
 
@@ -1458,10 +1677,10 @@ func (m *Model) toggleBulkSelection() {
 	}
 }
 
-// selectAllPlugins selects all non-header plugins.
+// selectAllPlugins selects all non-header plugins in the active view.
 func (m *Model) selectAllPlugins() {
 	for _, p := range m.plugins {
-		if !p.IsGroupHeader {
+		if !p.IsGroupHeader && m.inView(&p) {
 			m.main.bulkSelected[p.ID] = true
 		}
 	}
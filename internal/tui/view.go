@@ -39,7 +39,7 @@ func (m *Model) renderMainView() string {
 		return "Loading..."
 	}
 
-	styles := m.styles.WithDimensions(m.width, m.height)
+	styles := m.styles.WithDimensions(m.width, m.height-m.tabsHeight())
 
 	leftContent := m.renderList(styles)
 	rightContent := m.renderDetails(styles)
@@ -51,13 +51,44 @@ func (m *Model) renderMainView() string {
 
 	help := m.renderHelp(styles)
 
+	var rows []string
+	if tabs := m.renderViewTabs(styles); tabs != "" {
+		rows = append(rows, tabs)
+	}
+
 	// Add filter input if active
 	if m.filter.active {
 		filter := m.renderFilterInput(styles)
-		return lipgloss.JoinVertical(lipgloss.Left, filter, main, help)
+		return lipgloss.JoinVertical(lipgloss.Left, append(rows, filter, main, help)...)
+	}
+
+	if status := m.renderStatusLine(styles); status != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, append(rows, main, status, help)...)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, append(rows, main, help)...)
+}
+
+// renderStatusLine renders the line between the panes and the help bar: the
+// result of the last action or a notice if there is one, otherwise the
+// plugins directory disk usage. It is cut to the terminal width so it never wraps.
+func (m *Model) renderStatusLine(styles Styles) string {
+	if m.main.status != "" {
+		return styles.Pending.MaxWidth(m.width).Render(m.main.status)
+	}
+	if m.main.notice != "" {
+		return styles.Pending.MaxWidth(m.width).Render(m.main.notice)
 	}
+	if m.cacheUsage != nil {
+		return styles.Help.MaxWidth(m.width).Render(formatPluginsUsage(*m.cacheUsage))
+	}
+	return ""
+}
 
-	return lipgloss.JoinVertical(lipgloss.Left, main, help)
+// formatPluginsUsage summarizes disk usage under ~/.claude/plugins.
+func formatPluginsUsage(u claude.PluginsUsage) string {
+	return fmt.Sprintf("Disk: %s total — marketplaces %s • installed %s",
+		formatBytes(u.Total().Bytes), formatBytes(u.Marketplaces.Bytes), formatBytes(u.Cache.Bytes))
 }
 
 // renderList renders the left pane plugin list.
@@ -67,30 +98,29 @@ func (m *Model) renderList(styles Styles) string {
 		if m.filter.active && m.filter.text != "" {
 			return "No matches for: " + m.filter.text
 		}
+		if view := m.activeView(); view != nil {
+			return "No plugins in view " + view.Name + " (" + view.Query + ")"
+		}
 		return "No plugins found."
 	}
 
 	var lines []string
 	visibleHeight := styles.LeftPane.GetHeight() - 2
+	rows := m.listRows()
 
 	// Calculate visible range
 	start := m.listOffset
 	end := min(start+visibleHeight, len(plugins))
 
+	// Headers carry counts and ages; cut them rather than wrap onto the next row
+	headerWidth := lipgloss.NewStyle().MaxWidth(styles.LeftPane.GetWidth() - 2)
+
 	for i := start; i < end; i++ {
 		plugin := plugins[i]
-		// When filtering, getActualIndex converts filtered index to original.
-		// When not filtering, i is already the actual index.
-		var isSelected bool
-		if m.filter.active && m.filter.text != "" {
-			// i is index into filtered list, need to get original index
-			if i < len(m.filteredIdx) {
-				isSelected = m.filteredIdx[i] == m.selectedIdx
-			}
-		} else {
-			isSelected = i == m.selectedIdx
+		line := m.renderListItem(plugin, rows[i] == m.selectedIdx, styles)
+		if plugin.IsGroupHeader {
+			line = headerWidth.Render(line)
 		}
-		line := m.renderListItem(plugin, isSelected, styles)
 		lines = append(lines, line)
 	}
 
@@ -100,7 +130,7 @@ func (m *Model) renderList(styles Styles) string {
 // renderListItem renders a single list item.
 func (m *Model) renderListItem(plugin PluginState, selected bool, styles Styles) string {
 	if plugin.IsGroupHeader {
-		return styles.GroupHeader.Render("── " + plugin.Name + " ──")
+		return m.renderGroupHeader(plugin, selected, styles, time.Now())
 	}
 
 	// Build the line
@@ -133,6 +163,11 @@ func (m *Model) renderListItem(plugin PluginState, selected bool, styles Styles)
 		parts = append(parts, scope)
 	}
 
+	// Conflict marker
+	if len(plugin.Conflicts) > 0 {
+		parts = append(parts, styles.Pending.Render("⚠"))
+	}
+
 	line := strings.Join(parts, " ")
 
 	if selected {
@@ -141,11 +176,61 @@ func (m *Model) renderListItem(plugin PluginState, selected bool, styles Styles)
 	return styles.Normal.Render(line)
 }
 
-// getScopeIndicator returns the scope indicator for a plugin.
+// renderGroupHeader renders a group header with whether it is collapsed and
+// its plugin counts. Marketplace headers also show how long ago the
+// marketplace was updated, highlighted once it is stale.
+func (m *Model) renderGroupHeader(plugin PluginState, selected bool, styles Styles, now time.Time) string {
+	marker := "▾"
+	if m.main.collapsed[plugin.Name] {
+		marker = "▸"
+	}
+	title := styles.GroupHeader
+	if selected {
+		title = styles.Selected
+	}
+	header := title.Render(marker+" "+plugin.Name) + " " + styles.Help.Render(m.groupStatsFor(plugin.Name).String())
+	mp, ok := m.known[plugin.Marketplace]
+	if plugin.Marketplace == "" || !ok || mp.LastUpdated == "" {
+		return header
+	}
+	style := styles.Help
+	if m.isStale(mp.LastUpdated, now) {
+		style = styles.Pending
+	}
+	return header + styles.Help.Render(" · ") + style.Render("updated "+formatAge(mp.LastUpdated, now))
+}
+
+// getScopeIndicator returns the scope indicator for a plugin, followed by a
+// managed policy marker when one applies.
 func (m *Model) getScopeIndicator(plugin PluginState, styles Styles) string {
+	indicator := m.getInstalledScopeIndicator(plugin, styles)
+	if plugin.Managed == nil {
+		return indicator
+	}
+
+	managed := styles.ScopeManaged.Render("[MANAGED]")
+	if !*plugin.Managed {
+		managed = styles.ScopeManaged.Render("[BLOCKED]")
+	}
+	if indicator == "" {
+		return managed
+	}
+	return indicator + " " + managed
+}
+
+// getInstalledScopeIndicator returns the indicator for pending changes or the
+// user-controlled scopes a plugin is installed at.
+func (m *Model) getInstalledScopeIndicator(plugin PluginState, styles Styles) string {
 	// Check for pending changes first
 	if op, ok := m.main.pendingOps[plugin.ID]; ok {
-		return renderPendingIndicator(op, plugin, styles)
+		indicator := renderPendingIndicator(op, plugin, styles)
+		if op.Obsolete != "" {
+			indicator += styles.Pending.Render(" (obsolete)")
+		}
+		if op.NeedsCLI != "" {
+			indicator += styles.Pending.Render(" (needs CLI)")
+		}
+		return indicator
 	}
 
 	if !plugin.IsInstalled() {
@@ -241,7 +326,7 @@ func (m *Model) renderDetails(styles Styles) string {
 
 	plugin := m.plugins[m.selectedIdx]
 	if plugin.IsGroupHeader {
-		return styles.DetailTitle.Render("Marketplace: " + plugin.Name)
+		return m.renderMarketplaceDetails(plugin, styles)
 	}
 
 	var lines []string
@@ -266,12 +351,41 @@ func (m *Model) renderDetails(styles Styles) string {
 	// Components (what comes with this plugin)
 	lines = m.appendComponents(lines, plugin, styles)
 
+	// Names shared with other enabled plugins
+	lines = appendConflicts(lines, plugin, styles)
+
 	// Show external plugin notice if applicable
 	lines = m.appendExternalNotice(lines, plugin, styles)
 
 	return strings.Join(lines, "\n")
 }
 
+// renderMarketplaceDetails renders the right pane for a marketplace group header:
+// its plugin counts, and the owner and description from its catalogue when
+// available.
+func (m *Model) renderMarketplaceDetails(header PluginState, styles Styles) string {
+	stats := m.groupStatsFor(header.Name)
+	lines := []string{
+		styles.DetailTitle.Render("Marketplace: " + header.Name),
+		"",
+		styles.DetailLabel.Render("Plugins: ") + styles.DetailValue.Render(fmt.Sprintf(
+			"%d installed, %d available, %d with updates, %d disabled",
+			stats.installed, stats.available, stats.updates, stats.disabled,
+		)),
+	}
+	if header.AuthorName != "" {
+		owner := header.AuthorName
+		if header.AuthorEmail != "" {
+			owner += " <" + header.AuthorEmail + ">"
+		}
+		lines = append(lines, "", styles.DetailLabel.Render("Owner: ")+styles.DetailValue.Render(owner))
+	}
+	if header.Description != "" {
+		lines = append(lines, "", styles.DetailDescription.Render(header.Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // renderPluginInfo renders the basic plugin information fields.
 func (m *Model) renderPluginInfo(plugin PluginState, styles Styles) []string {
 	var lines []string
@@ -304,6 +418,8 @@ func (m *Model) renderPluginInfo(plugin PluginState, styles Styles) []string {
 			styles.DetailValue.Render(authorStr))
 	}
 
+	lines = appendProjectMetadata(lines, plugin, styles)
+
 	// Install count (only for plugins with available info)
 	if plugin.InstallCount > 0 {
 		lines = append(lines, styles.DetailLabel.Render("Installs: ")+
@@ -316,6 +432,22 @@ func (m *Model) renderPluginInfo(plugin PluginState, styles Styles) []string {
 			styles.DetailValue.Render(formatTimestamp(plugin.LastUpdated)))
 	}
 
+	// Managed policy
+	if plugin.Managed != nil {
+		policy := "Enabled by managed settings (read-only)"
+		if !*plugin.Managed {
+			policy = "Blocked by managed settings (read-only)"
+		}
+		lines = append(lines, styles.DetailLabel.Render("Policy: ")+
+			styles.DetailValue.Render(policy))
+	}
+
+	// Disk usage (once measured)
+	if plugin.DiskUsage != nil {
+		lines = append(lines, styles.DetailLabel.Render("Disk: ")+
+			styles.DetailValue.Render(formatDirUsage(*plugin.DiskUsage)))
+	}
+
 	// Status
 	status := m.getStatusText(plugin)
 	lines = append(lines, styles.DetailLabel.Render("Status: ")+
@@ -324,6 +456,38 @@ func (m *Model) renderPluginInfo(plugin PluginState, styles Styles) []string {
 	return lines
 }
 
+// appendProjectMetadata appends plugin.json and catalogue metadata fields that are set.
+func appendProjectMetadata(lines []string, plugin PluginState, styles Styles) []string {
+	// Project metadata from plugin.json
+	if plugin.Homepage != "" {
+		lines = append(lines, styles.DetailLabel.Render("Homepage: ")+
+			styles.DetailValue.Render(plugin.Homepage))
+	}
+	if plugin.Repository != "" {
+		lines = append(lines, styles.DetailLabel.Render("Repository: ")+
+			styles.DetailValue.Render(plugin.Repository))
+	}
+	if plugin.License != "" {
+		lines = append(lines, styles.DetailLabel.Render("License: ")+
+			styles.DetailValue.Render(plugin.License))
+	}
+	if len(plugin.Keywords) > 0 {
+		lines = append(lines, styles.DetailLabel.Render("Keywords: ")+
+			styles.DetailValue.Render(strings.Join(plugin.Keywords, ", ")))
+	}
+
+	// Catalogue metadata from marketplace.json
+	if plugin.Category != "" {
+		lines = append(lines, styles.DetailLabel.Render("Category: ")+
+			styles.DetailValue.Render(plugin.Category))
+	}
+	if len(plugin.Tags) > 0 {
+		lines = append(lines, styles.DetailLabel.Render("Tags: ")+
+			styles.DetailValue.Render(strings.Join(plugin.Tags, ", ")))
+	}
+	return lines
+}
+
 // getStatusText returns the status text for a plugin.
 func (m *Model) getStatusText(plugin PluginState) string {
 	if !plugin.IsInstalled() {
@@ -368,7 +532,14 @@ func (m *Model) appendPendingChange(lines []string, plugin PluginState, styles S
 		pendingStr = op.Type.meta().Pending
 	}
 
-	return append(lines, styles.Pending.Render("Pending: "+pendingStr))
+	lines = append(lines, styles.Pending.Render("Pending: "+pendingStr))
+	if op.Obsolete != "" {
+		lines = append(lines, styles.Pending.Render("Obsolete: "+op.Obsolete+" (Esc to discard)"))
+	}
+	if op.NeedsCLI != "" {
+		lines = append(lines, styles.Pending.Render("Offline: "+op.NeedsCLI+"; this change will fail"))
+	}
+	return lines
 }
 
 // appendComponents appends component information if the plugin has any.
@@ -390,15 +561,36 @@ func (m *Model) appendComponents(lines []string, plugin PluginState, styles Styl
 	lines = append(lines, "")
 	lines = append(lines, styles.DetailLabel.Render("Includes:"))
 
-	lines = appendComponentCategory(lines, "Skills", plugin.Components.Skills, styles)
-	lines = appendComponentCategory(lines, "Agents", plugin.Components.Agents, styles)
-	lines = appendComponentCategory(lines, "Commands", plugin.Components.Commands, styles)
-	lines = appendComponentCategory(lines, "Hooks", plugin.Components.Hooks, styles)
-	lines = appendComponentCategory(lines, "MCPs", plugin.Components.MCPs, styles)
+	// Hooks run code on the user's machine, so list them first
+	lines = appendHooks(lines, plugin.Components.Hooks, styles)
+	lines = appendComponentEntries(lines, "Skills", plugin.Components.Skills, styles)
+	lines = appendComponentEntries(lines, "Agents", plugin.Components.Agents, styles)
+	lines = appendComponentEntries(lines, "Commands", plugin.Components.Commands, styles)
+	lines = appendMCPServers(lines, plugin.Components.MCPs, styles)
 
 	return lines
 }
 
+// appendConflicts explains the component names this plugin shares with other enabled plugins.
+func appendConflicts(lines []string, plugin PluginState, styles Styles) []string {
+	if len(plugin.Conflicts) == 0 {
+		return lines
+	}
+	lines = append(lines, "")
+	lines = append(lines, styles.Pending.Render("Conflicts:"))
+	for _, c := range plugin.Conflicts {
+		lines = append(lines, styles.ComponentItem.Render("• "+formatConflict(c, plugin.ID)))
+	}
+	lines = append(lines, styles.Help.Render("Unprefixed names are ambiguous; use the plugin-prefixed name or disable one plugin."))
+	return lines
+}
+
+// formatConflict describes a conflict from one plugin's point of view,
+// e.g. `command "review" is also provided by other@mp`.
+func formatConflict(c claude.Conflict, pluginID string) string {
+	return fmt.Sprintf("%s %q is also provided by %s", c.Kind, c.Name, strings.Join(c.Others(pluginID), ", "))
+}
+
 // formatUninstallPending returns the pending string for an uninstall operation.
 func (m *Model) formatUninstallPending(op Operation, plugin PluginState) string {
 	if len(op.Scopes) > 0 && plugin.IsInstalled() && !plugin.IsSingleScope() {
@@ -422,26 +614,118 @@ func appendComponentCategory(lines []string, category string, items []string, st
 	return lines
 }
 
-// appendExternalNotice appends the external plugin notice if applicable.
+// appendComponentEntries appends a category of skills, agents, or commands with
+// their frontmatter descriptions, tools, and model.
+func appendComponentEntries(lines []string, category string, items []claude.Component, styles Styles) []string {
+	if len(items) == 0 {
+		return lines
+	}
+	lines = append(lines, styles.ComponentCategory.Render(category))
+	for _, item := range items {
+		lines = append(lines, styles.ComponentItem.Render("• "+formatComponent(item)))
+		if detail := formatComponentDetail(item); detail != "" {
+			lines = append(lines, styles.ComponentItem.Render("  "+styles.Help.Render(detail)))
+		}
+	}
+	return lines
+}
+
+// appendHooks appends the plugin's hooks, one line per handler.
+func appendHooks(lines []string, hooks []claude.Hook, styles Styles) []string {
+	items := make([]string, len(hooks))
+	for i, h := range hooks {
+		items[i] = h.String()
+	}
+	return appendComponentCategory(lines, "Hooks", items, styles)
+}
+
+// appendMCPServers appends the plugin's MCP servers with transport, endpoint,
+// and the names of required environment variables (never their values).
+func appendMCPServers(lines []string, servers []claude.MCPServer, styles Styles) []string {
+	if len(servers) == 0 {
+		return lines
+	}
+	lines = append(lines, styles.ComponentCategory.Render("MCPs"))
+	for _, s := range servers {
+		text := s.Name
+		if s.Type != "" {
+			text += " (" + s.Type + ")"
+		}
+		if endpoint := s.Endpoint(); endpoint != "" {
+			text += " " + endpoint
+		}
+		lines = append(lines, styles.ComponentItem.Render("• "+text))
+		if len(s.EnvVars) > 0 {
+			lines = append(lines, styles.ComponentItem.Render("  "+styles.Help.Render("env: "+strings.Join(s.EnvVars, ", "))))
+		}
+	}
+	return lines
+}
+
+// formatComponent formats a component as "name [hint] — description".
+func formatComponent(c claude.Component) string {
+	text := c.Name
+	if c.ArgumentHint != "" {
+		text += " " + c.ArgumentHint
+	}
+	if c.Description != "" {
+		text += " — " + c.Description
+	}
+	return text
+}
+
+// formatComponentDetail formats a component's tools and model, or "" if neither is set.
+func formatComponentDetail(c claude.Component) string {
+	var parts []string
+	if len(c.AllowedTools) > 0 {
+		parts = append(parts, "tools: "+strings.Join(c.AllowedTools, ", "))
+	}
+	if c.Model != "" {
+		parts = append(parts, "model: "+c.Model)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// appendExternalNotice appends the typed source of a plugin hosted outside its
+// marketplace clone, noting when no local copy was found to read details from.
 func (m *Model) appendExternalNotice(lines []string, plugin PluginState, styles Styles) []string {
-	if !plugin.IsExternal || plugin.IsInstalled() {
+	if !plugin.IsExternal || plugin.Source == nil {
 		return lines
 	}
 	lines = append(lines, "")
 	lines = append(lines, styles.DetailLabel.Render("Source:"))
-	lines = append(lines, styles.DetailDescription.Render("External plugin (hosted on GitHub)"))
-	if plugin.ExternalURL != "" {
-		lines = append(lines, styles.Help.Render(plugin.ExternalURL))
+	lines = append(lines, styles.DetailDescription.Render(sourceTypeLabel(plugin.Source)+": "+plugin.Source.String()))
+	if plugin.Components == nil && !plugin.IsInstalled() {
+		lines = append(lines, "")
+		lines = append(lines, styles.Help.Render("No local copy found; component details available after installation."))
 	}
-	lines = append(lines, "")
-	lines = append(lines, styles.Help.Render("Component details available after installation."))
 	return lines
 }
 
+// sourceTypeLabel returns a display label for a plugin source type.
+func sourceTypeLabel(src claude.MarketplaceSource) string {
+	switch src.SourceType() {
+	case "github":
+		return "GitHub"
+	case "git":
+		return "Git"
+	case "url":
+		return "URL"
+	case "npm":
+		return "npm"
+	case "directory":
+		return "Directory"
+	case "file":
+		return "File"
+	default:
+		return src.SourceType()
+	}
+}
+
 // renderHelp renders the help bar at the bottom.
 func (m *Model) renderHelp(styles Styles) string {
 	if m.filter.active {
-		return styles.Help.Render("Type to filter • Enter: select • Esc: cancel")
+		return styles.Help.Render("Type to filter • scope: mkt: has: status: is: author: • -x: exclude • Enter: select • Ctrl+S: save as view • Esc: cancel")
 	}
 
 	// Show mouse state indicator
@@ -459,7 +743,7 @@ func (m *Model) renderHelp(styles Styles) string {
 		selectionInfo = fmt.Sprintf(" • %d selected", len(m.main.bulkSelected))
 	}
 
-	baseHelp := "↑↓: navigate • Space: select • a/A: all/none • l/p/u/U: install/uninstall/update • Tab: toggle • " + sortInfo + " • c: config"
+	baseHelp := "↑↓: navigate • Space: select • a/A: all/none • z/Z: fold group/all • v: select group • F: views • l/p/u/U: install/uninstall/update • Tab: toggle • " + sortInfo + " • c: config • X: extras • M: marketplaces • R: refresh marketplace"
 	if len(m.main.pendingOps) > 0 {
 		return styles.Help.Render(baseHelp + " • Enter: apply • Esc: clear • /: filter • ?: readme • C: changelog • " + mouseIndicator + selectionInfo + " • q: quit")
 	}
@@ -533,7 +817,11 @@ func (m *Model) renderConfirmation(styles Styles) string {
 	})
 
 	for _, op := range operations {
-		lines = append(lines, "  "+formatOperationLine(op, styles))
+		line := formatOperationLine(op, styles)
+		if op.NeedsCLI != "" {
+			line += styles.Help.Render(" (" + op.NeedsCLI + ")")
+		}
+		lines = append(lines, "  "+line)
 	}
 
 	lines = append(lines, "")
@@ -542,6 +830,24 @@ func (m *Model) renderConfirmation(styles Styles) string {
 	summary := buildOperationSummary(operations)
 	lines = append(lines, styles.DetailLabel.Render(summary))
 	lines = append(lines, "")
+
+	// Settings file diff, fitted to the space left by the modal's other lines,
+	// border, and padding. Only the offline client writes the files as shown;
+	// the claude CLI rewrites them in its own formatting.
+	title := "Settings changes:"
+	if _, ok := m.client.(claude.OfflineClient); !ok {
+		title = "Intended settings changes (claude may reformat the files):"
+	}
+	lines = append(lines, styles.DetailLabel.Render(title))
+	limit, width := 0, 0
+	if m.height > 0 {
+		limit = max(m.height-len(lines)-8, 3)
+	}
+	if m.width > 0 {
+		width = max(m.width-10, 20)
+	}
+	lines = append(lines, renderSettingsDiffs(m.main.settingsDiffs, styles, limit, width)...)
+	lines = append(lines, "")
 	lines = append(lines, "Press Enter to confirm, Esc to cancel")
 
 	return lipgloss.Place(
@@ -565,7 +871,11 @@ func formatOperationLine(op Operation, styles Styles) string {
 		style = styles.Pending
 	}
 
-	return style.Render(verb+scopeDetail+": ") + op.PluginID
+	line := style.Render(verb+scopeDetail+": ") + op.PluginID
+	if op.Obsolete != "" {
+		line += styles.Help.Render(" (obsolete: " + op.Obsolete + ")")
+	}
+	return line
 }
 
 // buildOperationSummary builds a summary string counting operations by type.
@@ -689,40 +999,39 @@ func (m *Model) renderErrorSummary(styles Styles) string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
 }
 
-// renderFilterInput renders the filter input bar.
+// renderFilterInput renders the filter input bar, followed by the query's
+// parse error if it has one.
 func (m *Model) renderFilterInput(styles Styles) string {
 	if !m.filter.active {
 		return ""
 	}
 
-	input := "/" + m.filter.text + "█"
-	return styles.Header.Render(input)
+	input := styles.Header.Render("/" + m.filter.text + "█")
+	if m.filter.err != "" {
+		input += " " + styles.Pending.Render(m.filter.err)
+	}
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(input)
 }
 
-// getVisiblePlugins returns plugins to display (filtered or all).
+// getVisiblePlugins returns the plugins the list shows (see listRows).
 func (m *Model) getVisiblePlugins() []PluginState {
-	if !m.filter.active || m.filter.text == "" {
-		return m.plugins
-	}
-
-	if len(m.filteredIdx) == 0 {
+	rows := m.listRows()
+	if len(rows) == 0 {
 		return nil
 	}
 
-	result := make([]PluginState, len(m.filteredIdx))
-	for i, idx := range m.filteredIdx {
+	result := make([]PluginState, len(rows))
+	for i, idx := range rows {
 		result[i] = m.plugins[idx]
 	}
 	return result
 }
 
-// getActualIndex converts a filtered index to the actual plugin index.
-func (m *Model) getActualIndex(filteredIndex int) int {
-	if !m.filter.active || m.filter.text == "" {
-		return filteredIndex + m.listOffset
-	}
-	if filteredIndex+m.listOffset < len(m.filteredIdx) {
-		return m.filteredIdx[filteredIndex+m.listOffset]
+// getActualIndex converts a row on screen to the actual plugin index.
+func (m *Model) getActualIndex(row int) int {
+	rows := m.listRows()
+	if row+m.listOffset < len(rows) {
+		return rows[row+m.listOffset]
 	}
 	return -1
 }
@@ -738,6 +1047,28 @@ func formatInstallCount(count int) string {
 	return fmt.Sprintf("%d", count)
 }
 
+// formatBytes formats a byte count for display using binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatDirUsage formats a directory's size and file count for display.
+func formatDirUsage(u claude.DirUsage) string {
+	if u.Files == 1 {
+		return formatBytes(u.Bytes) + " (1 file)"
+	}
+	return fmt.Sprintf("%s (%d files)", formatBytes(u.Bytes), u.Files)
+}
+
 // formatTimestamp formats an ISO timestamp for display.
 func formatTimestamp(timestamp string) string {
 	// Try to parse ISO 8601 format
@@ -749,6 +1080,35 @@ func formatTimestamp(timestamp string) string {
 	return t.Format("Jan 2, 2006")
 }
 
+// formatAge formats an ISO timestamp as its age relative to now, e.g. "3d ago".
+func formatAge(timestamp string, now time.Time) string {
+	if timestamp == "" {
+		return "never"
+	}
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	age := now.Sub(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
+
+// isStale reports whether a marketplace last updated at the ISO timestamp
+// lastUpdated is older than the stale threshold at now.
+func (m *Model) isStale(lastUpdated string, now time.Time) bool {
+	updated, err := time.Parse(time.RFC3339, lastUpdated)
+	return err == nil && m.staleAfter > 0 && now.Sub(updated) > m.staleAfter
+}
+
 // renderQuitConfirmation renders the quit confirmation modal.
 func (m *Model) renderQuitConfirmation(styles Styles) string {
 	var lines []string
@@ -770,7 +1130,8 @@ func (m *Model) renderQuitConfirmation(styles Styles) string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
 }
 
-// scopeDialogLabels shows the scope name and settings file path.
+// scopeDialogLabels shows the scope name and settings file path. The user
+// path is the default; the dialog shows the resolved Claude config directory.
 var scopeDialogLabels = [3]struct {
 	name string
 	path string
@@ -800,6 +1161,9 @@ func (m *Model) renderScopeDialog(styles Styles) string {
 		}
 
 		label := scopeDialogLabels[i]
+		if p := claude.UserSettingsPath(); i == 0 && p != "" {
+			label.path = displayPath(p, m.workingDir)
+		}
 		line := cursor + checkbox + " " + label.name
 		// Pad to align paths
 		for len(line) < 25 {
@@ -0,0 +1,326 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/open-cli-collective/cpm/internal/config"
+)
+
+// ViewsState holds state for the saved views picker.
+type ViewsState struct {
+	form    *viewForm // New view being entered; nil when closed
+	message string    // Result of the last action
+	cursor  int       // 0 is all plugins, then the saved views in order
+}
+
+// viewForm holds the inputs for a new saved view.
+type viewForm struct {
+	name    string
+	query   string
+	message string // Validation error
+	field   int    // 0 is the name, 1 the query
+}
+
+// activeView returns the saved view the list shows, or nil for all plugins.
+func (m *Model) activeView() *config.View {
+	i := m.activeViewIndex()
+	if i < 0 {
+		return nil
+	}
+	return &m.prefs.Views[i]
+}
+
+// activeViewIndex returns the index of the active view in the saved views,
+// or -1 when all plugins are listed.
+func (m *Model) activeViewIndex() int {
+	return slices.IndexFunc(m.prefs.Views, func(v config.View) bool { return v.Name == m.prefs.ActiveView })
+}
+
+// computeView records which plugins the active view's query matches. It is
+// rerun whenever the plugins change, so the view stays applied across
+// refreshes. A query that no longer parses lists all plugins.
+func (m *Model) computeView() error {
+	m.main.viewIDs = nil
+	view := m.activeView()
+	if view == nil {
+		return nil
+	}
+	q, err := parseQuery(view.Query)
+	if err != nil {
+		return fmt.Errorf("view %s: %w", view.Name, err)
+	}
+	m.main.viewIDs = make(map[string]bool)
+	for _, i := range m.matchQuery(q, false) {
+		m.main.viewIDs[m.plugins[i].ID] = true
+	}
+	return nil
+}
+
+// inView reports whether the active view includes p.
+func (m *Model) inView(p *PluginState) bool {
+	return m.main.viewIDs == nil || m.main.viewIDs[p.ID]
+}
+
+// setActiveView switches the list to the named saved view, or to all plugins
+// for "", and saves the choice.
+func (m *Model) setActiveView(name string) error {
+	m.prefs.ActiveView = name
+	err := m.computeView()
+	if err != nil {
+		m.prefs.ActiveView = ""
+	}
+	m.selectFirstNonHeader()
+	m.savePrefs()
+	return err
+}
+
+// activateViewNumber switches to the nth saved view from the tab bar, where 0
+// is all plugins. Choosing the active view again shows all plugins.
+func (m *Model) activateViewNumber(n int) {
+	if n > len(m.prefs.Views) {
+		return
+	}
+	name := ""
+	if n > 0 && m.prefs.Views[n-1].Name != m.prefs.ActiveView {
+		name = m.prefs.Views[n-1].Name
+	}
+	if name == m.prefs.ActiveView {
+		return
+	}
+	if err := m.setActiveView(name); err != nil {
+		m.main.status = err.Error()
+	}
+}
+
+// openViews opens the saved views picker with the active view highlighted.
+func (m *Model) openViews() {
+	m.views = ViewsState{cursor: m.activeViewIndex() + 1} // Row 0 is all plugins
+	m.mode = ModeViews
+}
+
+// saveFilterAsView opens the picker's new view form with the filter query.
+func (m *Model) saveFilterAsView() {
+	query := m.filter.text
+	m.exitFilter()
+	m.openViews()
+	m.views.form = &viewForm{query: query}
+}
+
+// updateViews handles input in the saved views picker.
+func (m *Model) updateViews(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.views.form != nil {
+		m.handleViewFormKey(keyMsg)
+		return m, nil
+	}
+
+	state := &m.views
+	switch {
+	case matchesKey(keyMsg, m.keys.Escape), matchesKey(keyMsg, m.keys.Quit), matchesKey(keyMsg, m.keys.Views):
+		m.mode = ModeMain
+	case matchesKey(keyMsg, m.keys.Up):
+		state.cursor = max(state.cursor-1, 0)
+	case matchesKey(keyMsg, m.keys.Down):
+		state.cursor = min(state.cursor+1, len(m.prefs.Views))
+	case matchesKey(keyMsg, m.keys.Enter):
+		name := ""
+		if state.cursor > 0 {
+			name = m.prefs.Views[state.cursor-1].Name
+		}
+		if err := m.setActiveView(name); err != nil {
+			state.message = err.Error()
+			return m, nil
+		}
+		m.mode = ModeMain
+	case matchesKey(keyMsg, m.keys.Add):
+		state.form = &viewForm{}
+		if view := m.activeView(); view != nil {
+			state.form.query = view.Query
+		}
+	case matchesKey(keyMsg, m.keys.Delete):
+		m.deleteView(state.cursor - 1)
+	}
+	return m, nil
+}
+
+// deleteView removes the ith saved view, showing all plugins if it was active.
+func (m *Model) deleteView(i int) {
+	if i < 0 || i >= len(m.prefs.Views) {
+		return
+	}
+	name := m.prefs.Views[i].Name
+	m.prefs.Views = slices.Delete(m.prefs.Views, i, i+1)
+	if name == m.prefs.ActiveView {
+		_ = m.setActiveView("") // Listing all plugins can't fail
+	} else {
+		m.savePrefs()
+	}
+	m.views.cursor = min(m.views.cursor, len(m.prefs.Views))
+	m.views.message = "Deleted view " + name
+}
+
+// handleViewFormKey handles keys in the new view form.
+func (m *Model) handleViewFormKey(msg tea.KeyMsg) {
+	form := m.views.form
+	form.message = ""
+	input := &form.name
+	if form.field == 1 {
+		input = &form.query
+	}
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.views.form = nil
+	case tea.KeyTab, tea.KeyShiftTab, tea.KeyUp, tea.KeyDown:
+		form.field = 1 - form.field
+	case tea.KeyEnter:
+		m.submitViewForm()
+	case tea.KeyBackspace:
+		if runes := []rune(*input); len(runes) > 0 {
+			*input = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		*input += string(msg.Runes)
+	}
+}
+
+// submitViewForm validates the form, saves the view, and shows it.
+func (m *Model) submitViewForm() {
+	form := m.views.form
+	name, query := strings.TrimSpace(form.name), strings.TrimSpace(form.query)
+	switch {
+	case name == "":
+		form.message = "Name is required"
+		return
+	case slices.ContainsFunc(m.prefs.Views, func(v config.View) bool { return v.Name == name }):
+		form.message = fmt.Sprintf("There is already a view named %s", name)
+		return
+	case query == "":
+		form.message = "Query is required"
+		return
+	}
+	if _, err := parseQuery(query); err != nil {
+		form.message = err.Error()
+		return
+	}
+
+	m.prefs.Views = append(m.prefs.Views, config.View{Name: name, Query: query})
+	m.views.form = nil
+	_ = m.setActiveView(name) // The query parsed above
+	m.mode = ModeMain
+}
+
+// renderViews renders the saved views picker.
+func (m *Model) renderViews(styles Styles) string {
+	header := styles.Header.Render(" Views ")
+
+	var lines []string
+	var help string
+	if form := m.views.form; form != nil {
+		lines, help = m.renderViewForm(styles, form)
+	} else {
+		lines = m.renderViewList(styles)
+		help = "↑↓: navigate • Enter: show • a: add • d: delete • Esc: back"
+	}
+	if m.views.message != "" {
+		lines = append(lines, "", styles.Pending.Render(m.views.message))
+	}
+
+	content := lipgloss.NewStyle().
+		Width(max(m.width-4, 0)).
+		Height(max(m.height-4, 1)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+	return lipgloss.JoinVertical(lipgloss.Left, header, content, styles.Help.Render(help))
+}
+
+// renderViewList renders "All plugins" and the saved views with their queries.
+func (m *Model) renderViewList(styles Styles) []string {
+	row := func(i int, name, query string, active bool) string {
+		line := fmt.Sprintf("%-20s %s", name, styles.Help.Render(query))
+		if active {
+			line += styles.Pending.Render(" (active)")
+		}
+		if i == m.views.cursor {
+			return styles.Selected.Render("> ") + line
+		}
+		return "  " + line
+	}
+
+	lines := []string{row(0, "All plugins", "", m.activeView() == nil)}
+	for i, v := range m.prefs.Views {
+		lines = append(lines, row(i+1, v.Name, v.Query, v.Name == m.prefs.ActiveView))
+	}
+	if len(m.prefs.Views) == 0 {
+		lines = append(lines, "", styles.Help.Render("No saved views. Press a to add one, or Ctrl+S while filtering to save the filter."))
+	}
+	return lines
+}
+
+// renderViewForm renders the new view form and its help line.
+func (m *Model) renderViewForm(styles Styles, form *viewForm) ([]string, string) {
+	field := func(i int, label, value, hint string) string {
+		prefix := "  "
+		if i == form.field {
+			prefix = styles.Selected.Render("> ")
+			value += "█"
+		}
+		text := styles.DetailValue.Render(value)
+		if value == "" || value == "█" {
+			text += styles.Help.Render(hint)
+		}
+		return prefix + styles.DetailLabel.Render(fmt.Sprintf("%-7s", label+":")) + " " + text
+	}
+
+	lines := []string{
+		styles.DetailLabel.Render("New view"),
+		"",
+		field(0, "Name", form.name, "e.g. Updates"),
+		field(1, "Query", form.query, "e.g. is:outdated, scope:project has:mcp"),
+	}
+	message := form.message
+	if _, err := parseQuery(form.query); err != nil && message == "" {
+		message = err.Error()
+	}
+	if message != "" {
+		lines = append(lines, "", styles.Pending.Render(message))
+	}
+	return lines, "Type to edit • Tab: field • Enter: save • Esc: cancel"
+}
+
+// renderViewTabs renders the tab bar of saved views above the panes, or ""
+// when there are none.
+func (m *Model) renderViewTabs(styles Styles) string {
+	if len(m.prefs.Views) == 0 {
+		return ""
+	}
+	tab := func(n int, name string, active bool) string {
+		label := fmt.Sprintf(" %d %s ", n, name)
+		if active {
+			return styles.Selected.Render(label)
+		}
+		return styles.Normal.Render(label)
+	}
+
+	tabs := []string{tab(0, "All", m.activeView() == nil)}
+	for i, v := range m.prefs.Views {
+		tabs = append(tabs, tab(i+1, v.Name, v.Name == m.prefs.ActiveView))
+	}
+	line := strings.Join(tabs, styles.Help.Render("│")) + styles.Help.Render("  F: views")
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(line)
+}
+
+// tabsHeight returns the number of lines the view tabs take above the panes.
+func (m *Model) tabsHeight() int {
+	if len(m.prefs.Views) == 0 {
+		return 0
+	}
+	return 1
+}
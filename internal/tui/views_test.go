@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+	"github.com/open-cli-collective/cpm/internal/config"
+)
+
+func TestAddViewPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cpm", "config.json")
+	m := marketplacesTestModel(&mockClient{})
+	m.UseConfig(path)
+
+	sendKeys(m, "F", "a", "Mine", "tab", "status:installed", "enter")
+	if m.mode != ModeMain || m.prefs.ActiveView != "Mine" {
+		t.Fatalf("mode = %v, active view = %q, want main showing Mine", m.mode, m.prefs.ActiveView)
+	}
+	if got := len(m.getVisiblePlugins()); got != 2 || m.selectedIdx != 1 {
+		t.Errorf("visible rows = %d, selectedIdx = %d, want alpha and a1 selected", got, m.selectedIdx)
+	}
+
+	// A restart loads the view and applies it once the plugins arrive
+	next := NewModel(&mockClient{}, "/test/project")
+	next.UseConfig(path)
+	next.Update(pluginsLoadedMsg{plugins: marketplacesTestModel(&mockClient{}).plugins})
+	if next.prefs.ActiveView != "Mine" || len(next.getVisiblePlugins()) != 2 {
+		t.Errorf("after reload active view = %q, visible rows = %d", next.prefs.ActiveView, len(next.getVisiblePlugins()))
+	}
+	if !strings.Contains(next.renderViewTabs(next.styles), "1 Mine") {
+		t.Errorf("tabs = %q, want the Mine tab", next.renderViewTabs(next.styles))
+	}
+}
+
+func TestQuickViewKeys(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+	m.prefs.Views = []config.View{{Name: "Mine", Query: "status:installed"}, {Name: "Beta", Query: "mkt:beta"}}
+
+	sendKeys(m, "2")
+	if m.prefs.ActiveView != "Beta" || m.selectedIdx != 4 || len(m.getVisiblePlugins()) != 2 {
+		t.Errorf("after 2 active view = %q, selectedIdx = %d, want Beta with b1 selected", m.prefs.ActiveView, m.selectedIdx)
+	}
+	sendKeys(m, "2")
+	if m.prefs.ActiveView != "" || len(m.getVisiblePlugins()) != 5 {
+		t.Errorf("after 2 again active view = %q, want all plugins", m.prefs.ActiveView)
+	}
+	sendKeys(m, "9")
+	if m.prefs.ActiveView != "" {
+		t.Errorf("9 with two views switched to %q", m.prefs.ActiveView)
+	}
+
+	// A refresh reruns the view's query against the new plugins
+	sendKeys(m, "1")
+	plugins := marketplacesTestModel(&mockClient{}).plugins
+	plugins[2].InstalledScopes = map[claude.Scope]bool{claude.ScopeProject: true}
+	m.applyRefresh(pluginsRefreshedMsg{plugins: plugins})
+	if got := len(m.getVisiblePlugins()); got != 3 || m.selectedIdx != 1 {
+		t.Errorf("after refresh visible rows = %d, selectedIdx = %d, want alpha, a1, a2 with a1 selected", got, m.selectedIdx)
+	}
+
+	// Filtering searches within the view, and Esc leaves the view applied
+	sendKeys(m, "/", "b", "1")
+	if len(m.filteredIdx) != 0 {
+		t.Errorf("filteredIdx = %v, want nothing outside the view", m.filteredIdx)
+	}
+	sendKeys(m, "esc")
+	if m.prefs.ActiveView != "Mine" || len(m.getVisiblePlugins()) != 3 {
+		t.Errorf("after Esc active view = %q, want Mine", m.prefs.ActiveView)
+	}
+}
+
+func TestEmptyViewSelectsNothing(t *testing.T) {
+	setupKnownMarketplaces(t, testKnownMarketplaces)
+	m := marketplacesTestModel(&mockClient{})
+	m.prefs.Views = []config.View{{Name: "None", Query: "mkt:gamma"}, {Name: "Beta", Query: "mkt:beta"}}
+
+	sendKeys(m, "1")
+	if m.selectedIdx != -1 || m.getSelectedPlugin() != nil {
+		t.Fatalf("selectedIdx = %d, want nothing selected in an empty view", m.selectedIdx)
+	}
+	sendKeys(m, "l", "j", "l")
+	if len(m.main.pendingOps) != 0 {
+		t.Errorf("pendingOps = %v, want nothing queued for hidden plugins", m.main.pendingOps)
+	}
+
+	// A refresh keeps nothing selected while the view is still empty
+	m.applyRefresh(pluginsRefreshedMsg{plugins: marketplacesTestModel(&mockClient{}).plugins})
+	if m.selectedIdx != -1 {
+		t.Errorf("after refresh selectedIdx = %d, want -1", m.selectedIdx)
+	}
+
+	// Jumping to a marketplace outside the view stays in the marketplaces view
+	sendKeys(m, "2", "M", "enter")
+	if m.mode != ModeMarketplaces || !strings.Contains(m.marketplaces.message, "alpha in the active view") {
+		t.Errorf("mode = %v, message = %q, want alpha reported outside the view", m.mode, m.marketplaces.message)
+	}
+}
+
+func TestSaveFilterAsView(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+	m.prefs.Views = []config.View{{Name: "Mine", Query: "status:installed"}}
+
+	sendKeys(m, "/", "mkt:b")
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.mode != ModeViews || m.views.form == nil || m.views.form.query != "mkt:b" {
+		t.Fatalf("mode = %v, form = %+v, want the form with the filter query", m.mode, m.views.form)
+	}
+	if m.filter.active {
+		t.Error("filter still active after Ctrl+S")
+	}
+
+	tests := []struct {
+		wantMsg string
+		keys    []string
+	}{
+		{"Name is required", []string{"enter"}},
+		{"already a view named Mine", []string{"Mine", "enter"}},
+		{`is: "x" isn't one of`, []string{"backspace", "backspace", "backspace", "backspace", "Beta", "tab", " is:x", "enter"}},
+	}
+	for _, tt := range tests {
+		sendKeys(m, tt.keys...)
+		if m.views.form == nil || !strings.Contains(m.views.form.message, tt.wantMsg) {
+			t.Fatalf("after %v form = %+v, want message containing %q", tt.keys, m.views.form, tt.wantMsg)
+		}
+	}
+
+	sendKeys(m, "backspace", "backspace", "backspace", "backspace", "backspace", "enter")
+	if m.mode != ModeMain || m.prefs.ActiveView != "Beta" || len(m.prefs.Views) != 2 {
+		t.Fatalf("mode = %v, active view = %q, views = %v", m.mode, m.prefs.ActiveView, m.prefs.Views)
+	}
+}
+
+func TestDeleteView(t *testing.T) {
+	m := marketplacesTestModel(&mockClient{})
+	m.prefs.Views = []config.View{{Name: "Mine", Query: "status:installed"}, {Name: "Beta", Query: "mkt:beta"}}
+	sendKeys(m, "2", "F")
+	if m.views.cursor != 2 {
+		t.Fatalf("cursor = %d, want 2 (the active view)", m.views.cursor)
+	}
+
+	sendKeys(m, "d")
+	if len(m.prefs.Views) != 1 || m.prefs.ActiveView != "" || m.views.cursor != 1 {
+		t.Errorf("views = %v, active view = %q, cursor = %d", m.prefs.Views, m.prefs.ActiveView, m.views.cursor)
+	}
+	if len(m.getVisiblePlugins()) != 5 {
+		t.Errorf("visible rows = %d, want all plugins after deleting the active view", len(m.getVisiblePlugins()))
+	}
+
+	sendKeys(m, "k", "d", "esc")
+	if m.mode != ModeMain || len(m.prefs.Views) != 1 {
+		t.Errorf("mode = %v, views = %v, want All plugins left undeletable", m.mode, m.prefs.Views)
+	}
+}
@@ -0,0 +1,264 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+// pollInterval is how often the settings and plugin state files are checked
+// for changes made outside cpm (e.g., toggling a plugin in a Claude session).
+const pollInterval = 2 * time.Second
+
+// fileStamp identifies a version of a watched file by modification time and size.
+type fileStamp struct {
+	modTime int64 // Unix nanoseconds
+	size    int64
+	exists  bool
+}
+
+// fileStamps maps watched file paths to their stamps.
+type fileStamps map[string]fileStamp
+
+// statFiles stamps each path. Missing files get a zero stamp, so creating or
+// deleting a file counts as a change.
+func statFiles(paths []string) fileStamps {
+	stamps := make(fileStamps, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			stamps[path] = fileStamp{}
+			continue
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size(), exists: true}
+	}
+	return stamps
+}
+
+// watchTickMsg is sent every pollInterval with the current stamps of the watched files.
+type watchTickMsg struct {
+	stamps fileStamps
+}
+
+// pluginsRefreshedMsg is sent when plugins are reloaded after a watched file changed.
+type pluginsRefreshedMsg pluginsLoadedMsg
+
+// watchFiles returns a command that stamps the watched files after pollInterval.
+func (m *Model) watchFiles() tea.Cmd {
+	paths := claude.WatchedFiles(m.workingDir)
+	return tea.Tick(pollInterval, func(time.Time) tea.Msg {
+		return watchTickMsg{stamps: statFiles(paths)}
+	})
+}
+
+// handleWatchTick starts a background reload when a watched file changed since
+// the plugins were last loaded, and schedules the next check. Reloads wait
+// while operations run, since their completion reloads the list anyway.
+func (m *Model) handleWatchTick(msg watchTickMsg) tea.Cmd {
+	if m.watched == nil || maps.Equal(m.watched, msg.stamps) {
+		return m.watchFiles()
+	}
+	if m.progress.loading || m.mode == ModeProgress || m.mode == ModeSummary {
+		return m.watchFiles()
+	}
+	m.watched = msg.stamps // Don't trigger again while this reload is in flight
+	return tea.Batch(m.reloadPlugins, m.watchFiles())
+}
+
+// reloadPlugins loads plugins like loadPlugins but reports them as a refresh.
+func (m *Model) reloadPlugins() tea.Msg {
+	msg, ok := m.loadPlugins().(pluginsLoadedMsg)
+	if !ok {
+		return nil // Keep showing the current state if the reload fails
+	}
+	return pluginsRefreshedMsg(msg)
+}
+
+// applyRefresh replaces the plugin list with freshly loaded state, keeping the
+// sort mode, selection, filter, view, and pending operations. Pending operations the
+// new state makes unnecessary are flagged rather than dropped, and an open
+// confirmation dialog's settings preview is recomputed.
+func (m *Model) applyRefresh(msg pluginsRefreshedMsg) tea.Cmd {
+	selectedID := m.getSelectedPluginID()
+
+	m.plugins = msg.plugins
+	m.catalogs = msg.catalogs
+	m.known = msg.known
+	m.watched = msg.stamps
+	m.applyDiskUsage()
+	if m.main.sortMode != SortByNameAsc {
+		// Loaded plugins are already grouped by marketplace and sorted by name
+		plugins := m.extractNonHeaderPlugins()
+		applySortMode(plugins, m.main.sortMode)
+		m.plugins = rebuildWithGroupHeaders(plugins, m.main.sortMode)
+		applyCatalogs(m.plugins, m.catalogs)
+	}
+	_ = m.computeView() // A saved query that doesn't parse lists all plugins
+	m.restoreSelection(selectedID)
+	if m.filter.active {
+		m.applyFilter()
+		m.restoreFilterSelection(selectedID)
+	}
+
+	var notices []string
+	if msg.notice != "" {
+		notices = append(notices, msg.notice)
+	}
+	if n := m.flagObsoleteOps(); n > 0 {
+		notices = append(notices, fmt.Sprintf("Settings changed on disk: %d pending change(s) may no longer apply", n))
+	}
+	m.main.notice = strings.Join(notices, " • ")
+	if m.main.showConfirm {
+		m.main.settingsDiffs = m.previewSettings() // Diff against the files as they are now
+	}
+
+	return measureDiskUsage(m.plugins)
+}
+
+// restoreFilterSelection keeps selectedID selected if it still matches the filter.
+func (m *Model) restoreFilterSelection(selectedID string) {
+	for _, idx := range m.filteredIdx {
+		if m.plugins[idx].ID == selectedID {
+			m.selectedIdx = idx
+			m.ensureVisible()
+			return
+		}
+	}
+}
+
+// flagObsoleteOps records, on each pending operation, why the current plugin
+// state makes it unnecessary or stale, and returns how many are flagged.
+func (m *Model) flagObsoleteOps() int {
+	byID := make(map[string]*PluginState, len(m.plugins))
+	for i := range m.plugins {
+		if !m.plugins[i].IsGroupHeader {
+			byID[m.plugins[i].ID] = &m.plugins[i]
+		}
+	}
+
+	flagged := 0
+	for id, op := range m.main.pendingOps {
+		op.Obsolete = obsoleteReason(op, byID[id])
+		m.queueOp(op) // The plugin may have been cached since it was queued
+		if op.Obsolete != "" {
+			flagged++
+		}
+	}
+	return flagged
+}
+
+// obsoleteReason returns why an operation no longer fits the plugin's current
+// state, or "" if it still applies. plugin is nil if the plugin is gone.
+func obsoleteReason(op Operation, plugin *PluginState) string {
+	if plugin == nil {
+		return "plugin is no longer listed"
+	}
+	if reason := plugin.policyBlocks(op.Type); reason != "" {
+		return reason
+	}
+	if reason := alreadyApplied(op, plugin); reason != "" {
+		return reason
+	}
+	if op.OriginalScopes != nil && !sameScopes(op.OriginalScopes, plugin.InstalledScopes) {
+		return "installed scopes changed to " + scopeListText(plugin.InstalledScopes)
+	}
+	return ""
+}
+
+// alreadyApplied returns why the plugin's state already matches the outcome of
+// the operation, or "" if the operation would still change something.
+func alreadyApplied(op Operation, plugin *PluginState) string {
+	switch op.Type {
+	case OpInstall, OpUninstall, OpMigrate, OpScopeChange:
+		return scopesAlreadyApplied(op, plugin)
+	case OpUpdate:
+		if !plugin.HasUpdate {
+			return "already up to date"
+		}
+	case OpEnable:
+		if plugin.Enabled {
+			return "already enabled"
+		}
+	case OpDisable:
+		if plugin.IsInstalled() && !plugin.Enabled {
+			return "already disabled"
+		}
+	}
+	return ""
+}
+
+// scopesAlreadyApplied is alreadyApplied for operations that add or remove scopes.
+func scopesAlreadyApplied(op Operation, plugin *PluginState) string {
+	switch op.Type {
+	case OpInstall:
+		if len(op.Scopes) > 0 && hasAllScopes(plugin, op.Scopes) {
+			return "already installed at " + joinScopes(op.Scopes)
+		}
+	case OpUninstall:
+		if !plugin.IsInstalled() || (len(op.Scopes) > 0 && !hasAnyScope(plugin, op.Scopes)) {
+			return "already uninstalled"
+		}
+	case OpMigrate:
+		if plugin.IsSingleScope() && plugin.HasScope(op.Scopes[0]) {
+			return "already moved to " + string(op.Scopes[0])
+		}
+	case OpScopeChange:
+		if hasAllScopes(plugin, op.Scopes) && !hasAnyScope(plugin, op.UninstallScopes) {
+			return "scopes already changed"
+		}
+	}
+	return ""
+}
+
+// hasAllScopes reports whether the plugin is installed at every given scope.
+func hasAllScopes(plugin *PluginState, scopes []claude.Scope) bool {
+	for _, s := range scopes {
+		if !plugin.HasScope(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAnyScope reports whether the plugin is installed at any of the given scopes.
+func hasAnyScope(plugin *PluginState, scopes []claude.Scope) bool {
+	for _, s := range scopes {
+		if plugin.HasScope(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameScopes reports whether two scope sets contain the same scopes,
+// ignoring enabled state.
+func sameScopes(a, b map[claude.Scope]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for s := range a {
+		if _, ok := b[s]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// scopeListText describes a scope set for messages, e.g. "user, local" or "none".
+func scopeListText(scopes map[claude.Scope]bool) string {
+	if len(scopes) == 0 {
+		return "none"
+	}
+	var list []claude.Scope
+	for _, s := range scopeOrder {
+		if _, ok := scopes[s]; ok {
+			list = append(list, s)
+		}
+	}
+	return joinScopes(list)
+}
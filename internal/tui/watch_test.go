@@ -0,0 +1,264 @@
+package tui
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/open-cli-collective/cpm/internal/claude"
+)
+
+func TestStatFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	missing := filepath.Join(dir, "missing.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	before := statFiles([]string{path, missing})
+	if !before[path].exists || before[path].size != 2 {
+		t.Errorf("stamp = %+v, want existing file of size 2", before[path])
+	}
+	if before[missing].exists {
+		t.Error("missing file should not exist")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"a": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if maps.Equal(before, statFiles([]string{path, missing})) {
+		t.Error("stamps should differ after the file changes")
+	}
+
+	if err := os.WriteFile(missing, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if statFiles([]string{missing})[missing] == before[missing] {
+		t.Error("creating a file should change its stamp")
+	}
+}
+
+func TestHandleWatchTick(t *testing.T) {
+	old := fileStamps{"/s.json": {modTime: 1, size: 2, exists: true}}
+	changed := fileStamps{"/s.json": {modTime: 3, size: 2, exists: true}}
+
+	t.Run("unchanged", func(t *testing.T) {
+		m, _ := testModel()
+		m.progress.loading = false
+		m.watched = old
+		if cmd := m.handleWatchTick(watchTickMsg{stamps: maps.Clone(old)}); cmd == nil {
+			t.Error("should schedule the next check")
+		}
+		if !maps.Equal(m.watched, old) {
+			t.Error("watched stamps should be unchanged")
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		m, _ := testModel()
+		m.progress.loading = false
+		m.watched = old
+		m.handleWatchTick(watchTickMsg{stamps: changed})
+		if !maps.Equal(m.watched, changed) {
+			t.Error("watched stamps should be updated when a reload starts")
+		}
+	})
+
+	t.Run("deferred during operations", func(t *testing.T) {
+		m, _ := testModel()
+		m.progress.loading = false
+		m.watched = old
+		m.mode = ModeProgress
+		m.handleWatchTick(watchTickMsg{stamps: changed})
+		if !maps.Equal(m.watched, old) {
+			t.Error("reload should wait until operations finish")
+		}
+	})
+}
+
+func TestApplyRefreshKeepsSelectionAndPendingOps(t *testing.T) {
+	m := NewModel(&mockClient{}, "/test/project")
+	m.plugins = []PluginState{
+		{Name: "mp", IsGroupHeader: true, Marketplace: "mp"},
+		{ID: "a@mp", Name: "a", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+		{ID: "b@mp", Name: "b", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{claude.ScopeUser: false}},
+		{ID: "c@mp", Name: "c", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+	}
+	m.selectedIdx = 2
+	m.main.pendingOps["b@mp"] = Operation{PluginID: "b@mp", Scopes: []claude.Scope{claude.ScopeUser}, Type: OpEnable}
+	m.main.pendingOps["c@mp"] = Operation{PluginID: "c@mp", Scopes: []claude.Scope{claude.ScopeLocal}, Type: OpInstall}
+
+	// b was enabled in another session and a new plugin sorts before it
+	m.applyRefresh(pluginsRefreshedMsg{plugins: []PluginState{
+		{Name: "mp", IsGroupHeader: true, Marketplace: "mp"},
+		{ID: "a@mp", Name: "a", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+		{ID: "aa@mp", Name: "aa", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+		{ID: "b@mp", Name: "b", Marketplace: "mp", Enabled: true, InstalledScopes: map[claude.Scope]bool{claude.ScopeUser: true}},
+		{ID: "c@mp", Name: "c", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+	}})
+
+	if got := m.getSelectedPluginID(); got != "b@mp" {
+		t.Errorf("selected = %q, want b@mp", got)
+	}
+	if len(m.main.pendingOps) != 2 {
+		t.Fatalf("pendingOps = %v, want both kept", m.main.pendingOps)
+	}
+	if got := m.main.pendingOps["b@mp"].Obsolete; got != "already enabled" {
+		t.Errorf("b Obsolete = %q, want %q", got, "already enabled")
+	}
+	if got := m.main.pendingOps["c@mp"].Obsolete; got != "" {
+		t.Errorf("c Obsolete = %q, want empty", got)
+	}
+	if !strings.Contains(m.main.notice, "1 pending change(s)") {
+		t.Errorf("notice = %q, want obsolete count", m.main.notice)
+	}
+}
+
+func TestApplyRefreshKeepsFilterAndSortMode(t *testing.T) {
+	m := NewModel(&mockClient{}, "/test/project")
+	m.plugins = []PluginState{
+		{Name: "mp", IsGroupHeader: true, Marketplace: "mp"},
+		{ID: "alpha@mp", Name: "alpha", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+		{ID: "beta@mp", Name: "beta", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+	}
+	m.main.sortMode = SortByNameDesc
+	m.sortPlugins()
+	m.filter = FilterState{text: "a", active: true}
+	m.applyFilter()
+	m.restoreSelection("beta@mp")
+
+	m.applyRefresh(pluginsRefreshedMsg{plugins: []PluginState{
+		{Name: "mp", IsGroupHeader: true, Marketplace: "mp"},
+		{ID: "alpha@mp", Name: "alpha", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+		{ID: "beta@mp", Name: "beta", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+		{ID: "gamma@mp", Name: "gamma", Marketplace: "mp", InstalledScopes: map[claude.Scope]bool{}},
+	}})
+
+	if m.plugins[1].ID != "gamma@mp" {
+		t.Errorf("first plugin = %q, want gamma@mp (Name Z-A kept)", m.plugins[1].ID)
+	}
+	if !m.filter.active || len(m.filteredIdx) != 3 {
+		t.Errorf("filter active=%v matches=%d, want active with 3 matches", m.filter.active, len(m.filteredIdx))
+	}
+	if got := m.getSelectedPluginID(); got != "beta@mp" {
+		t.Errorf("selected = %q, want beta@mp", got)
+	}
+}
+
+func TestObsoleteReason(t *testing.T) {
+	user := map[claude.Scope]bool{claude.ScopeUser: true}
+	local := map[claude.Scope]bool{claude.ScopeLocal: true}
+	tests := []struct {
+		plugin *PluginState
+		name   string
+		want   string
+		op     Operation
+	}{
+		{
+			name: "plugin gone",
+			op:   Operation{Type: OpInstall, Scopes: []claude.Scope{claude.ScopeLocal}},
+			want: "plugin is no longer listed",
+		},
+		{
+			name:   "install already done",
+			op:     Operation{Type: OpInstall, Scopes: []claude.Scope{claude.ScopeLocal}},
+			plugin: &PluginState{InstalledScopes: local},
+			want:   "already installed at local",
+		},
+		{
+			name:   "install still needed",
+			op:     Operation{Type: OpInstall, Scopes: []claude.Scope{claude.ScopeLocal}},
+			plugin: &PluginState{InstalledScopes: map[claude.Scope]bool{}},
+		},
+		{
+			name:   "uninstall already done",
+			op:     Operation{Type: OpUninstall, Scopes: []claude.Scope{claude.ScopeUser}, OriginalScopes: user},
+			plugin: &PluginState{InstalledScopes: map[claude.Scope]bool{}},
+			want:   "already uninstalled",
+		},
+		{
+			name:   "migrate already done",
+			op:     Operation{Type: OpMigrate, Scopes: []claude.Scope{claude.ScopeLocal}, OriginalScopes: user},
+			plugin: &PluginState{InstalledScopes: local},
+			want:   "already moved to local",
+		},
+		{
+			name:   "scopes changed under pending uninstall",
+			op:     Operation{Type: OpUninstall, Scopes: []claude.Scope{claude.ScopeUser}, OriginalScopes: user},
+			plugin: &PluginState{InstalledScopes: map[claude.Scope]bool{claude.ScopeUser: true, claude.ScopeLocal: true}},
+			want:   "installed scopes changed to user, local",
+		},
+		{
+			name:   "update no longer available",
+			op:     Operation{Type: OpUpdate, Scopes: []claude.Scope{claude.ScopeUser}, OriginalScopes: user},
+			plugin: &PluginState{InstalledScopes: user},
+			want:   "already up to date",
+		},
+		{
+			name:   "disable already done",
+			op:     Operation{Type: OpDisable, Scopes: []claude.Scope{claude.ScopeUser}},
+			plugin: &PluginState{InstalledScopes: map[claude.Scope]bool{claude.ScopeUser: false}},
+			want:   "already disabled",
+		},
+		{
+			name:   "newly blocked by policy",
+			op:     Operation{Type: OpInstall, Scopes: []claude.Scope{claude.ScopeLocal}},
+			plugin: &PluginState{ID: "x@mp", Managed: new(bool), InstalledScopes: map[claude.Scope]bool{}},
+			want:   "x@mp is blocked by managed settings and can't be installed or enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := obsoleteReason(tt.op, tt.plugin); got != tt.want {
+				t.Errorf("obsoleteReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObsoleteOperationDisplay(t *testing.T) {
+	m, _ := testModel(claude.ScopeLocal)
+	m.main.pendingOps["test@marketplace"] = Operation{
+		PluginID: "test@marketplace",
+		Scopes:   []claude.Scope{claude.ScopeLocal},
+		Type:     OpInstall,
+		Obsolete: "already installed at local",
+	}
+
+	if got := m.getInstalledScopeIndicator(m.plugins[0], m.styles); !strings.Contains(got, "(obsolete)") {
+		t.Errorf("indicator = %q, want obsolete marker", got)
+	}
+	if got := formatOperationLine(m.main.pendingOps["test@marketplace"], m.styles); !strings.Contains(got, "already installed at local") {
+		t.Errorf("confirmation line = %q, want obsolete reason", got)
+	}
+}
+
+func TestApplyRefreshUpdatesConfirmationPreview(t *testing.T) {
+	setupKnownMarketplaces(t, `{}`)
+	work := t.TempDir()
+	projectPath := filepath.Join(work, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(projectPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(projectPath, []byte("{\n  \"enabledPlugins\": {}\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel(&mockClient{}, work)
+	m.progress.loading = false
+	m.main.pendingOps["new@mp"] = Operation{PluginID: "new@mp", Type: OpInstall, Scopes: []claude.Scope{claude.ScopeProject}}
+	m.openConfirmation()
+
+	// Another process enables a plugin while the dialog is open
+	if err := os.WriteFile(projectPath, []byte("{\n  \"enabledPlugins\": {\"other@mp\": true}\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m.applyRefresh(pluginsRefreshedMsg{})
+	if len(m.main.settingsDiffs) != 1 || !strings.Contains(m.main.settingsDiffs[0].diff, "other@mp") {
+		t.Errorf("settingsDiffs = %+v, want the preview against the new contents", m.main.settingsDiffs)
+	}
+}